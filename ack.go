@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"status-bot/monitor"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// callbackAck — префикс данных кнопки «Принять», после двоеточия номер инцидента
+const callbackAck = "ack"
+
+// AckConfig настраивает напоминания о непринятых инцидентах
+type AckConfig struct {
+	RemindEvery Duration `yaml:"remind_every" json:"remind_every"` // Как часто напоминать о непринятом инциденте; 0 — не напоминать
+}
+
+func (c AckConfig) validate() error {
+	if c.RemindEvery.Duration < 0 {
+		return fmt.Errorf("ack.remind_every must not be negative")
+	}
+	return nil
+}
+
+// ackKeyboard — по кнопке «Принять» на каждый инцидент; nil, если инцидентов нет
+func ackKeyboard(lang string, incidents []Incident) *tgbotapi.InlineKeyboardMarkup {
+	if len(incidents) == 0 {
+		return nil
+	}
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(incidents))
+	for _, incident := range incidents {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(
+			tr(lang, "button.ack", consoleName(lang, incident.Console)),
+			callbackAck+":"+strconv.Itoa(incident.ID))))
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &keyboard
+}
+
+// incidentsOf возвращает инциденты, открытые изменениями changes
+func incidentsOf(changes []monitor.StatusChange, transitions map[string]transition) []Incident {
+	var incidents []Incident
+	for _, change := range changes {
+		if t := transitions[change.Name]; t.kind == changeDown && t.incident.ID != 0 {
+			incidents = append(incidents, t.incident)
+		}
+	}
+	return incidents
+}
+
+// userName — как показать пользователя, принявшего инцидент
+func userName(user *tgbotapi.User) string {
+	if user == nil {
+		return "?"
+	}
+	if user.UserName != "" {
+		return "@" + user.UserName
+	}
+	return strings.TrimSpace(user.FirstName + " " + user.LastName)
+}
+
+// handleAckButton отмечает инцидент принятым и дописывает это в исходное сообщение
+func handleAckButton(ctx context.Context, query *tgbotapi.CallbackQuery, payload string) {
+	lang := cfg().DefaultLanguage
+	if query.Message != nil {
+		lang = chatLang(query.Message.Chat.ID)
+	}
+	id, err := strconv.Atoi(payload)
+	if err != nil {
+		bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+
+	by := userName(query.From)
+	now := time.Now()
+	incident, updated, err := updateIncident(id, func(incident *Incident) bool {
+		if incident.AckedBy != "" {
+			return false
+		}
+		incident.AckedBy, incident.AckedAt = by, now
+		return true
+	})
+	if err != nil {
+		slog.Error("Error acknowledging incident", "incident_id", id, "error", err)
+		bot.Request(tgbotapi.NewCallback(query.ID, tr(lang, "error.save")))
+		return
+	}
+	if !updated {
+		if incident.AckedBy == "" {
+			bot.Request(tgbotapi.NewCallback(query.ID, tr(lang, "ack.unknown")))
+			return
+		}
+		bot.Request(tgbotapi.NewCallback(query.ID, tr(lang, "ack.already", incident.AckedBy)))
+		markAcked(query.Message, lang, incident)
+		return
+	}
+
+	slog.Info("Incident acknowledged", "incident_id", id, "by", by)
+	bot.Request(tgbotapi.NewCallback(query.ID, tr(lang, "ack.done")))
+	markAcked(query.Message, lang, incident)
+}
+
+// markAcked дописывает в сообщение, кто принял инцидент, и убирает его кнопку.
+// Разметка сохраняется: к исходным entities добавляется только текст в конце.
+func markAcked(message *tgbotapi.Message, lang string, incident Incident) {
+	if message == nil {
+		return
+	}
+
+	keyboard := withoutButton(message.ReplyMarkup, callbackAck+":"+strconv.Itoa(incident.ID))
+	note := tr(lang, "ack.note", consoleName(lang, incident.Console), incident.AckedBy, incident.AckedAt.Local().Format("15:04"))
+	if strings.Contains(message.Text, note) {
+		return
+	}
+	edit := tgbotapi.NewEditMessageText(message.Chat.ID, message.MessageID, message.Text+"\n\n"+note)
+	edit.Entities = message.Entities
+	edit.ReplyMarkup = keyboard
+	if _, err := bot.Send(edit); err != nil {
+		slog.Error("Error editing message", "message_id", message.MessageID, "chat_id", message.Chat.ID, "error", err)
+		return
+	}
+	noteAlertEdited(message.Chat.ID, message.MessageID, note, keyboard)
+}
+
+// remindUnacked напоминает подписчикам о недоступных консолях, инциденты
+// которых никто не принял, не чаще раза в ack.remind_every
+func remindUnacked() {
+	incidents, err := openIncidents()
+	if err != nil {
+		slog.Error("Error loading open incidents", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, incident := range incidents {
+		last := incident.Reminded
+		if last.IsZero() {
+			last = incident.Started
+		}
+		if incident.AckedBy != "" || now.Sub(last) < cfg().Ack.RemindEvery.Duration {
+			continue
+		}
+		if _, _, err := updateIncident(incident.ID, func(i *Incident) bool {
+			i.Reminded = now
+			return true
+		}); err != nil {
+			slog.Error("Error saving incident", "incident_id", incident.ID, "error", err)
+			continue
+		}
+
+		incident := incident
+		broadcastConsoleEvent(incident.Endpoint, incident.Console, true, func(lang string) (string, *tgbotapi.InlineKeyboardMarkup) {
+			text := trMarkup(lang, "ack.reminder", bold(consoleName(lang, incident.Console)),
+				escapeText(formatDuration(lang, now.Sub(incident.Started))))
+			return text, ackKeyboard(lang, []Incident{incident})
+		})
+	}
+}
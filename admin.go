@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"strings"
+)
+
+// isAdmin сообщает, входит ли пользователь в список администраторов из конфигурации
+func isAdmin(userID int64) bool {
+	for _, id := range cfg().Admins {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// requireAdmin отвечает отказом, если автор сообщения не администратор
+func requireAdmin(message *tgbotapi.Message) bool {
+	if message.From != nil && isAdmin(message.From.ID) {
+		return true
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, tr(chatLang(message.Chat.ID), "admin.only"))
+	msg.ReplyToMessageID = message.MessageID
+	bot.Send(msg)
+	return false
+}
+
+// handleForceCheck немедленно проверяет все API и рассылает изменения
+func handleForceCheck(ctx context.Context, chatID int64) {
+	lang := chatLang(chatID)
+	endpoints := cfg().endpoints()
+	lines := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if err := statusEngine().Check(ctx, endpoint.Name); err != nil {
+			slog.Error("Forced check failed", "endpoint", endpoint.Name, "error", err)
+			lines = append(lines, trMarkup(lang, "admin.check.error", escapeText(endpoint.Name), escapeText(err.Error())))
+			continue
+		}
+		lines = append(lines, trMarkup(lang, "admin.check.ok", escapeText(endpoint.Name)))
+	}
+
+	sendNow(chatID, newMarkupMessage(chatID, trMarkup(lang, "admin.check.done", strings.Join(lines, "\n"))))
+}
+
+// handleSubscribers отправляет список подписанных чатов
+func handleSubscribers(chatID int64) {
+	chats, err := store.Chats()
+	if err != nil {
+		slog.Error("Error loading chats", "error", err)
+		reply(chatID, "error.load")
+		return
+	}
+	if len(chats) == 0 {
+		reply(chatID, "subscribers.empty")
+		return
+	}
+
+	lang := chatLang(chatID)
+	var b strings.Builder
+	b.WriteString(tr(lang, "subscribers.count", len(chats)))
+	for _, chat := range chats {
+		consoles := tr(lang, "subscribers.all")
+		if len(chat.Consoles) > 0 {
+			consoles = consoleNames(lang, chat.Consoles)
+		}
+		fmt.Fprintf(&b, "\n%d — %s", chat.ID, consoles)
+		if !chat.SubscribedAt.IsZero() {
+			b.WriteString(tr(lang, "subscribers.since", chat.SubscribedAt.Local().Format("02.01.2006 15:04")))
+		}
+	}
+
+	sendNow(chatID, tgbotapi.NewMessage(chatID, b.String()))
+}
+
+// handleBroadcast рассылает объявление администратора всем подписанным чатам
+// через общую очередь с ограничением скорости
+func handleBroadcast(chatID int64, args string) {
+	text := strings.TrimSpace(args)
+	if text == "" {
+		reply(chatID, "broadcast.usage")
+		return
+	}
+
+	chats, err := store.Chats()
+	if err != nil {
+		slog.Error("Error loading chats", "error", err)
+		reply(chatID, "error.load")
+		return
+	}
+
+	for _, chat := range chats {
+		enqueueMessage(chat.ID, tgbotapi.NewMessage(chat.ID, tr(settingsLang(chat.Settings), "broadcast.message", text)))
+	}
+
+	reply(chatID, "broadcast.queued", len(chats))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+func validateAliases(aliases map[string]string) error {
+	ids := make([]string, 0, len(aliases))
+	for id := range aliases {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	seen := make(map[string]string, len(aliases))
+	for _, id := range ids {
+		alias := strings.TrimSpace(aliases[id])
+		if id == "" || alias == "" {
+			return fmt.Errorf("aliases: console %q: console and alias must be set", id)
+		}
+		key := strings.ToLower(alias)
+		if other, ok := seen[key]; ok {
+			return fmt.Errorf("aliases: alias %q is used for both %q and %q", alias, other, id)
+		}
+		seen[key] = id
+	}
+	return nil
+}
+
+// resolveConsole переводит псевдоним консоли из aliases в её ID из API;
+// остальные имена возвращаются как есть
+func resolveConsole(name string) string {
+	name = strings.TrimSpace(name)
+	for id, alias := range cfg().Aliases {
+		if strings.EqualFold(strings.TrimSpace(alias), name) {
+			return id
+		}
+	}
+	return name
+}
+
+// consoleFields делит аргументы команды по пробелам, как strings.Fields, но
+// псевдоним консоли из нескольких слов остаётся одним полем. Псевдонимы
+// заменяются ID консолей, остальные слова возвращаются как есть.
+func consoleFields(args string) []string {
+	// Сначала пробуются самые длинные псевдонимы: «Xbox bay 4» раньше «Xbox»
+	aliases := make([][]string, 0, len(cfg().Aliases))
+	for _, alias := range cfg().Aliases {
+		if words := strings.Fields(alias); len(words) > 1 {
+			aliases = append(aliases, words)
+		}
+	}
+	sort.Slice(aliases, func(i, j int) bool { return len(aliases[i]) > len(aliases[j]) })
+
+	words := strings.Fields(args)
+	fields := make([]string, 0, len(words))
+	for i := 0; i < len(words); {
+		n := 1
+		for _, alias := range aliases {
+			if len(alias) <= len(words)-i && strings.EqualFold(strings.Join(alias, " "), strings.Join(words[i:i+len(alias)], " ")) {
+				n = len(alias)
+				break
+			}
+		}
+		fields = append(fields, resolveConsole(strings.Join(words[i:i+n], " ")))
+		i += n
+	}
+	return fields
+}
+
+// consoleNames перечисляет консоли через запятую по их псевдонимам
+func consoleNames(lang string, consoles []string) string {
+	names := make([]string, 0, len(consoles))
+	for _, console := range consoles {
+		names = append(names, consoleName(lang, console))
+	}
+	return strings.Join(names, ", ")
+}
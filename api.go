@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIConfig — REST API управления ботом на служебном HTTP-сервере (http_listen)
+type APIConfig struct {
+	Token    string `yaml:"token" json:"token"`         // Токен доступа (Authorization: Bearer); если пуст, читается из TokenEnv. Без токена API выключен
+	TokenEnv string `yaml:"token_env" json:"token_env"` // Переменная окружения с токеном
+}
+
+func (c APIConfig) token() string {
+	if c.Token != "" {
+		return c.Token
+	}
+	return getenvSecret(c.TokenEnv)
+}
+
+func init() {
+	httpMux.HandleFunc("GET /api/chats", apiHandler(handleAPIChats))
+	httpMux.HandleFunc("POST /api/chats", apiHandler(handleAPIAddChat))
+	httpMux.HandleFunc("DELETE /api/chats/{id}", apiHandler(handleAPIRemoveChat))
+	httpMux.HandleFunc("POST /api/check", apiHandler(handleAPICheck))
+	httpMux.HandleFunc("GET /api/status", apiHandler(handleAPIStatus))
+	httpMux.HandleFunc("GET /api/history", apiHandler(handleAPIHistory))
+	httpMux.HandleFunc("GET /api/maintenance", apiHandler(handleAPIMaintenance))
+	httpMux.HandleFunc("POST /api/maintenance", apiHandler(handleAPIAddMaintenance))
+	httpMux.HandleFunc("DELETE /api/maintenance/{id}", apiHandler(handleAPIRemoveMaintenance))
+}
+
+// apiError — ошибка, которую обработчик возвращает клиенту с кодом status
+type apiError struct {
+	status  int
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &apiError{http.StatusBadRequest, fmt.Sprintf(format, args...)}
+}
+
+func notFound(format string, args ...interface{}) error {
+	return &apiError{http.StatusNotFound, fmt.Sprintf(format, args...)}
+}
+
+// authorizeAPI сверяет токен из заголовка Authorization с токеном API
+func authorizeAPI(authorization string) bool {
+	expected := cfg().API.token()
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	return expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// apiHandler проверяет токен и отдаёт результат обработчика в JSON.
+// Ошибки, кроме apiError, журналируются и возвращаются как 500.
+func apiHandler(handler func(r *http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if cfg().API.token() == "" {
+			http.NotFound(w, r)
+			return
+		}
+		if !authorizeAPI(r.Header.Get("Authorization")) {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+
+		result, err := handler(r)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if e, ok := err.(*apiError); ok {
+				status = e.status
+			} else {
+				slog.Error("API request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+			}
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// decodeBody разбирает тело запроса в JSON; неизвестные поля — ошибка
+func decodeBody(r *http.Request, value interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(value); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	return nil
+}
+
+type apiChat struct {
+	ID           int64     `json:"id"`
+	SubscribedAt time.Time `json:"subscribed_at"`
+	Consoles     []string  `json:"consoles,omitempty"`
+}
+
+func newAPIChat(chat Chat) apiChat {
+	return apiChat{ID: chat.ID, SubscribedAt: chat.SubscribedAt, Consoles: chat.Consoles}
+}
+
+func handleAPIChats(r *http.Request) (interface{}, error) {
+	chats, err := store.Chats()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]apiChat, 0, len(chats))
+	for _, chat := range chats {
+		result = append(result, newAPIChat(chat))
+	}
+	return result, nil
+}
+
+func handleAPIAddChat(r *http.Request) (interface{}, error) {
+	var body struct {
+		ChatID int64 `json:"chat_id"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	chat, err := apiAddChat(body.ChatID)
+	if err != nil {
+		return nil, err
+	}
+	return newAPIChat(chat), nil
+}
+
+// apiAddChat подписывает чат; общая часть REST и gRPC API
+func apiAddChat(chatID int64) (Chat, error) {
+	if chatID == 0 {
+		return Chat{}, badRequest("chat_id is required")
+	}
+	if err := store.AddChat(chatID); err != nil {
+		return Chat{}, err
+	}
+	slog.Info("Chat subscribed via API", "chat_id", chatID)
+	chat, _, err := store.Chat(chatID)
+	return chat, err
+}
+
+func handleAPIRemoveChat(r *http.Request) (interface{}, error) {
+	chatID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, badRequest("invalid chat id %q", r.PathValue("id"))
+	}
+	if err := apiRemoveChat(chatID); err != nil {
+		return nil, err
+	}
+	return map[string]int64{"id": chatID}, nil
+}
+
+func apiRemoveChat(chatID int64) error {
+	if _, ok, err := store.Chat(chatID); err != nil {
+		return err
+	} else if !ok {
+		return notFound("chat %d is not subscribed", chatID)
+	}
+	if err := store.RemoveChat(chatID); err != nil {
+		return err
+	}
+	slog.Info("Chat unsubscribed via API", "chat_id", chatID)
+	return nil
+}
+
+// apiEndpoints возвращает API с именем name или все, если имя не задано
+func apiEndpoints(name string) ([]Endpoint, error) {
+	endpoints := cfg().endpoints()
+	if name == "" {
+		return endpoints, nil
+	}
+	for _, endpoint := range endpoints {
+		if endpoint.Name == name {
+			return []Endpoint{endpoint}, nil
+		}
+	}
+	return nil, notFound("unknown endpoint %q", name)
+}
+
+type apiCheckResult struct {
+	Endpoint string `json:"endpoint"`
+	OK       bool   `json:"ok"`
+	Error    string `json:"error,omitempty"`
+}
+
+// handleAPICheck выполняет проверку немедленно, как /check
+func handleAPICheck(r *http.Request) (interface{}, error) {
+	return apiCheck(r.Context(), r.URL.Query().Get("endpoint"))
+}
+
+func apiCheck(ctx context.Context, name string) ([]apiCheckResult, error) {
+	endpoints, err := apiEndpoints(name)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]apiCheckResult, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		result := apiCheckResult{Endpoint: endpoint.Name, OK: true}
+		if err := statusEngine().Check(ctx, endpoint.Name); err != nil {
+			slog.Error("Forced check failed", "endpoint", endpoint.Name, "error", err)
+			result.OK, result.Error = false, err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func handleAPIStatus(r *http.Request) (interface{}, error) {
+	return endpointStatuses(), nil
+}
+
+// handleAPIHistory отдаёт смены статуса консоли: ?console=NAME[&endpoint=NAME][&since=24h|RFC3339].
+// По умолчанию — за весь срок хранения истории.
+func handleAPIHistory(r *http.Request) (interface{}, error) {
+	query := r.URL.Query()
+	var since time.Time
+	if s := query.Get("since"); s != "" {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			since = t
+		} else if d, err := parseDuration(s); err == nil {
+			since = time.Now().Add(-d)
+		} else {
+			return nil, badRequest("invalid since %q, expected duration or RFC 3339 time", s)
+		}
+	}
+	return apiHistory(query.Get("endpoint"), query.Get("console"), since)
+}
+
+// apiHistory собирает историю консоли по всем подходящим API; нулевой since —
+// за весь срок хранения
+func apiHistory(name, console string, since time.Time) ([]HistoryEntry, error) {
+	if console == "" {
+		return nil, badRequest("console is required")
+	}
+	endpoints, err := apiEndpoints(name)
+	if err != nil {
+		return nil, err
+	}
+	if since.IsZero() {
+		since = time.Now().Add(-cfg().History.Duration)
+	}
+
+	history := []HistoryEntry{}
+	for _, endpoint := range endpoints {
+		entries, err := store.History(endpoint.Name, console, since)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, entries...)
+	}
+	return history, nil
+}
+
+func handleAPIMaintenance(r *http.Request) (interface{}, error) {
+	windows, err := loadMaintenance()
+	if err != nil {
+		return nil, err
+	}
+	if windows == nil {
+		windows = []maintenanceWindow{}
+	}
+	return windows, nil
+}
+
+// handleAPIAddMaintenance добавляет окно обслуживания. When задаётся так же,
+// как в /maintenance add; Duration включает режим обслуживания прямо сейчас.
+func handleAPIAddMaintenance(r *http.Request) (interface{}, error) {
+	var body struct {
+		When     string   `json:"when"`
+		Duration Duration `json:"duration"`
+		Consoles []string `json:"consoles"`
+		Silent   bool     `json:"silent"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		return nil, err
+	}
+	return apiAddMaintenance(body.When, body.Duration.Duration, body.Consoles, body.Silent)
+}
+
+func apiAddMaintenance(when string, duration time.Duration, consoles []string, silent bool) (maintenanceWindow, error) {
+	switch {
+	case when != "" && duration != 0:
+		return maintenanceWindow{}, badRequest("when and duration are mutually exclusive")
+	case duration > 0:
+		if duration >= 24*time.Hour {
+			return maintenanceWindow{}, badRequest("duration must be less than 24h")
+		}
+		now := time.Now()
+		when = now.Format("2006-01-02 15:04") + "-" + now.Add(duration).Format("15:04")
+	case when == "":
+		return maintenanceWindow{}, badRequest("when or duration is required")
+	}
+
+	day, hours, err := parseMaintenanceWhen(when)
+	if err != nil {
+		return maintenanceWindow{}, badRequest("invalid when %q: %v", when, err)
+	}
+	w, err := addMaintenanceWindow(maintenanceWindow{Day: day, Hours: hours.String(), Consoles: consoles, Silent: silent})
+	if err != nil {
+		return w, err
+	}
+	slog.Info("Maintenance window added via API", "id", w.ID, "when", w.when())
+	return w, nil
+}
+
+func handleAPIRemoveMaintenance(r *http.Request) (interface{}, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return nil, badRequest("invalid maintenance window id %q", r.PathValue("id"))
+	}
+	if err := apiRemoveMaintenance(id); err != nil {
+		return nil, err
+	}
+	return map[string]int{"id": id}, nil
+}
+
+func apiRemoveMaintenance(id int) error {
+	removed, err := removeMaintenanceWindow(id)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return notFound("maintenance window %d not found", id)
+	}
+	slog.Info("Maintenance window removed via API", "id", id)
+	return nil
+}
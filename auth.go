@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"golang.org/x/oauth2"
+	"net/http"
+	"sync"
+)
+
+// AuthConfig — авторизация запросов к API: логин и пароль (Basic),
+// статический токен (Bearer) или токен OAuth2 client credentials. Секреты читаются при каждом запросе, поэтому
+// новые значения из *_FILE и Vault применяются без перезапуска.
+type AuthConfig struct {
+	Username    string       `yaml:"username" json:"username"`         // Логин для Basic-авторизации
+	Password    string       `yaml:"password" json:"password"`         // Пароль; если пуст, читается из PasswordEnv
+	PasswordEnv string       `yaml:"password_env" json:"password_env"` // Переменная окружения с паролем
+	Token       string       `yaml:"token" json:"token"`               // Токен для заголовка Authorization: Bearer; если пуст, читается из TokenEnv
+	TokenEnv    string       `yaml:"token_env" json:"token_env"`       // Переменная окружения с токеном
+	OAuth2      OAuth2Config `yaml:"oauth2" json:"oauth2"`             // Токен от провайдера идентификации вместо статического
+}
+
+func (c AuthConfig) validate() error {
+	basic := c.Username != ""
+	bearer := c.Token != "" || c.TokenEnv != ""
+	if basic && bearer {
+		return fmt.Errorf("auth: username and token are mutually exclusive")
+	}
+	if !basic && (c.Password != "" || c.PasswordEnv != "") {
+		return fmt.Errorf("auth: password requires username")
+	}
+	if c.OAuth2.enabled() {
+		if basic || bearer {
+			return fmt.Errorf("auth: oauth2 cannot be combined with username or token")
+		}
+		return c.OAuth2.validate()
+	}
+	return nil
+}
+
+func (c AuthConfig) password() string {
+	if c.Password != "" {
+		return c.Password
+	}
+	return getenvSecret(c.PasswordEnv)
+}
+
+func (c AuthConfig) token() string {
+	if c.Token != "" {
+		return c.Token
+	}
+	return getenvSecret(c.TokenEnv)
+}
+
+// authorization возвращает значение заголовка Authorization; пусто — без авторизации
+func (c AuthConfig) authorization() (string, error) {
+	switch {
+	case c.Username != "":
+		credentials := base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.password()))
+		return "Basic " + credentials, nil
+	case c.Token != "" || c.TokenEnv != "":
+		token := c.token()
+		if token == "" {
+			return "", fmt.Errorf("auth token is empty, expected token or %s", c.TokenEnv)
+		}
+		return "Bearer " + token, nil
+	}
+	return "", nil
+}
+
+// endpointRequest — общие настройки запросов к одному API: заголовки,
+// авторизация и TLS. Используется status_api, sse и websocket.
+type endpointRequest struct {
+	header http.Header
+	auth   AuthConfig
+	tls    *tls.Config        // nil — общий apiClient
+	base   *http.Client       // Клиент вместо общего apiClient, если client_tls не задан
+	oauth2 oauth2.TokenSource // nil — без OAuth2
+
+	once   sync.Once
+	client *http.Client
+}
+
+// newEndpointRequest проверяет и собирает настройки запросов endpoint
+func newEndpointRequest(endpoint Endpoint) (*endpointRequest, error) {
+	if err := endpoint.Auth.validate(); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := endpoint.ClientTLS.load()
+	if err != nil {
+		return nil, err
+	}
+	request := &endpointRequest{header: requestHeader(endpoint.Headers), auth: endpoint.Auth, tls: tlsConfig}
+	if endpoint.Auth.OAuth2.enabled() {
+		request.oauth2 = newOAuth2TokenSource(endpoint.Auth.OAuth2)
+	}
+	return request, nil
+}
+
+// httpClient возвращает клиент для запросов к API: общий apiClient или,
+// если задан client_tls, собственный с теми же таймаутами из http_client.
+// Клиент создаётся при первом запросе, когда конфигурация уже загружена.
+func (r *endpointRequest) httpClient() *http.Client {
+	if r.tls == nil {
+		if r.base != nil {
+			return r.base
+		}
+		return apiClient
+	}
+	r.once.Do(func() {
+		r.client = newAPIClient(cfg().HTTPClient, r.tls)
+	})
+	return r.client
+}
+
+// streamClient возвращает HTTP-клиент без общего таймаута запроса, который
+// оборвал бы долгоживущий поток sse; таймауты соединения остаются из http_client
+func (r *endpointRequest) streamClient() *http.Client {
+	return &http.Client{Transport: r.httpClient().Transport}
+}
+
+// headers возвращает заголовки очередного запроса вместе с авторизацией
+func (r *endpointRequest) headers(ctx context.Context) (http.Header, error) {
+	header := r.header.Clone()
+	if r.oauth2 != nil {
+		token, err := r.oauth2.Token()
+		if err != nil {
+			return nil, err
+		}
+		token.SetAuthHeader(&http.Request{Header: header})
+		return header, nil
+	}
+	authorization, err := r.auth.authorization()
+	if err != nil {
+		return nil, err
+	}
+	if authorization != "" {
+		header.Set("Authorization", authorization)
+	}
+	return header, nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"status-bot/monitor"
+)
+
+// BackoffConfig задаёт паузы между повторными запросами к недоступному API
+type BackoffConfig struct {
+	Initial    Duration `yaml:"initial" json:"initial"`       // Первая пауза после ошибки; по умолчанию интервал проверки API
+	Max        Duration `yaml:"max" json:"max"`               // Максимальная пауза
+	Multiplier float64  `yaml:"multiplier" json:"multiplier"` // Во сколько раз растёт пауза после каждой ошибки подряд
+}
+
+func (c BackoffConfig) validate() error {
+	if c.Initial.Duration < 0 {
+		return fmt.Errorf("backoff.initial must not be negative")
+	}
+	if c.Max.Duration <= 0 {
+		return fmt.Errorf("backoff.max must be positive")
+	}
+	if c.Multiplier < 1 {
+		return fmt.Errorf("backoff.multiplier must be at least 1")
+	}
+	return nil
+}
+
+// monitor переводит настройки в паузы для monitor.Engine
+func (c BackoffConfig) monitor() monitor.Backoff {
+	return monitor.Backoff{Initial: c.Initial.Duration, Max: c.Max.Duration, Multiplier: c.Multiplier}
+}
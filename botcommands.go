@@ -0,0 +1,75 @@
+package main
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"sort"
+)
+
+// Команды в меню клиентов Telegram по областям видимости, в порядке показа.
+// Описания — ключи command.<имя> в каталогах переводов.
+var (
+	privateMenu = []string{
+		"status", "start", "stop", "subscribe", "unsubscribe", "history", "uptime", "chart", "incidents",
+		"settings", "quiet", "frequency", "digest", "verbosity", "live", "snooze", "mute", "unmute", "language", "keyboard", "oncall", "stats",
+		"add_monitor", "my_monitors", "remove_monitor",
+	}
+	groupMenu = []string{
+		"status", "start", "stop", "subscribe", "unsubscribe", "history", "uptime", "chart", "incidents",
+		"settings", "quiet", "frequency", "digest", "verbosity", "topic", "live", "snooze", "mute", "unmute", "language", "oncall", "stats",
+	}
+	// adminMenu добавляется к privateMenu в личных чатах администраторов
+	adminMenu = []string{"check", "subscribers", "broadcast", "channel", "maintenance", "override", "testalert", "reload"}
+)
+
+// userMonitorCommands показываются, только если пользовательские мониторы включены
+var userMonitorCommands = map[string]bool{"add_monitor": true, "my_monitors": true, "remove_monitor": true}
+
+// registerBotCommands публикует меню команд для личных чатов, групп и личных
+// чатов администраторов на каждом языке из каталогов. Меню без языка — на
+// языке по умолчанию. previousAdmins — администраторы до перечитывания
+// конфигурации: у тех, кого больше нет в списке, меню администратора убирается.
+func registerBotCommands(previousAdmins []int64) {
+	langs := make([]string, 0, len(catalogs)+1)
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	langs = append(langs, "")
+
+	admin := append(append([]string{}, privateMenu...), adminMenu...)
+	for _, lang := range langs {
+		setBotCommands(tgbotapi.NewBotCommandScopeAllPrivateChats(), lang, privateMenu)
+		setBotCommands(tgbotapi.NewBotCommandScopeAllGroupChats(), lang, groupMenu)
+		for _, id := range cfg().Admins {
+			setBotCommands(tgbotapi.NewBotCommandScopeChat(id), lang, admin)
+		}
+		for _, id := range previousAdmins {
+			if !isAdmin(id) {
+				request := tgbotapi.NewDeleteMyCommandsWithScopeAndLanguage(tgbotapi.NewBotCommandScopeChat(id), lang)
+				if _, err := bot.Request(request); err != nil {
+					slog.Warn("Error removing admin commands", "chat_id", id, "error", err)
+				}
+			}
+		}
+	}
+}
+
+func setBotCommands(scope tgbotapi.BotCommandScope, lang string, names []string) {
+	text := lang
+	if text == "" {
+		text = cfg().DefaultLanguage
+	}
+	commands := make([]tgbotapi.BotCommand, 0, len(names))
+	for _, name := range names {
+		if _, ok := router.commands[name]; !ok || (userMonitorCommands[name] && !cfg().UserMonitors.Enabled) {
+			continue
+		}
+		commands = append(commands, tgbotapi.BotCommand{Command: name, Description: tr(text, "command."+name)})
+	}
+
+	if _, err := bot.Request(tgbotapi.NewSetMyCommandsWithScopeAndLanguage(scope, lang, commands...)); err != nil {
+		// Область отдельного чата недоступна, пока пользователь не написал боту
+		slog.Warn("Error setting bot commands", "scope", scope.Type, "chat_id", scope.ChatID, "language", lang, "error", err)
+	}
+}
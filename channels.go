@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// channelSetting — настройка чата-канала: его @имя или название для списка
+// каналов. Каналы не присылают /start, их подписывают администраторы бота,
+// а непустая настройка отличает канал от обычного чата.
+const channelSetting = "channel"
+
+// callbackChannel — кнопка подписки канала, куда бота назначили администратором: "channel:<ID>"
+const callbackChannel = "channel"
+
+var (
+	errNotChannel  = errors.New("chat is not a channel")
+	errCannotPost  = errors.New("bot cannot post messages to the channel")
+	errUnknownChat = errors.New("chat not found")
+)
+
+// isChannel сообщает, что подписан канал: в нём нет команд, а кнопки видят все читатели
+func isChannel(settings map[string]string) bool {
+	return settings[channelSetting] != ""
+}
+
+// handleChannel обрабатывает /channel, /channel add <@канал|ID> [консоли...]
+// и /channel remove <@канал|ID>
+func handleChannel(chatID int64, args string) {
+	fields := consoleFields(args)
+	if len(fields) == 0 {
+		listChannels(chatID)
+		return
+	}
+	if len(fields) < 2 {
+		reply(chatID, "channel.usage")
+		return
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "add":
+		channel, err := resolveChannel(fields[1])
+		if err != nil {
+			slog.Warn("Error resolving channel", "channel", fields[1], "error", err)
+			reply(chatID, channelErrorKey(err), fields[1])
+			return
+		}
+		if err := subscribeChannel(channel, fields[2:]); err != nil {
+			slog.Error("Error saving chat", "chat_id", channel.ID, "error", err)
+			reply(chatID, "error.save")
+			return
+		}
+		reply(chatID, "channel.added", channelName(channel))
+	case "remove":
+		id, err := channelID(fields[1])
+		if err != nil {
+			slog.Warn("Error resolving channel", "channel", fields[1], "error", err)
+			reply(chatID, channelErrorKey(err), fields[1])
+			return
+		}
+		if err := store.RemoveChat(id); err != nil {
+			slog.Error("Error removing chat", "chat_id", id, "error", err)
+			reply(chatID, "error.save")
+			return
+		}
+		reply(chatID, "channel.removed", fields[1])
+	default:
+		reply(chatID, "channel.usage")
+	}
+}
+
+// listChannels отправляет список подписанных каналов
+func listChannels(chatID int64) {
+	chats, err := store.Chats()
+	if err != nil {
+		slog.Error("Error loading chats", "error", err)
+		reply(chatID, "error.load")
+		return
+	}
+	lang := chatLang(chatID)
+	var lines []string
+	for _, chat := range chats {
+		if !isChannel(chat.Settings) {
+			continue
+		}
+		consoles := tr(lang, "subscribers.all")
+		if len(chat.Consoles) > 0 {
+			consoles = consoleNames(lang, chat.Consoles)
+		}
+		lines = append(lines, fmt.Sprintf("%s (%d) — %s", chat.Settings[channelSetting], chat.ID, consoles))
+	}
+	if len(lines) == 0 {
+		reply(chatID, "channel.empty")
+		return
+	}
+	reply(chatID, "channel.list", strings.Join(lines, "\n"))
+}
+
+// subscribeChannel подписывает канал на все консоли или на перечисленные
+func subscribeChannel(channel tgbotapi.Chat, consoles []string) error {
+	if err := store.AddChat(channel.ID); err != nil {
+		return err
+	}
+	for _, console := range consoles {
+		if err := store.AddChatConsole(channel.ID, console); err != nil {
+			return err
+		}
+	}
+	return store.SetChatSetting(channel.ID, channelSetting, channelName(channel))
+}
+
+// resolveChannel находит канал по @имени или ID и проверяет, что бот может в нём писать
+func resolveChannel(ref string) (tgbotapi.Chat, error) {
+	config := tgbotapi.ChatInfoConfig{}
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		config.ChatID = id
+	} else {
+		config.SuperGroupUsername = "@" + strings.TrimPrefix(ref, "@")
+	}
+	var channel tgbotapi.Chat
+	if err := requestResult(config, &channel); err != nil {
+		return channel, err
+	}
+	if !channel.IsChannel() {
+		return channel, errNotChannel
+	}
+
+	var member tgbotapi.ChatMember
+	err := requestResult(tgbotapi.GetChatMemberConfig{ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: channel.ID, UserID: botUser.ID}}, &member)
+	if err != nil {
+		return channel, err
+	}
+	if !member.IsCreator() && !(member.IsAdministrator() && member.CanPostMessages) {
+		return channel, errCannotPost
+	}
+	return channel, nil
+}
+
+// channelID возвращает ID канала: числовой как есть, @имя — через Bot API
+func channelID(ref string) (int64, error) {
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return id, nil
+	}
+	var channel tgbotapi.Chat
+	config := tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{SuperGroupUsername: "@" + strings.TrimPrefix(ref, "@")}}
+	if err := requestResult(config, &channel); err != nil {
+		return 0, err
+	}
+	return channel.ID, nil
+}
+
+// requestResult вызывает метод Bot API и разбирает его результат
+func requestResult(c tgbotapi.Chattable, result interface{}) error {
+	resp, err := bot.Request(c)
+	if err != nil {
+		if isChatUnreachable(err) {
+			return errUnknownChat
+		}
+		return err
+	}
+	if len(resp.Result) == 0 {
+		return errUnknownChat
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+func channelErrorKey(err error) string {
+	switch {
+	case errors.Is(err, errNotChannel):
+		return "channel.not_channel"
+	case errors.Is(err, errCannotPost):
+		return "channel.cannot_post"
+	case errors.Is(err, errUnknownChat):
+		return "channel.not_found"
+	}
+	return "channel.error"
+}
+
+func channelName(channel tgbotapi.Chat) string {
+	if channel.UserName != "" {
+		return "@" + channel.UserName
+	}
+	if channel.Title != "" {
+		return channel.Title
+	}
+	return strconv.FormatInt(channel.ID, 10)
+}
+
+// offerChannel предлагает администраторам подписать канал, куда бота
+// назначили администратором: сам канал /start не пришлёт
+func offerChannel(channel tgbotapi.Chat) {
+	for _, admin := range cfg().Admins {
+		lang := chatLang(admin)
+		msg := tgbotapi.NewMessage(admin, tr(lang, "channel.offer", channelName(channel)))
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(lang, "channel.subscribe"), fmt.Sprintf("%s:%d", callbackChannel, channel.ID)),
+		))
+		bot.Send(msg)
+	}
+}
+
+// handleChannelButton подписывает канал по кнопке из offerChannel
+func handleChannelButton(ctx context.Context, query *tgbotapi.CallbackQuery, payload string) {
+	lang := cfg().DefaultLanguage
+	if query.Message != nil {
+		lang = chatLang(query.Message.Chat.ID)
+	}
+	if query.From == nil || !isAdmin(query.From.ID) {
+		bot.Request(tgbotapi.NewCallback(query.ID, tr(lang, "admin.only")))
+		return
+	}
+
+	channel, err := resolveChannel(payload)
+	if err != nil {
+		slog.Warn("Error resolving channel", "channel", payload, "error", err)
+		bot.Request(tgbotapi.NewCallback(query.ID, tr(lang, channelErrorKey(err), payload)))
+		return
+	}
+	if err := subscribeChannel(channel, nil); err != nil {
+		slog.Error("Error saving chat", "chat_id", channel.ID, "error", err)
+		bot.Request(tgbotapi.NewCallback(query.ID, tr(lang, "error.save")))
+		return
+	}
+	bot.Request(tgbotapi.NewCallback(query.ID, ""))
+	if query.Message != nil {
+		bot.Request(tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, tr(lang, "channel.added", channelName(channel))))
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// defaultChartPeriod — период /chart, если он не указан
+const defaultChartPeriod = 24 * time.Hour
+
+// Размеры графика в пикселях
+const (
+	chartWidth  = 800
+	chartLabel  = 150 // Ширина колонки с именем API и доступностью
+	chartMargin = 12
+	chartRow    = 34 // Высота полосы одного API
+	chartGap    = 12
+	chartAxis   = 24 // Высота подписей оси времени
+)
+
+var (
+	chartBackground = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	chartUp         = color.RGBA{0x2e, 0x7d, 0x32, 0xff}
+	chartDown       = color.RGBA{0xc6, 0x28, 0x28, 0xff}
+	chartNoData     = color.RGBA{0xe0, 0xe0, 0xe0, 0xff}
+	chartText       = color.RGBA{0x21, 0x21, 0x21, 0xff}
+	chartGrid       = color.RGBA{0x9e, 0x9e, 0x9e, 0xff}
+)
+
+// chartRowData — полоса графика: история консоли в одном API
+type chartRowData struct {
+	label    string
+	uptime   float64
+	segments []timelineSegment
+}
+
+// handleChart обрабатывает /chart <консоль> [24h|7d]: присылает картинку с
+// полосой доступности консоли в каждом API за период
+func handleChart(chatID int64, args string) {
+	fields := consoleFields(args)
+	if len(fields) == 0 || len(fields) > 2 {
+		reply(chatID, "chart.usage")
+		return
+	}
+
+	period := defaultChartPeriod
+	if len(fields) == 2 {
+		d, err := parseDuration(fields[1])
+		if err != nil || d <= 0 {
+			reply(chatID, "chart.usage")
+			return
+		}
+		period = d
+	}
+
+	lang := chatLang(chatID)
+	now := time.Now()
+	from := now.Add(-period)
+	var rows []chartRowData
+	var lines []string
+	for _, endpoint := range cfg().endpoints() {
+		console, ok := consoleStatus(endpoint.Name, fields[0])
+		if !ok {
+			continue
+		}
+		entries, err := store.History(endpoint.Name, console.Name, from)
+		if err != nil {
+			slog.Error("Error loading history", "endpoint", endpoint.Name, "console", console.Name, "error", err)
+			reply(chatID, "error.load")
+			return
+		}
+		a := computeAvailability(entries, console.Status, from, now)
+		rows = append(rows, chartRowData{
+			label:    endpoint.Name,
+			uptime:   a.percent,
+			segments: consoleTimeline(entries, console.Status, from, now),
+		})
+		lines = append(lines, tr(lang, "uptime.line", endpoint.Name, a.percent, formatDuration(lang, a.downtime), a.incidents))
+	}
+	if len(rows) == 0 {
+		reply(chatID, "uptime.unknown", consoleName(lang, fields[0]))
+		return
+	}
+
+	if _, err := bot.Request(tgbotapi.NewChatAction(chatID, tgbotapi.ChatUploadPhoto)); err != nil {
+		slog.Warn("Error sending chat action", "chat_id", chatID, "error", err)
+	}
+	data, err := renderChart(rows, from, now)
+	if err != nil {
+		slog.Error("Error rendering chart", "console", fields[0], "error", err)
+		reply(chatID, "chart.error")
+		return
+	}
+	photo := tgbotapi.NewPhoto(chatID, tgbotapi.FileBytes{Name: "chart.png", Bytes: data})
+	photo.Caption = tr(lang, "chart.caption", consoleName(lang, fields[0]), formatDuration(lang, period), strings.Join(lines, "\n"))
+	if _, err := bot.Send(photo); err != nil {
+		slog.Error("Error sending chart", "chat_id", chatID, "error", err)
+	}
+}
+
+// renderChart рисует PNG с полосой на каждый API: зелёным — доступность,
+// красным — недоступность, серым — время, когда консоли ещё не было в ответе
+func renderChart(rows []chartRowData, from, to time.Time) ([]byte, error) {
+	height := 2*chartMargin + len(rows)*(chartRow+chartGap) + chartAxis
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, height))
+	fill(img, img.Bounds(), chartBackground)
+
+	left, right := chartMargin+chartLabel, chartWidth-chartMargin
+	span := to.Sub(from)
+	x := func(t time.Time) int {
+		return left + int(float64(right-left)*float64(t.Sub(from))/float64(span))
+	}
+
+	for i, row := range rows {
+		top := chartMargin + i*(chartRow+chartGap)
+		fill(img, image.Rect(left, top, right, top+chartRow), chartNoData)
+		for _, segment := range row.segments {
+			c := chartUp
+			if segment.Down {
+				c = chartDown
+			}
+			// Короткий сбой не должен пропасть из-за округления до пикселя
+			x0, x1 := x(segment.From), x(segment.To)
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			fill(img, image.Rect(x0, top, x1, top+chartRow), c)
+		}
+		label := []rune(latin(row.label))
+		if limit := (chartLabel - chartGap) / 7; len(label) > limit {
+			label = append(label[:limit-1], '~')
+		}
+		drawText(img, chartMargin, top+14, string(label))
+		drawText(img, chartMargin, top+30, fmt.Sprintf("%.2f%%", row.uptime))
+	}
+
+	// Ось времени: не больше восьми подписей
+	axis := chartMargin + len(rows)*(chartRow+chartGap)
+	step, layout := chartStep(span)
+	start := from.Truncate(step).Add(step)
+	if step >= 24*time.Hour {
+		// Дни отсчитываются от местной полуночи
+		y, m, d := from.Local().Date()
+		start = time.Date(y, m, d+1, 0, 0, 0, 0, time.Local)
+	}
+	for t := start; t.Before(to); t = t.Add(step) {
+		tx := x(t)
+		fill(img, image.Rect(tx, chartMargin, tx+1, axis-chartGap), chartGrid)
+		label := t.Local().Format(layout)
+		drawText(img, tx-len(label)*7/2, axis+12, label)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// chartStep подбирает шаг сетки и формат подписей оси для периода
+func chartStep(span time.Duration) (time.Duration, string) {
+	for _, step := range []time.Duration{time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour} {
+		if span/step <= 8 {
+			return step, "15:04"
+		}
+	}
+	for _, step := range []time.Duration{24 * time.Hour, 2 * 24 * time.Hour, 7 * 24 * time.Hour, 14 * 24 * time.Hour} {
+		if span/step <= 8 {
+			return step, "02.01"
+		}
+	}
+	return 30 * 24 * time.Hour, "02.01"
+}
+
+func fill(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
+
+// drawText пишет строку моноширинным шрифтом 7×13; y — базовая линия.
+// В шрифте только латиница, цифры и знаки, поэтому кириллицу нужно заранее
+// пропустить через latin.
+func drawText(img draw.Image, x, y int, s string) {
+	d := font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(chartText),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(s)
+}
+
+// cyrillicLatin — транслитерация русских букв для подписей графика
+var cyrillicLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// latin приводит строку к символам, которые есть в basicfont.Face7x13:
+// кириллица транслитерируется, остальные символы без глифа заменяются на «?»
+func latin(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if t, ok := cyrillicLatin[unicode.ToLower(r)]; ok {
+			if unicode.IsUpper(r) && t != "" {
+				t = strings.ToUpper(t[:1]) + t[1:]
+			}
+			b.WriteString(t)
+		} else if _, ok := basicfont.Face7x13.GlyphAdvance(r); ok {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('?')
+		}
+	}
+	return b.String()
+}
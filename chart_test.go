@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+)
+
+func TestLatin(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "api-eu 1", want: "api-eu 1"},
+		{in: "Прод Щука", want: "Prod Shchuka"},
+		{in: "Эхо съезд", want: "Ekho sezd"},
+		{in: "东京", want: "??"},
+	}
+	for _, tt := range tests {
+		if got := latin(tt.in); got != tt.want {
+			t.Errorf("latin(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRenderChartLongLabel(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []chartRowData{{label: "Основной кластер в Москве", uptime: 99.5}}
+	data, err := renderChart(rows, from, from.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("renderChart() returned an invalid PNG: %v", err)
+	}
+}
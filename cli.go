@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"status-bot/monitor"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// command — подкоманда status-bot
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+// commands — подкоманды в порядке вывода в справке; первая выполняется по умолчанию
+var commands = []command{
+	{"serve", "run the bot (default)", runServe},
+	{"check", "fetch every API once and print the parsed statuses", runCheck},
+	{"export", "print subscribed chats with their consoles and settings", runExport},
+	{"validate", "check the config file and exit", runValidate},
+	{"mockapi", "serve a fake status API for development", runMockAPI},
+}
+
+// runCLI выполняет подкоманду и возвращает код завершения. Без подкоманды
+// (в том числе когда первым идёт флаг, как в status-bot -config bot.yaml)
+// запускается serve.
+func runCLI(args []string) int {
+	name := commands[0].name
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		printUsage()
+		return 0
+	}
+
+	for _, c := range commands {
+		if c.name == name {
+			if err := c.run(args); err != nil {
+				fmt.Fprintf(os.Stderr, "status-bot %s: %v\n", name, err)
+				return 1
+			}
+			return 0
+		}
+	}
+	fmt.Fprintf(os.Stderr, "status-bot: unknown command %q\n\n", name)
+	printUsage()
+	return 2
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "Usage: status-bot <command> [flags]\n\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun status-bot <command> -h for the flags of a command.")
+}
+
+// loadCLIConfig загружает конфигурацию для подкоманд, которые не запускают бота
+func loadCLIConfig(path string) error {
+	config, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	setConfig(config)
+	return setupLogging(LogConfig{Level: cfg().Log.Level, Format: cfg().Log.Format})
+}
+
+// checkResult — результат разовой проверки одного API
+type checkResult struct {
+	Endpoint string                  `json:"endpoint"`
+	Type     string                  `json:"type"`
+	Consoles []monitor.ConsoleStatus `json:"consoles,omitempty"`
+	Error    string                  `json:"error,omitempty"`
+	Latency  string                  `json:"latency"`
+}
+
+// runCheck один раз опрашивает API и печатает разобранные статусы:
+// status-bot check [-config path] [-endpoint name] [-json]
+func runCheck(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	configPath := flags.String("config", "", "path to YAML or JSON config file")
+	only := flags.String("endpoint", "", "check only the endpoint with this name")
+	asJSON := flags.Bool("json", false, "print results as JSON")
+	flags.Parse(args)
+
+	if err := loadCLIConfig(*configPath); err != nil {
+		return err
+	}
+	if err := startVault(cfg().Vault); err != nil {
+		return err
+	}
+	defer stopVault()
+	apiClient = newAPIClient(cfg().HTTPClient, nil)
+	sources, err := newSources(cfg().endpoints())
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var results []checkResult
+	failed := 0
+	for _, endpoint := range cfg().endpoints() {
+		if *only != "" && endpoint.Name != *only {
+			continue
+		}
+		source := sources[endpoint.Name]
+		result := checkResult{Endpoint: endpoint.Name, Type: endpoint.Type}
+		if result.Type == "" {
+			result.Type = sourceStatusAPI
+		}
+
+		start := time.Now()
+		consoles, err := source.Fetch(ctx)
+		result.Latency = time.Since(start).Round(time.Millisecond).String()
+		if err != nil {
+			result.Error = err.Error()
+			failed++
+		}
+		result.Consoles = consoles
+		results = append(results, result)
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no endpoint named %q", *only)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		for _, result := range results {
+			fmt.Printf("%s (%s, %s):\n", result.Endpoint, result.Type, result.Latency)
+			if result.Error != "" {
+				fmt.Printf("  error: %s\n", result.Error)
+				continue
+			}
+			if len(result.Consoles) == 0 {
+				fmt.Println("  no consoles")
+			}
+			for _, console := range result.Consoles {
+				marker := ""
+				if isDown(console.Status) {
+					marker = "  [down]"
+				}
+				fmt.Printf("  %s: %s%s\n", console.Name, orDash(console.Status), marker)
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d endpoints failed", failed, len(results))
+	}
+	return nil
+}
+
+// exportedChat — подписка чата в выводе export
+type exportedChat struct {
+	ID           int64             `json:"id"`
+	SubscribedAt *time.Time        `json:"subscribed_at,omitempty"`
+	Consoles     []string          `json:"consoles"`
+	Settings     map[string]string `json:"settings"`
+}
+
+// runExport печатает подписанные чаты из хранилища:
+// status-bot export [-config path] [-format json|csv]
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	configPath := flags.String("config", "", "path to YAML or JSON config file")
+	format := flags.String("format", "json", "output format: json or csv")
+	flags.Parse(args)
+
+	if *format != "json" && *format != "csv" {
+		return fmt.Errorf("unknown format %q, expected json or csv", *format)
+	}
+	if err := loadCLIConfig(*configPath); err != nil {
+		return err
+	}
+	storage, err := openStorage(*cfg())
+	if err != nil {
+		return err
+	}
+	defer storage.Close()
+
+	chats, err := storage.Chats()
+	if err != nil {
+		return err
+	}
+	sort.Slice(chats, func(i, j int) bool { return chats[i].ID < chats[j].ID })
+
+	if *format == "csv" {
+		return exportCSV(chats)
+	}
+	exported := make([]exportedChat, 0, len(chats))
+	for _, chat := range chats {
+		e := exportedChat{ID: chat.ID, Consoles: chat.Consoles, Settings: chat.Settings}
+		if !chat.SubscribedAt.IsZero() {
+			at := chat.SubscribedAt
+			e.SubscribedAt = &at
+		}
+		if e.Consoles == nil {
+			e.Consoles = []string{}
+		}
+		if e.Settings == nil {
+			e.Settings = map[string]string{}
+		}
+		exported = append(exported, e)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(exported)
+}
+
+// exportCSV печатает чаты таблицей: консоли через «;», настройки как ключ=значение через «;»
+func exportCSV(chats []Chat) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"id", "subscribed_at", "consoles", "settings"})
+	for _, chat := range chats {
+		subscribed := ""
+		if !chat.SubscribedAt.IsZero() {
+			subscribed = chat.SubscribedAt.Format(time.RFC3339)
+		}
+		keys := make([]string, 0, len(chat.Settings))
+		for key := range chat.Settings {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		settings := make([]string, 0, len(keys))
+		for _, key := range keys {
+			settings = append(settings, key+"="+chat.Settings[key])
+		}
+		w.Write([]string{strconv.FormatInt(chat.ID, 10), subscribed, strings.Join(chat.Consoles, ";"), strings.Join(settings, ";")})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// runValidate проверяет файл конфигурации, не запуская бота:
+// status-bot validate [-config] path
+func runValidate(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	configPath := flags.String("config", "", "path to YAML or JSON config file")
+	flags.Parse(args)
+
+	path := *configPath
+	if path == "" && flags.NArg() > 0 {
+		path = flags.Arg(0)
+	}
+	if path == "" {
+		return fmt.Errorf("config file is required: status-bot validate -config bot.yaml")
+	}
+
+	config, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s is valid: %d endpoints\n", path, len(config.endpoints()))
+	if config.botToken() == "" {
+		fmt.Printf("warning: bot token is not set, %s environment variable is empty\n", config.TokenEnv)
+	}
+	return nil
+}
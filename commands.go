@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+)
+
+// router — обработчики обновлений Telegram, заполняется в registerHandlers
+var router = newRouter()
+
+// registerHandlers регистрирует все команды, кнопки и служебные обработчики бота
+func registerHandlers(r *Router) {
+	// Переход группы в супергруппу приходит служебным сообщением
+	r.Intercept(func(ctx context.Context, message *tgbotapi.Message) bool {
+		return handleMigration(message)
+	})
+	// Нажатия кнопок постоянной клавиатуры приходят обычным текстом
+	r.Intercept(handleMenuButton)
+
+	r.Command("start", func(ctx context.Context, message *tgbotapi.Message) {
+		// Добавляем чат в список для уведомлений
+		if err := store.AddChat(message.Chat.ID); err != nil {
+			slog.Error("Error saving chat", "chat_id", message.Chat.ID, "error", err)
+		}
+		sendWithKeyboard(message.Chat.ID, "start")
+	})
+	r.Command("stop", func(ctx context.Context, message *tgbotapi.Message) {
+		// Удаляем чат из списка для уведомлений
+		if err := store.RemoveChat(message.Chat.ID); err != nil {
+			slog.Error("Error removing chat", "chat_id", message.Chat.ID, "error", err)
+		}
+		reply(message.Chat.ID, "stop")
+	})
+	r.Command("status", func(ctx context.Context, message *tgbotapi.Message) {
+		// Запрашиваем статус немедленно, не дожидаясь следующей проверки
+		sendCurrentStatus(ctx, message.Chat.ID)
+	})
+	r.Command("subscribe", withArgs(handleSubscribe))
+	r.Command("unsubscribe", withArgs(handleUnsubscribe))
+	r.Command("quiet", withArgs(handleQuiet))
+	r.Command("frequency", withArgs(handleFrequency))
+	r.Command("digest", withArgs(handleDigest))
+	r.Command("snooze", withArgs(handleSnooze))
+	r.Command("mute", withArgs(handleMute))
+	r.Command("unmute", withArgs(handleUnmute))
+	r.Command("language", withArgs(handleLanguage))
+	r.Command("keyboard", withArgs(handleKeyboard))
+	r.Command("settings", func(ctx context.Context, message *tgbotapi.Message) {
+		handleSettings(message.Chat.ID)
+	})
+	r.Command("verbosity", withArgs(handleVerbosity))
+	r.Command("topic", handleTopic)
+	r.Command("live", withArgs(handleLive))
+	r.Command("uptime", withArgs(handleUptime))
+	r.Command("history", withArgs(handleHistory))
+	r.Command("chart", withArgs(handleChart))
+	r.Command("incidents", func(ctx context.Context, message *tgbotapi.Message) {
+		handleIncidents(message.Chat.ID)
+	})
+	r.Command("oncall", func(ctx context.Context, message *tgbotapi.Message) {
+		handleOnCall(message.Chat.ID)
+	})
+	r.Command("stats", func(ctx context.Context, message *tgbotapi.Message) {
+		handleStats(message.Chat.ID)
+	})
+	r.Command("add_monitor", handleAddMonitor)
+	r.Command("my_monitors", handleMyMonitors)
+	r.Command("remove_monitor", handleRemoveMonitor)
+
+	r.AdminCommand("check", func(ctx context.Context, message *tgbotapi.Message) {
+		handleForceCheck(ctx, message.Chat.ID)
+	})
+	r.AdminCommand("subscribers", func(ctx context.Context, message *tgbotapi.Message) {
+		handleSubscribers(message.Chat.ID)
+	})
+	r.AdminCommand("broadcast", withArgs(handleBroadcast))
+	r.AdminCommand("channel", withArgs(handleChannel))
+	r.AdminCommand("maintenance", withArgs(handleMaintenance))
+	r.AdminCommand("override", withArgs(handleOverride))
+	r.AdminCommand("testalert", withArgs(handleTestAlert))
+	r.AdminCommand("reload", func(ctx context.Context, message *tgbotapi.Message) {
+		handleReload(ctx, message.Chat.ID)
+	})
+
+	r.Callback(callbackSubscribe, handleSubscribeButton)
+	r.Callback(callbackUnsubscribe, handleUnsubscribeButton)
+	r.Callback(callbackStatus, handleStatusButton)
+	r.Callback(callbackAck, handleAckButton)
+	r.Callback(callbackHistory, handleHistoryButton)
+	r.Callback(callbackSettings, handleSettingsButton)
+	r.Callback(callbackChannel, handleChannelButton)
+
+	// Исправленная опечатка в команде выполняет её заново
+	r.EditedMessage(func(ctx context.Context, message *tgbotapi.Message) {
+		if message.IsCommand() {
+			r.DispatchCommand(ctx, message)
+		}
+	})
+
+	r.MyChatMember(handleMyChatMember)
+	r.InlineQuery(handleInlineQuery)
+}
+
+// withArgs приспосабливает обработчик вида f(chatID, args) к CommandHandler
+func withArgs(handler func(chatID int64, args string)) CommandHandler {
+	return func(ctx context.Context, message *tgbotapi.Message) {
+		handler(message.Chat.ID, message.CommandArguments())
+	}
+}
+
+// handleMyChatMember отписывает чаты, из которых бота удалили или где его
+// заблокировали, приветствует группы, куда бота добавили, и предлагает
+// администраторам подписать каналы, где бот стал администратором
+func handleMyChatMember(ctx context.Context, update *tgbotapi.ChatMemberUpdated) {
+	chatID := update.Chat.ID
+
+	switch update.NewChatMember.Status {
+	case "kicked", "left":
+		if err := store.RemoveChat(chatID); err != nil {
+			slog.Error("Error removing chat", "chat_id", chatID, "error", err)
+			return
+		}
+		slog.Info("Bot was removed from chat, unsubscribed", "chat_id", chatID)
+	case "member", "administrator":
+		// В канал приветствие ушло бы всем читателям; бот может писать в канал,
+		// только став администратором
+		if update.Chat.IsChannel() {
+			if update.NewChatMember.IsAdministrator() && !update.OldChatMember.IsAdministrator() {
+				offerChannel(update.Chat)
+			}
+			return
+		}
+		switch update.OldChatMember.Status {
+		case "left", "kicked":
+			if !update.Chat.IsPrivate() {
+				sendWithKeyboard(chatID, "welcome")
+			}
+		}
+	}
+}
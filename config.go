@@ -0,0 +1,375 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"status-bot/monitor"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config содержит все настройки бота, которые можно задать в файле конфигурации
+type Config struct {
+	APIURL        string           `yaml:"api_url" json:"api_url"`                     // Адрес API статусов консолей, если endpoints не заданы
+	Endpoints     []Endpoint       `yaml:"endpoints" json:"endpoints"`                 // Список отслеживаемых API
+	PollInterval  Duration         `yaml:"poll_interval" json:"poll_interval"`         // Интервал между проверками
+	Backoff       BackoffConfig    `yaml:"backoff" json:"backoff"`                     // Паузы между повторами при ошибках API
+	HTTPClient    HTTPClientConfig `yaml:"http_client" json:"http_client"`             // Таймауты и соединения HTTP-клиента для API
+	ErrorResponse string           `yaml:"error_response" json:"error_response"`       // Ответ API, который считается ошибкой и игнорируется
+	Token         string           `yaml:"token" json:"token"`                         // Токен бота; если пуст, читается из TokenEnv
+	TokenEnv      string           `yaml:"token_env" json:"token_env"`                 // Переменная окружения с токеном бота
+	StorageDir    string           `yaml:"storage_dir" json:"storage_dir"`             // Каталог для файлов с подписками и состоянием
+	Storage       StorageConfig    `yaml:"storage" json:"storage"`                     // Выбор хранилища подписок
+	History       Duration         `yaml:"history_retention" json:"history_retention"` // Сколько хранить историю смен статуса
+
+	DownStatuses    []string          `yaml:"down_statuses" json:"down_statuses"`       // Статусы консоли, означающие недоступность
+	Statuses        []StatusConfig    `yaml:"statuses" json:"statuses"`                 // Значки и важность статусов
+	Aliases         map[string]string `yaml:"aliases" json:"aliases"`                   // Понятные имена консолей по их ID из API
+	Flapping        FlappingConfig    `yaml:"flapping" json:"flapping"`                 // Обнаружение мигающих консолей
+	Confirm         ConfirmConfig     `yaml:"confirm" json:"confirm"`                   // Сколько проверок подряд подтверждают недоступность и восстановление
+	Ack             AckConfig         `yaml:"ack" json:"ack"`                           // Напоминания о непринятых инцидентах
+	FollowUps       FollowUpsConfig   `yaml:"followups" json:"followups"`               // Продолжения уведомлений о недоступности
+	Escalation      EscalationConfig  `yaml:"escalation" json:"escalation"`             // Пересылка непринятых инцидентов в отдельный чат
+	OnCall          OnCallConfig      `yaml:"oncall" json:"oncall"`                     // График дежурств
+	DefaultLanguage string            `yaml:"default_language" json:"default_language"` // Язык сообщений для чатов, не выбравших его через /language
+	ParseMode       string            `yaml:"parse_mode" json:"parse_mode"`             // Разметка уведомлений: пусто (обычный текст), html или markdownv2
+	Templates       TemplatesConfig   `yaml:"templates" json:"templates"`               // Шаблоны текста уведомлений
+	Rules           []RuleConfig      `yaml:"rules" json:"rules"`                       // Правила: о каких изменениях уведомлять и насколько они важны
+	Thresholds      []ThresholdConfig `yaml:"thresholds" json:"thresholds"`             // Тревоги по числовым полям консолей
+
+	Sender   SenderConfig   `yaml:"sender" json:"sender"`     // Ограничение скорости рассылки
+	Telegram TelegramConfig `yaml:"telegram" json:"telegram"` // Подключение к Bot API
+	Admins   []int64        `yaml:"admins" json:"admins"`     // Telegram ID пользователей с доступом к командам управления
+
+	HTTPListen   string `yaml:"http_listen" json:"http_listen"`     // Адрес служебного HTTP-сервера с /metrics, /healthz и /readyz; пусто — выключен
+	HealthChecks int    `yaml:"health_checks" json:"health_checks"` // Сколько последних проверок API должны пройти успешно для /readyz
+	PprofListen  string `yaml:"pprof_listen" json:"pprof_listen"`   // Loopback-адрес для net/http/pprof; пусто — выключен
+
+	Mode    string        `yaml:"mode" json:"mode"`       // Способ получения обновлений: polling или webhook
+	Webhook WebhookConfig `yaml:"webhook" json:"webhook"` // Настройки режима webhook
+
+	Schedules []ScheduleConfig `yaml:"schedules" json:"schedules"` // Регулярные задачи по cron-расписанию
+
+	Notifiers NotifiersConfig `yaml:"notifiers" json:"notifiers"` // Внешние каналы уведомлений и повторы доставки
+
+	Log            LogConfig            `yaml:"log" json:"log"`                         // Уровень и формат журнала
+	ErrorReporting ErrorReportingConfig `yaml:"error_reporting" json:"error_reporting"` // Sentry и другие трекеры ошибок
+	Tracing        TracingConfig        `yaml:"tracing" json:"tracing"`                 // Трассировка OpenTelemetry
+	Vault          VaultConfig          `yaml:"vault" json:"vault"`                     // Секреты из HashiCorp Vault
+	UserMonitors   UserMonitorsConfig   `yaml:"user_monitors" json:"user_monitors"`     // Мониторы, которые пользователи добавляют сами
+	Dashboard      DashboardConfig      `yaml:"dashboard" json:"dashboard"`             // Веб-панель на служебном HTTP-сервере
+	API            APIConfig            `yaml:"api" json:"api"`                         // REST API управления на служебном HTTP-сервере
+	GRPC           GRPCConfig           `yaml:"grpc" json:"grpc"`                       // gRPC API управления и поток изменений статуса
+	WebApp         WebAppConfig         `yaml:"webapp" json:"webapp"`                   // Мини-приложение Telegram
+}
+
+// Endpoint описывает одно отслеживаемое API статусов
+type Endpoint struct {
+	Name          string                `yaml:"name" json:"name"`                     // Имя для префикса уведомлений; по умолчанию — хост из URL
+	Type          string                `yaml:"type" json:"type"`                     // Источник статусов; по умолчанию status_api — API статусов консолей
+	URL           string                `yaml:"url" json:"url"`                       // Адрес API
+	PollInterval  Duration              `yaml:"poll_interval" json:"poll_interval"`   // Свой интервал проверки; по умолчанию общий
+	ErrorResponse string                `yaml:"error_response" json:"error_response"` // Свой ответ-ошибка; по умолчанию общий
+	Extract       monitor.ExtractConfig `yaml:"extract" json:"extract"`               // Пути JSONPath к консолям, именам и статусам для status_api и exec
+	Headers       map[string]string     `yaml:"headers" json:"headers"`               // Дополнительные заголовки запросов status_api, sse и websocket
+	Auth          AuthConfig            `yaml:"auth" json:"auth"`                     // Basic- или Bearer-авторизация запросов status_api, sse и websocket
+	ClientTLS     ClientTLSConfig       `yaml:"client_tls" json:"client_tls"`         // Клиентский сертификат и УЦ для status_api, sse и websocket
+
+	Exec       ExecSourceConfig       `yaml:"exec" json:"exec"`             // Команда для type: exec
+	Probe      ProbeSourceConfig      `yaml:"probe" json:"probe"`           // Хосты для type: probe
+	TLS        TLSSourceConfig        `yaml:"tls" json:"tls"`               // Сертификаты для type: tls
+	HTTP       HTTPSourceConfig       `yaml:"http" json:"http"`             // Проверки для type: http
+	Prometheus PrometheusSourceConfig `yaml:"prometheus" json:"prometheus"` // Запрос для type: prometheus
+	MQTT       MQTTSourceConfig       `yaml:"mqtt" json:"mqtt"`             // Брокер и топики для type: mqtt
+}
+
+// Duration — time.Duration, которая читается из строк вида "5s", "1m30s" или "30d"
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	var s string
+	if err := node.Decode(&s); err != nil {
+		return err
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	return d.parse(s)
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) parse(s string) error {
+	parsed, err := parseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// parseDuration разбирает длительность в формате time.ParseDuration,
+// дополнительно понимая целые дни: "7d", "30d"
+func parseDuration(s string) (time.Duration, error) {
+	if days := strings.TrimSuffix(s, "d"); days != s {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid number of days")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// defaultConfig возвращает настройки, с которыми бот работает без файла конфигурации
+func defaultConfig() Config {
+	return Config{
+		APIURL:       "https://4cloud.pro/api.php?method=get-consoles-status",
+		PollInterval: Duration{5 * time.Second},
+		Backoff:      BackoffConfig{Max: Duration{5 * time.Minute}, Multiplier: 2},
+		HTTPClient: HTTPClientConfig{
+			ConnectTimeout:  Duration{5 * time.Second},
+			ReadTimeout:     Duration{15 * time.Second},
+			KeepAlive:       Duration{30 * time.Second},
+			MaxIdleConns:    10,
+			MaxBodySize:     10 << 20,
+			IdleConnTimeout: Duration{90 * time.Second},
+		},
+		ErrorResponse:   `[{"Status":"Error"}]`,
+		TokenEnv:        "TELEGRAM_BOT_TOKEN",
+		StorageDir:      ".",
+		History:         Duration{90 * 24 * time.Hour},
+		DownStatuses:    []string{"Error"},
+		DefaultLanguage: "ru",
+		FollowUps:       FollowUpsConfig{Edit: true},
+		Sender:          SenderConfig{Rate: 25, Burst: 5, QueueSize: 1000},
+		Mode:            "polling",
+		HealthChecks:    3,
+		Notifiers:       NotifiersConfig{Retries: 3, RetryDelay: Duration{5 * time.Second}, QueueSize: 100},
+		ErrorReporting:  ErrorReportingConfig{After: 3},
+		Tracing:         TracingConfig{ServiceName: "status-bot", SampleRatio: 1},
+		Vault:           VaultConfig{TokenEnv: "VAULT_TOKEN", Timeout: Duration{10 * time.Second}},
+		Dashboard:       DashboardConfig{TokenEnv: "DASHBOARD_TOKEN", Refresh: Duration{30 * time.Second}},
+		API:             APIConfig{TokenEnv: "API_TOKEN"},
+		UserMonitors:    UserMonitorsConfig{DefaultInterval: Duration{time.Minute}, MinInterval: Duration{30 * time.Second}, MaxPerUser: 5, MaxPerHour: 30, Confirm: 3},
+		Log:             LogConfig{Level: "info", Format: "text", MaxSize: 100, MaxBackups: 10, MaxAge: Duration{30 * 24 * time.Hour}},
+	}
+}
+
+// loadConfig читает файл конфигурации поверх значений по умолчанию.
+// Формат определяется по расширению: .json — JSON, иначе YAML.
+// Пустой путь означает работу с настройками по умолчанию. Затем применяются
+// переменные окружения CLOUD_BOT_* (см. applyEnvOverrides).
+func loadConfig(path string) (Config, error) {
+	config := defaultConfig()
+	if path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return config, err
+		}
+
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			err = json.Unmarshal(data, &config)
+		} else {
+			err = yaml.Unmarshal(data, &config)
+		}
+		if err != nil {
+			return config, fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+
+	// Переменные CLOUD_BOT_* важнее файла конфигурации
+	if err := applyEnvOverrides(&config, os.Environ()); err != nil {
+		return config, fmt.Errorf("environment: %w", err)
+	}
+
+	if err := config.validate(); err != nil {
+		if path == "" {
+			return config, fmt.Errorf("invalid config: %w", err)
+		}
+		return config, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// endpoints возвращает список API с подставленными значениями по умолчанию.
+// Если endpoints не заданы, используется единственный api_url.
+func (c Config) endpoints() []Endpoint {
+	endpoints := c.Endpoints
+	if len(endpoints) == 0 {
+		endpoints = []Endpoint{{URL: c.APIURL}}
+	}
+
+	result := make([]Endpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if endpoint.Name == "" {
+			endpoint.Name = endpoint.URL
+			if u, err := url.Parse(endpoint.URL); err == nil && u.Host != "" {
+				endpoint.Name = u.Host
+			}
+		}
+		if endpoint.PollInterval.Duration <= 0 {
+			endpoint.PollInterval = c.PollInterval
+		}
+		if endpoint.ErrorResponse == "" {
+			endpoint.ErrorResponse = c.ErrorResponse
+		}
+		result = append(result, endpoint)
+	}
+	return result
+}
+
+func (c Config) validate() error {
+	if len(c.Endpoints) == 0 && c.APIURL == "" {
+		return fmt.Errorf("either api_url or endpoints must be set")
+	}
+	names := make(map[string]bool)
+	for i, endpoint := range c.endpoints() {
+		if endpoint.Name == "" {
+			return fmt.Errorf("endpoints[%d]: name must be set", i)
+		}
+		if _, err := newSource(endpoint); err != nil {
+			return fmt.Errorf("endpoints[%d]: %w", i, err)
+		}
+		if names[endpoint.Name] {
+			return fmt.Errorf("endpoints[%d]: duplicate name %q", i, endpoint.Name)
+		}
+		names[endpoint.Name] = true
+	}
+	if c.PollInterval.Duration <= 0 {
+		return fmt.Errorf("poll_interval must be positive")
+	}
+	if err := c.Backoff.validate(); err != nil {
+		return err
+	}
+	if err := c.HTTPClient.validate(); err != nil {
+		return err
+	}
+	if err := c.Telegram.validate(); err != nil {
+		return err
+	}
+	if err := c.Sender.validate(); err != nil {
+		return err
+	}
+	if c.History.Duration <= 0 {
+		return fmt.Errorf("history_retention must be positive")
+	}
+	if c.HealthChecks <= 0 {
+		return fmt.Errorf("health_checks must be positive")
+	}
+	if err := validatePprofListen(c.PprofListen); err != nil {
+		return err
+	}
+	if c.Token == "" && c.TokenEnv == "" {
+		return fmt.Errorf("either token or token_env must be set")
+	}
+	if catalogs[c.DefaultLanguage] == nil {
+		return fmt.Errorf("unknown default_language %q, available: %s", c.DefaultLanguage, languages())
+	}
+	if err := validateParseMode(c.ParseMode); err != nil {
+		return err
+	}
+	if err := c.OnCall.validate(); err != nil {
+		return err
+	}
+	if err := c.Escalation.validate(); err != nil {
+		return err
+	}
+	if err := c.Ack.validate(); err != nil {
+		return err
+	}
+	if err := c.Confirm.validate(); err != nil {
+		return err
+	}
+	if err := c.Flapping.validate(); err != nil {
+		return err
+	}
+	if err := c.Templates.validate(); err != nil {
+		return err
+	}
+	if err := validateStatuses(c.Statuses); err != nil {
+		return err
+	}
+	if err := validateAliases(c.Aliases); err != nil {
+		return err
+	}
+	if _, err := compileRules(c.Rules); err != nil {
+		return err
+	}
+	if _, err := compileThresholds(c.Thresholds); err != nil {
+		return err
+	}
+	if err := c.Notifiers.validate(); err != nil {
+		return err
+	}
+	if err := c.Storage.validate(); err != nil {
+		return err
+	}
+	if err := c.Log.validate(); err != nil {
+		return err
+	}
+	if err := c.ErrorReporting.validate(); err != nil {
+		return err
+	}
+	if err := c.Tracing.validate(); err != nil {
+		return err
+	}
+	if err := c.Vault.validate(); err != nil {
+		return err
+	}
+	if err := c.UserMonitors.validate(); err != nil {
+		return err
+	}
+	if err := c.Dashboard.validate(); err != nil {
+		return err
+	}
+	if err := c.GRPC.validate(); err != nil {
+		return err
+	}
+	if err := c.WebApp.validate(); err != nil {
+		return err
+	}
+	for _, schedule := range c.Schedules {
+		if err := schedule.validate(); err != nil {
+			return err
+		}
+	}
+	switch c.Mode {
+	case "polling":
+	case "webhook":
+		if err := c.Webhook.validate(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown mode %q, expected polling or webhook", c.Mode)
+	}
+	return nil
+}
+
+// botToken возвращает токен из конфигурации, из указанной переменной окружения
+// или из файла, путь к которому задан в переменной с суффиксом _FILE
+func (c Config) botToken() string {
+	if c.Token != "" {
+		return c.Token
+	}
+	return getenvSecret(c.TokenEnv)
+}
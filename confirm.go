@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"status-bot/monitor"
+	"sync"
+)
+
+// ConfirmConfig задаёт, сколько проверок подряд должен держаться новый статус
+// консоли, прежде чем о нём сообщат. 0 и 1 — сообщать сразу.
+type ConfirmConfig struct {
+	Down int `yaml:"down" json:"down"` // Проверок подряд до сообщения о недоступности
+	Up   int `yaml:"up" json:"up"`     // Проверок подряд до сообщения о восстановлении
+}
+
+func (c ConfirmConfig) validate() error {
+	if c.Down < 0 || c.Up < 0 {
+		return fmt.Errorf("confirm.down and confirm.up must not be negative")
+	}
+	return nil
+}
+
+// candidate — новый статус консоли, ещё не подтверждённый нужным числом проверок
+type candidate struct {
+	status string
+	polls  int
+}
+
+var (
+	candidatesMutex = &sync.Mutex{}
+	candidates      = make(map[string]map[string]*candidate) // По имени API и консоли
+)
+
+// confirmStatuses возвращает ответ API, в котором неподтверждённые переходы
+// в недоступность и обратно заменены прежними статусами. Подтверждённым
+// считается статус из последнего сохранённого ответа, поэтому он переживает
+// перезапуск. Ответ, который не удаётся разобрать, возвращается как есть.
+func confirmStatuses(endpoint, prev, status string) string {
+	if (cfg().Confirm.Down <= 1 && cfg().Confirm.Up <= 1) || prev == "" {
+		return status
+	}
+
+	prevConsoles, err := monitor.ParseStatuses(prev)
+	if err != nil {
+		return status
+	}
+	confirmed := make(map[string]string, len(prevConsoles))
+	for _, console := range prevConsoles {
+		confirmed[console.Name] = console.Status
+	}
+
+	var consoles []map[string]interface{}
+	if err := json.Unmarshal([]byte(status), &consoles); err != nil {
+		return status
+	}
+
+	candidatesMutex.Lock()
+	defer candidatesMutex.Unlock()
+
+	pending := candidates[endpoint]
+	if pending == nil {
+		pending = make(map[string]*candidate)
+		candidates[endpoint] = pending
+	}
+
+	changed := false
+	seen := make(map[string]bool, len(consoles))
+	for _, console := range consoles {
+		name, _ := console["Name"].(string)
+		current, _ := console["Status"].(string)
+		seen[name] = true
+
+		old, known := confirmed[name]
+		if !known || current == old {
+			delete(pending, name)
+			continue
+		}
+
+		required := 1
+		switch {
+		case isDown(current) && !isDown(old):
+			required = cfg().Confirm.Down
+		case isDown(old) && !isDown(current):
+			required = cfg().Confirm.Up
+		}
+
+		c := pending[name]
+		if c == nil || c.status != current {
+			c = &candidate{status: current}
+			pending[name] = c
+		}
+		c.polls++
+		if c.polls >= required {
+			delete(pending, name)
+			continue
+		}
+
+		// Пока не подтверждено — оставляем прежний статус
+		console["Status"] = old
+		changed = true
+	}
+	for name := range pending {
+		if !seen[name] {
+			delete(pending, name)
+		}
+	}
+
+	if !changed {
+		return status
+	}
+	normalized, err := json.Marshal(consoles)
+	if err != nil {
+		return status
+	}
+	return string(normalized)
+}
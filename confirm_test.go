@@ -0,0 +1,73 @@
+package main
+
+import "testing"
+
+func TestConfirmStatuses(t *testing.T) {
+	const (
+		online = `[{"Name":"a","Status":"Online"}]`
+		down   = `[{"Name":"a","Status":"Error"}]`
+		busy   = `[{"Name":"a","Status":"Busy"}]`
+	)
+
+	tests := []struct {
+		name string
+		down int
+		up   int
+		prev string
+		// Ответы подряд и что считать текущим после каждого
+		polls []string
+		want  []string
+	}{
+		{
+			name:  "без подтверждения",
+			prev:  online,
+			polls: []string{down},
+			want:  []string{down},
+		},
+		{
+			name:  "недоступность с третьей проверки",
+			down:  3,
+			prev:  online,
+			polls: []string{down, down, down},
+			want:  []string{online, online, down},
+		},
+		{
+			name:  "сбой не подтвердился",
+			down:  2,
+			prev:  online,
+			polls: []string{down, online, down},
+			want:  []string{online, online, online},
+		},
+		{
+			name:  "восстановление со второй проверки",
+			up:    2,
+			prev:  down,
+			polls: []string{online, online},
+			want:  []string{down, online},
+		},
+		{
+			name:  "смена без недоступности сразу",
+			down:  3,
+			prev:  online,
+			polls: []string{busy},
+			want:  []string{busy},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestBot(t)
+			config := *cfg()
+			config.Confirm = ConfirmConfig{Down: tt.down, Up: tt.up}
+			setConfig(config)
+
+			prev := tt.prev
+			for i, status := range tt.polls {
+				got := confirmStatuses("api:"+tt.name, prev, status)
+				if got != tt.want[i] {
+					t.Fatalf("poll %d: confirmStatuses() = %s, want %s", i+1, got, tt.want[i])
+				}
+				prev = got
+			}
+		})
+	}
+}
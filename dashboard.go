@@ -0,0 +1,191 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"status-bot/monitor"
+	"strings"
+	"time"
+)
+
+// DashboardConfig — веб-панель только для чтения на служебном HTTP-сервере
+// (http_listen): статусы консолей, инциденты, подписчики и проверки API
+type DashboardConfig struct {
+	Token    string   `yaml:"token" json:"token"`         // Токен доступа; если пуст, читается из TokenEnv. Без токена панель выключена
+	TokenEnv string   `yaml:"token_env" json:"token_env"` // Переменная окружения с токеном
+	Refresh  Duration `yaml:"refresh" json:"refresh"`     // Как часто страница обновляется сама
+}
+
+func (c DashboardConfig) validate() error {
+	if c.Refresh.Duration < 0 {
+		return fmt.Errorf("dashboard.refresh must not be negative")
+	}
+	return nil
+}
+
+func (c DashboardConfig) token() string {
+	if c.Token != "" {
+		return c.Token
+	}
+	return getenvSecret(c.TokenEnv)
+}
+
+// dashboardIncidents — сколько последних инцидентов показывать
+const dashboardIncidents = 20
+
+func init() {
+	httpMux.HandleFunc("/dashboard", handleDashboard)
+	httpMux.HandleFunc("/dashboard.json", handleDashboardJSON)
+}
+
+// dashboardData — всё, что показывает панель; /dashboard.json отдаёт то же
+type dashboardData struct {
+	Generated   time.Time           `json:"generated"`
+	Uptime      string              `json:"uptime"`
+	Subscribers int                 `json:"subscribers"`
+	Endpoints   []dashboardEndpoint `json:"endpoints"`
+	Incidents   []Incident          `json:"incidents"`
+
+	Lang    string `json:"-"`
+	Token   string `json:"-"`
+	Refresh int    `json:"-"` // Секунды до обновления страницы
+}
+
+type dashboardEndpoint struct {
+	Name        string                  `json:"name"`
+	OK          bool                    `json:"ok"`
+	LastCheckAt time.Time               `json:"last_check_at,omitempty"`
+	LastError   string                  `json:"last_error,omitempty"`
+	Consoles    []monitor.ConsoleStatus `json:"consoles"`
+}
+
+// authorizeDashboard проверяет токен из ?token= или заголовка Authorization: Bearer
+func authorizeDashboard(w http.ResponseWriter, r *http.Request) (string, bool) {
+	expected := cfg().Dashboard.token()
+	if expected == "" {
+		http.NotFound(w, r)
+		return "", false
+	}
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return "", false
+	}
+	return token, true
+}
+
+// buildDashboard собирает текущее состояние бота для панели
+func buildDashboard() dashboardData {
+	now := time.Now()
+	lang := cfg().DefaultLanguage
+	data := dashboardData{Generated: now, Uptime: formatDuration(lang, now.Sub(startTime)), Lang: lang}
+
+	if chats, err := store.Chats(); err != nil {
+		slog.Error("Error loading chats", "error", err)
+	} else {
+		data.Subscribers = len(chats)
+	}
+
+	data.Endpoints = endpointStatuses()
+
+	incidents, err := recentIncidents(dashboardIncidents)
+	if err != nil {
+		slog.Error("Error loading incidents", "error", err)
+	}
+	data.Incidents = incidents
+	return data
+}
+
+// endpointStatuses возвращает последние статусы консолей и состояние проверок каждого API
+func endpointStatuses() []dashboardEndpoint {
+	report := buildHealthReport(false)
+	endpoints := make([]dashboardEndpoint, 0, len(cfg().endpoints()))
+	for _, endpoint := range cfg().endpoints() {
+		r := report.Endpoints[endpoint.Name]
+		item := dashboardEndpoint{Name: endpoint.Name, OK: r.OK, LastCheckAt: r.LastCheckAt, LastError: r.LastError}
+		if status := statusEngine().Last(endpoint.Name); status != "" {
+			consoles, err := monitor.ParseStatuses(status)
+			if err != nil {
+				slog.Error("Error parsing last status", "endpoint", endpoint.Name, "error", err)
+			}
+			item.Consoles = consoles
+		}
+		endpoints = append(endpoints, item)
+	}
+	return endpoints
+}
+
+func handleDashboard(w http.ResponseWriter, r *http.Request) {
+	token, ok := authorizeDashboard(w, r)
+	if !ok {
+		return
+	}
+	data := buildDashboard()
+	data.Token = token
+	data.Refresh = int(cfg().Dashboard.Refresh.Seconds())
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTemplate.Execute(w, data); err != nil {
+		slog.Error("Error rendering dashboard", "error", err)
+	}
+}
+
+func handleDashboardJSON(w http.ResponseWriter, r *http.Request) {
+	if _, ok := authorizeDashboard(w, r); !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildDashboard())
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"tr":     tr,
+	"down":   isDown,
+	"dash":   orDash,
+	"open":   func(i Incident) bool { return i.open() },
+	"time":   func(t time.Time) string { return t.Local().Format("2006-01-02 15:04:05") },
+	"minute": func(t time.Time) string { return t.Local().Format("01-02 15:04") },
+}).Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+{{if .Refresh}}<meta http-equiv="refresh" content="{{.Refresh}}">{{end}}
+<title>{{tr .Lang "dashboard.title"}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 1.5em; background: #111; color: #eee; }
+h1 { font-size: 1.4em; margin: 0 0 .3em; }
+h2 { font-size: 1.1em; margin: 1.2em 0 .4em; }
+.meta { color: #999; }
+table { border-collapse: collapse; min-width: 20em; margin-bottom: .5em; }
+td, th { padding: .25em .8em; border-bottom: 1px solid #333; text-align: left; }
+.ok { color: #4caf50; }
+.down { color: #f44336; font-weight: bold; }
+.error { color: #ff9800; }
+</style>
+</head>
+<body>
+<h1>{{tr .Lang "dashboard.title"}}</h1>
+<div class="meta">{{tr .Lang "dashboard.meta" .Subscribers .Uptime (time .Generated)}} · <a href="dashboard.json?token={{.Token}}">JSON</a></div>
+{{range .Endpoints}}
+<h2>{{.Name}} {{if .OK}}<span class="ok">●</span>{{else}}<span class="down">●</span>{{end}}</h2>
+<div class="meta">{{tr $.Lang "dashboard.last_check"}}: {{if .LastCheckAt.IsZero}}—{{else}}{{time .LastCheckAt}}{{end}}{{if .LastError}} · <span class="error">{{.LastError}}</span>{{end}}</div>
+{{if .Consoles}}<table>
+{{range .Consoles}}<tr><td>{{.Name}}</td><td class="{{if down .Status}}down{{else}}ok{{end}}">{{dash .Status}}</td></tr>
+{{end}}</table>{{else}}<p class="meta">{{tr $.Lang "status.no_data"}}</p>{{end}}
+{{end}}
+<h2>{{tr .Lang "dashboard.incidents"}}</h2>
+{{if .Incidents}}<table>
+<tr><th>#</th><th>{{tr .Lang "dashboard.console"}}</th><th>{{tr .Lang "dashboard.started"}}</th><th>{{tr .Lang "dashboard.resolved"}}</th></tr>
+{{range .Incidents}}<tr><td>{{.ID}}</td><td>{{.Endpoint}} / {{.Console}}</td><td>{{minute .Started}}</td><td>{{if open .}}<span class="down">{{tr $.Lang "dashboard.open"}}</span>{{else}}{{minute .Resolved}}{{end}}</td></tr>
+{{end}}</table>{{else}}<p class="meta">{{tr .Lang "dashboard.no_incidents"}}</p>{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// frequencySetting — ключ настройки чата с минимальным интервалом между уведомлениями
+const frequencySetting = "frequency"
+
+// pendingDigest — уведомления, отложенные до конца тихих часов или интервала чата
+type pendingDigest struct {
+	texts []string
+	quiet bool // Откладывались ли уведомления из-за тихих часов
+}
+
+var (
+	pending      = make(map[int64]*pendingDigest)
+	lastNotified = make(map[int64]time.Time) // Когда чату в последний раз ушло уведомление
+	pendingMutex = &sync.Mutex{}
+)
+
+// chatFrequency возвращает минимальный интервал между уведомлениями чата или 0
+func chatFrequency(settings map[string]string) time.Duration {
+	d, err := time.ParseDuration(settings[frequencySetting])
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// notifyChat доставляет уведомление чату с учётом его настроек. Текст должен
+// быть размечен для newMarkupMessage.
+//   - пока действует /snooze, уведомления не отправляются;
+//   - в тихие часы некритичные уведомления откладываются до их окончания,
+//     а критичные отправляются без звука;
+//   - если задан интервал (/frequency), все изменения внутри него
+//     собираются в одну сводку; кнопки keyboard в сводку не попадают.
+func notifyChat(ctx context.Context, chat Chat, text string, critical bool, keyboard *tgbotapi.InlineKeyboardMarkup) {
+	notifyChatTopic(ctx, chat, chatTopic(chat.Settings, ""), nil, text, critical, keyboard)
+}
+
+// notifyChatTopic — notifyChat в тему форума thread об инцидентах incidents.
+// Отложенное в сводку уходит в тему всего чата и не дописывается.
+func notifyChatTopic(ctx context.Context, chat Chat, thread int, incidents []int, text string, critical bool, keyboard *tgbotapi.InlineKeyboardMarkup) {
+	now := time.Now()
+	if snoozed(chat.Settings, now) {
+		return
+	}
+	quiet := inQuietHours(chat.Settings, now)
+	frequency := chatFrequency(chat.Settings)
+
+	msg := newMarkupMessage(chat.ID, text)
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	if quiet && critical {
+		msg.DisableNotification = true
+		enqueueTopicMessage(ctx, chat.ID, thread, incidents, msg)
+		return
+	}
+	if !deferToDigest(chat.ID, text, quiet, frequency, now) {
+		// Очередь рассылки может ждать лимита, поэтому ставим в неё уже без pendingMutex
+		enqueueTopicMessage(ctx, chat.ID, thread, incidents, msg)
+	}
+}
+
+// deferToDigest откладывает текст в сводку чата, если сейчас тихие часы, не
+// прошёл интервал /frequency или сводка уже копится. Иначе отмечает, что чату
+// уходит уведомление, и возвращает false.
+func deferToDigest(chatID int64, text string, quiet bool, frequency time.Duration, now time.Time) bool {
+	pendingMutex.Lock()
+	defer pendingMutex.Unlock()
+
+	throttled := frequency > 0 && now.Sub(lastNotified[chatID]) < frequency
+	if quiet || throttled || pending[chatID] != nil {
+		digest := pending[chatID]
+		if digest == nil {
+			digest = &pendingDigest{}
+			pending[chatID] = digest
+		}
+		digest.texts = append(digest.texts, text)
+		digest.quiet = digest.quiet || quiet
+		return true
+	}
+	lastNotified[chatID] = now
+	return false
+}
+
+// telegramNotifier рассылает события подписанным чатам с учётом их консолей и настроек
+type telegramNotifier struct{}
+
+func (telegramNotifier) Name() string {
+	return "telegram"
+}
+
+func (telegramNotifier) Notify(ctx context.Context, event StatusEvent) error {
+	// Ошибка возможна только до начала рассылки, поэтому повтор не задублирует сообщения
+	chats, err := store.Chats()
+	if err != nil {
+		return fmt.Errorf("load chats: %w", err)
+	}
+
+	subscribed := make(map[int64]bool, len(chats))
+	for _, chat := range chats {
+		subscribed[chat.ID] = true
+	}
+	notifyOnCall(event.Endpoint, event.Changes, event.Transitions, subscribed, event.Time)
+
+	for _, chat := range chats {
+		if chat.Settings[liveSetting] != "" {
+			updateLiveDashboard(chat)
+			// Пробное событие показывается всегда, иначе его не увидеть
+			if liveOnlyMode(chat.Settings) && !event.Test {
+				continue
+			}
+		}
+
+		lang := settingsLang(chat.Settings)
+		if event.Changes == nil {
+			text := trMarkup(lang, "status.changed", escapeText(event.Endpoint.Name), formatStatuses(lang, event.Status))
+			if event.Test {
+				text += trMarkup(lang, "testalert.mark")
+			}
+			notifyChat(ctx, chat, text, false, nil)
+			continue
+		}
+
+		// Чат, подписанный на отдельные консоли, получает только их изменения
+		chatChanges := filterMuted(filterChanges(event.Changes, chat.Consoles), chat.Settings, event.Time)
+		chatChanges = filterVerbosity(chatChanges, chat.Settings, event)
+		chatChanges = editFollowUps(chat, lang, event, chatChanges)
+		// Консоли, направленные в разные темы форума, уходят отдельными сообщениями
+		for _, topic := range groupByTopic(chatChanges, chat.Settings) {
+			text := renderChanges(lang, event.Endpoint, topic.changes, event.Transitions, event.Time)
+			critical := event.criticalChanges(topic.changes)
+			incidents := incidentsOf(topic.changes, event.Transitions)
+			var keyboard *tgbotapi.InlineKeyboardMarkup
+			// Кнопки в канале видят все читатели, подтверждать инциденты там некому
+			if !isChannel(chat.Settings) {
+				keyboard = ackKeyboard(lang, incidents)
+			}
+			if critical {
+				text += onCallLine(lang, event.Time)
+			}
+			if event.Test {
+				text += trMarkup(lang, "testalert.mark")
+			}
+			notifyChatTopic(ctx, chat, topic.thread, incidentIDs(incidents), text, critical, keyboard)
+		}
+	}
+	for _, id := range closedIncidents(event) {
+		forgetIncidentAlerts(id)
+	}
+	return nil
+}
+
+// flushDigests отправляет накопленные сводки чатам, у которых закончились
+// тихие часы и прошёл минимальный интервал
+func flushDigests() {
+	pendingMutex.Lock()
+	chatIDs := make([]int64, 0, len(pending))
+	for chatID := range pending {
+		chatIDs = append(chatIDs, chatID)
+	}
+	pendingMutex.Unlock()
+
+	now := time.Now()
+	for _, chatID := range chatIDs {
+		settings, err := store.ChatSettings(chatID)
+		if err != nil {
+			slog.Error("Error loading chat settings", "chat_id", chatID, "error", err)
+			continue
+		}
+		if inQuietHours(settings, now) {
+			continue
+		}
+
+		pendingMutex.Lock()
+		if now.Sub(lastNotified[chatID]) < chatFrequency(settings) {
+			pendingMutex.Unlock()
+			continue
+		}
+		digest := pending[chatID]
+		delete(pending, chatID)
+		lastNotified[chatID] = now
+		pendingMutex.Unlock()
+		if digest == nil || len(digest.texts) == 0 {
+			continue
+		}
+
+		lang := settingsLang(settings)
+		header := trMarkup(lang, "digest.header")
+		if digest.quiet {
+			header = trMarkup(lang, "digest.quiet_header")
+		}
+		text := header + "\n\n" + strings.Join(digest.texts, "\n\n")
+		enqueueTopicMessage(context.Background(), chatID, chatTopic(settings, ""), nil, newMarkupMessage(chatID, text))
+	}
+}
+
+// handleFrequency обрабатывает /frequency 15m, /frequency off и /frequency без аргументов
+func handleFrequency(chatID int64, args string) {
+	args = strings.TrimSpace(args)
+
+	switch args {
+	case "":
+		settings, err := store.ChatSettings(chatID)
+		if err != nil {
+			slog.Error("Error loading chat settings", "chat_id", chatID, "error", err)
+		}
+		if d := chatFrequency(settings); d > 0 {
+			reply(chatID, "frequency.current", formatDuration(settingsLang(settings), d))
+			return
+		}
+		reply(chatID, "frequency.none")
+		return
+	case "off", "0":
+		if err := store.SetChatSetting(chatID, frequencySetting, ""); err != nil {
+			slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+			reply(chatID, "error.save")
+			return
+		}
+		reply(chatID, "frequency.off")
+		return
+	}
+
+	d, err := time.ParseDuration(args)
+	if err != nil || d < time.Minute {
+		reply(chatID, "frequency.usage")
+		return
+	}
+	if err := store.SetChatSetting(chatID, frequencySetting, d.String()); err != nil {
+		slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+	reply(chatID, "frequency.set", formatDuration(chatLang(chatID), d))
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeTelegram запоминает отправленные сообщения вместо вызовов Bot API
+type fakeTelegram struct {
+	Telegram
+
+	mutex    sync.Mutex
+	sent     []tgbotapi.MessageConfig
+	requests []tgbotapi.Params // Параметры прямых вызовов MakeRequest
+}
+
+func (b *fakeTelegram) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if m, ok := c.(tgbotapi.MessageConfig); ok {
+		b.sent = append(b.sent, m)
+	}
+	return tgbotapi.Message{MessageID: len(b.sent), Chat: &tgbotapi.Chat{}}, nil
+}
+
+func (b *fakeTelegram) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	return &tgbotapi.APIResponse{Ok: true, Result: []byte("true")}, nil
+}
+
+func (b *fakeTelegram) MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.requests = append(b.requests, params)
+	return &tgbotapi.APIResponse{Ok: true, Result: []byte("true")}, nil
+}
+
+// sentTo возвращает сообщения, отправленные в чат
+func (b *fakeTelegram) sentTo(chatID int64) []tgbotapi.MessageConfig {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	var result []tgbotapi.MessageConfig
+	for _, m := range b.sent {
+		if m.ChatID == chatID {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// setupTestBot настраивает бота с конфигурацией по умолчанию, хранилищем во
+// временном каталоге и поддельным клиентом Bot API
+func setupTestBot(t *testing.T) *fakeTelegram {
+	t.Helper()
+	config := defaultConfig()
+	config.StorageDir = t.TempDir()
+	state, err := buildRuntime(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setRuntime(state)
+
+	if store, err = openStorage(config); err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeTelegram{}
+	bot = fake
+
+	pendingMutex.Lock()
+	pending = make(map[int64]*pendingDigest)
+	lastNotified = make(map[int64]time.Time)
+	pendingMutex.Unlock()
+
+	t.Cleanup(func() {
+		store.Close()
+	})
+	return fake
+}
+
+// runNotify вызывает notifyChats и дожидается, пока события будут доставлены
+// во все каналы, а сообщения — отправлены
+func runNotify(t *testing.T, prev, status string) {
+	t.Helper()
+	startSender(cfg().Sender)
+	startDispatcher(cfg().Notifiers)
+	notifyChats(context.Background(), cfg().endpoints()[0], prev, status)
+	stopDispatcher()
+	sinks = nil
+	stopSender()
+}
+
+func TestNotifyChats(t *testing.T) {
+	now := time.Now()
+	quiet := now.Add(-time.Hour).Format("15:04") + "-" + now.Add(time.Hour).Format("15:04")
+
+	tests := []struct {
+		name     string
+		console  string
+		settings map[string]string
+		prev     string
+		status   string
+		want     int  // Сколько сообщений придёт сразу
+		silent   bool // Без звука
+		deferred bool // Отложено в сводку
+	}{
+		{
+			name:    "недоступность",
+			console: "a",
+			prev:    `[{"Name":"a","Status":"Online"}]`,
+			status:  `[{"Name":"a","Status":"Error"}]`,
+			want:    1,
+		},
+		{
+			name:     "snooze",
+			console:  "b",
+			settings: map[string]string{snoozeSetting: now.Add(time.Hour).Format(time.RFC3339)},
+			prev:     `[{"Name":"b","Status":"Online"}]`,
+			status:   `[{"Name":"b","Status":"Error"}]`,
+		},
+		{
+			name:     "snooze истёк",
+			console:  "c",
+			settings: map[string]string{snoozeSetting: now.Add(-time.Hour).Format(time.RFC3339)},
+			prev:     `[{"Name":"c","Status":"Online"}]`,
+			status:   `[{"Name":"c","Status":"Error"}]`,
+			want:     1,
+		},
+		{
+			name:     "тихие часы, некритичное изменение",
+			console:  "d",
+			settings: map[string]string{quietHoursSetting: quiet},
+			prev:     `[{"Name":"d","Status":"Online"}]`,
+			status:   `[{"Name":"d","Status":"Busy"}]`,
+			deferred: true,
+		},
+		{
+			name:     "тихие часы, недоступность",
+			console:  "e",
+			settings: map[string]string{quietHoursSetting: quiet},
+			prev:     `[{"Name":"e","Status":"Online"}]`,
+			status:   `[{"Name":"e","Status":"Error"}]`,
+			want:     1,
+			silent:   true,
+		},
+		{
+			name:     "отключённая консоль",
+			console:  "f",
+			settings: map[string]string{muteSettingPrefix + "f": muteForever},
+			prev:     `[{"Name":"f","Status":"Online"}]`,
+			status:   `[{"Name":"f","Status":"Error"}]`,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := setupTestBot(t)
+			chatID := int64(100 + i)
+			if err := store.AddChat(chatID); err != nil {
+				t.Fatal(err)
+			}
+			for key, value := range tt.settings {
+				if err := store.SetChatSetting(chatID, key, value); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			runNotify(t, tt.prev, tt.status)
+
+			sent := fake.sentTo(chatID)
+			if len(sent) != tt.want {
+				t.Fatalf("sent %d messages, want %d: %v", len(sent), tt.want, sent)
+			}
+			for _, m := range sent {
+				if !strings.Contains(m.Text, "Консоль "+tt.console+" недоступна") {
+					t.Errorf("alert text %q does not report the outage", m.Text)
+				}
+				if m.DisableNotification != tt.silent {
+					t.Errorf("DisableNotification = %v, want %v", m.DisableNotification, tt.silent)
+				}
+			}
+			pendingMutex.Lock()
+			digest := pending[chatID]
+			pendingMutex.Unlock()
+			if (digest != nil) != tt.deferred {
+				t.Errorf("deferred to digest = %v, want %v", digest != nil, tt.deferred)
+			}
+		})
+	}
+}
+
+func TestNotifyChatsConsoleSubscription(t *testing.T) {
+	fake := setupTestBot(t)
+	const subscribed, other = 200, 201
+	if err := store.AddChatConsole(subscribed, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddChatConsole(other, "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	runNotify(t, `[{"Name":"a","Status":"Online"},{"Name":"b","Status":"Online"}]`, `[{"Name":"a","Status":"Error"},{"Name":"b","Status":"Online"}]`)
+
+	if n := len(fake.sentTo(subscribed)); n != 1 {
+		t.Errorf("chat subscribed to the console got %d messages, want 1", n)
+	}
+	if n := len(fake.sentTo(other)); n != 0 {
+		t.Errorf("chat subscribed to another console got %d messages, want 0", n)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Цвета полоски embed в Discord
+const (
+	discordColorDown      = 0xE74C3C // Консоль стала недоступна
+	discordColorRecovered = 0x2ECC71 // Все изменения — восстановление
+	discordColorChanged   = 0xF1C40F // Прочие изменения статуса
+)
+
+// discordMaxDescription — предел длины описания embed в Discord
+const discordMaxDescription = 4096
+
+// DiscordConfig описывает webhook канала Discord
+type DiscordConfig struct {
+	Name       string `yaml:"name" json:"name"`               // Имя для логов; по умолчанию «discord»
+	WebhookURL string `yaml:"webhook_url" json:"webhook_url"` // Адрес webhook из настроек канала
+	Username   string `yaml:"username" json:"username"`       // Имя отправителя вместо заданного в webhook
+	Mention    string `yaml:"mention" json:"mention"`         // Упоминание при недоступности, например @here или <@&ROLE_ID>
+	Language   string `yaml:"language" json:"language"`       // Язык уведомлений; по умолчанию default_language
+}
+
+func (c DiscordConfig) validate(i int) error {
+	if c.WebhookURL == "" {
+		return fmt.Errorf("notifiers.discord[%d]: webhook_url must be set", i)
+	}
+	if c.Language != "" && catalogs[c.Language] == nil {
+		return fmt.Errorf("notifiers.discord[%d]: unknown language %q, available: %s", i, c.Language, languages())
+	}
+	return nil
+}
+
+// discordNotifier отправляет события в Discord через webhook
+type discordNotifier struct {
+	config DiscordConfig
+}
+
+func newDiscordNotifier(config DiscordConfig) *discordNotifier {
+	return &discordNotifier{config: config}
+}
+
+func (n *discordNotifier) Name() string {
+	if n.config.Name != "" {
+		return n.config.Name
+	}
+	return "discord"
+}
+
+type discordEmbed struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Color       int    `json:"color"`
+	Timestamp   string `json:"timestamp"`
+}
+
+type discordMessage struct {
+	Username string         `json:"username,omitempty"`
+	Content  string         `json:"content,omitempty"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+func (n *discordNotifier) Notify(ctx context.Context, event StatusEvent) error {
+	lang := notifierLang(n.config.Language)
+	title, description := eventText(lang, event), ""
+	// Первая строка уведомления — заголовок с именем API, остальное — описание
+	if i := strings.IndexByte(title, '\n'); i >= 0 {
+		title, description = title[:i], strings.TrimSpace(title[i+1:])
+	}
+	if runes := []rune(description); len(runes) > discordMaxDescription {
+		description = string(runes[:discordMaxDescription-1]) + "…"
+	}
+
+	message := discordMessage{
+		Username: n.config.Username,
+		Embeds: []discordEmbed{{
+			Title:       title,
+			Description: description,
+			Color:       discordColor(event),
+			Timestamp:   event.Time.UTC().Format(time.RFC3339),
+		}},
+	}
+	if event.critical() {
+		message.Content = n.config.Mention
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := notifierClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		data, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("discord returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// discordColor выбирает цвет embed: красный при недоступности, зелёный,
+// если консоли только восстановились, иначе жёлтый
+func discordColor(event StatusEvent) int {
+	if event.critical() {
+		return discordColorDown
+	}
+	if len(event.Changes) == 0 {
+		return discordColorChanged
+	}
+	for _, change := range event.Changes {
+		if event.Transitions[change.Name].kind != changeRecovered {
+			return discordColorChanged
+		}
+	}
+	return discordColorRecovered
+}
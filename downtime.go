@@ -0,0 +1,104 @@
+package main
+
+import (
+	"log/slog"
+	"status-bot/monitor"
+	"time"
+)
+
+// Виды изменений статуса консоли
+const (
+	changeUpdated   = "changed"   // Обычная смена статуса
+	changeDown      = "down"      // Консоль стала недоступна
+	changeRecovered = "recovered" // Консоль снова доступна
+)
+
+// transition — смена статуса консоли с учётом истории недоступности
+type transition struct {
+	kind     string
+	since    time.Time     // Для changeDown — когда консоль стала недоступна
+	downtime time.Duration // Для changeRecovered — сколько длилась недоступность
+	incident Incident      // Инцидент, открытый (changeDown) или закрытый (changeRecovered) изменением
+}
+
+// downSinceKey — ключ состояния со временем, когда консоль стала недоступна
+func downSinceKey(endpoint, console string) string {
+	return "down_since:" + endpoint + ":" + console
+}
+
+// trackTransitions определяет вид каждого изменения и запоминает начало
+// недоступности, чтобы после восстановления сообщить её длительность даже
+// после перезапуска бота. Возвращает вид изменения по имени консоли.
+func trackTransitions(endpoint string, changes []monitor.StatusChange, now time.Time) map[string]transition {
+	result := make(map[string]transition, len(changes))
+	for _, change := range changes {
+		key := downSinceKey(endpoint, change.Name)
+		wasDown, down := isDown(change.Old), isDown(change.New)
+
+		switch {
+		case down && !wasDown:
+			if err := store.SetState(key, now.Format(time.RFC3339)); err != nil {
+				slog.Error("Error saving downtime", "endpoint", endpoint, "console", change.Name, "error", err)
+			}
+			incident, err := startIncident(endpoint, change.Name, now)
+			if err != nil {
+				slog.Error("Error opening incident", "endpoint", endpoint, "console", change.Name, "error", err)
+			}
+			result[change.Name] = transition{kind: changeDown, since: now, incident: incident}
+		case wasDown && !down:
+			t := transition{kind: changeUpdated}
+			if since, ok := downSince(key); ok {
+				t = transition{kind: changeRecovered, downtime: now.Sub(since)}
+			}
+			if err := store.SetState(key, ""); err != nil {
+				slog.Error("Error clearing downtime", "endpoint", endpoint, "console", change.Name, "error", err)
+			}
+			t.incident = resolveIncident(endpoint, change.Name, now)
+			// Пропавшая консоль не восстановилась, а просто исчезла из ответа
+			if change.New == "" {
+				t = transition{kind: changeUpdated}
+			}
+			result[change.Name] = t
+		default:
+			result[change.Name] = transition{kind: changeUpdated}
+		}
+	}
+	return result
+}
+
+// downSince возвращает сохранённое время начала недоступности
+func downSince(key string) (time.Time, bool) {
+	value, err := store.State(key)
+	if err != nil {
+		slog.Error("Error loading state", "key", key, "error", err)
+		return time.Time{}, false
+	}
+	if value == "" {
+		return time.Time{}, false
+	}
+	since, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return since, true
+}
+
+// formatTransition выводит строку об изменении одной консоли: отдельные
+// сообщения о недоступности и восстановлении, для остального — «старый → новый».
+// Результат размечен для newMarkupMessage.
+func formatTransition(lang string, change monitor.StatusChange, t transition) string {
+	name := bold(consoleName(lang, change.Name))
+	var line string
+	switch t.kind {
+	case changeDown:
+		line = trMarkup(lang, "change.down", name, escapeText(t.since.Format("15:04")))
+	case changeRecovered:
+		line = trMarkup(lang, "change.recovered", name, escapeText(formatDuration(lang, t.downtime)))
+	default:
+		return formatChanges(lang, []monitor.StatusChange{change})
+	}
+	if t.incident.ID != 0 {
+		line += trMarkup(lang, "incident.ref", t.incident.ID)
+	}
+	return line
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// dryRun включается флагом --dry-run: бот опрашивает API и проходит всю
+// рассылку, но вместо отправки пишет в лог, что и кому ушло бы, ничего не
+// сохраняет в хранилище и не получает обновления Telegram, чтобы не мешать
+// рабочему экземпляру с тем же токеном и той же базой
+var dryRun bool
+
+// dryRunBot пишет в лог сообщения вместо отправки; получение обновлений
+// и остальные вызовы передаются настоящему клиенту
+type dryRunBot struct {
+	Telegram
+}
+
+func (b dryRunBot) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	slog.Info("[dry-run] would send message", "message", describeMessage(c))
+	return tgbotapi.Message{}, nil
+}
+
+func (b dryRunBot) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	slog.Info("[dry-run] would call Bot API", "request", fmt.Sprintf("%T", c))
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+func (b dryRunBot) MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error) {
+	slog.Info("[dry-run] would call Bot API", "request", endpoint)
+	return &tgbotapi.APIResponse{Ok: true}, nil
+}
+
+// describeMessage — адресат и текст сообщения для лога
+func describeMessage(msg tgbotapi.Chattable) string {
+	switch m := msg.(type) {
+	case tgbotapi.MessageConfig:
+		text := fmt.Sprintf("to chat %d: %s", m.ChatID, strconv.Quote(plainText(m.Text, m.ParseMode)))
+		if m.DisableNotification {
+			text += " (silent)"
+		}
+		if m.ReplyMarkup != nil {
+			text += " (with buttons)"
+		}
+		return text
+	case tgbotapi.DocumentConfig:
+		return fmt.Sprintf("document to chat %d: %s", m.ChatID, strconv.Quote(m.Caption))
+	case tgbotapi.EditMessageTextConfig:
+		return fmt.Sprintf("edit of message %d in chat %d: %s", m.MessageID, m.ChatID, strconv.Quote(plainText(m.Text, m.ParseMode)))
+	}
+	return fmt.Sprintf("%T", msg)
+}
+
+// dryRunNotifier пишет события внешних каналов в лог вместо доставки
+type dryRunNotifier struct {
+	Notifier
+}
+
+func (n dryRunNotifier) Notify(ctx context.Context, event StatusEvent) error {
+	slog.Info("[dry-run] would notify", "notifier", n.Name(), "text", eventText(cfg().DefaultLanguage, event))
+	return nil
+}
+
+// dryRunStorage читает подписки и состояние из настоящего хранилища, а
+// изменения держит в памяти до конца работы
+type dryRunStorage struct {
+	Storage
+
+	mutex sync.Mutex
+	state map[string]string // Изменённые значения состояния; пустое — удалено
+}
+
+func newDryRunStorage(store Storage) *dryRunStorage {
+	return &dryRunStorage{Storage: store, state: make(map[string]string)}
+}
+
+func (s *dryRunStorage) State(key string) (string, error) {
+	s.mutex.Lock()
+	value, ok := s.state[key]
+	s.mutex.Unlock()
+	if ok {
+		return value, nil
+	}
+	return s.Storage.State(key)
+}
+
+func (s *dryRunStorage) SetState(key, value string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.state[key] = value
+	return nil
+}
+
+func (s *dryRunStorage) AddChat(chatID int64) error {
+	slog.Info("[dry-run] would subscribe chat", "chat_id", chatID)
+	return nil
+}
+
+func (s *dryRunStorage) RemoveChat(chatID int64) error {
+	slog.Info("[dry-run] would unsubscribe chat", "chat_id", chatID)
+	return nil
+}
+
+func (s *dryRunStorage) AddChatConsole(chatID int64, console string) error {
+	slog.Info("[dry-run] would subscribe chat to console", "chat_id", chatID, "console", console)
+	return nil
+}
+
+func (s *dryRunStorage) RemoveChatConsole(chatID int64, console string) error {
+	slog.Info("[dry-run] would unsubscribe chat from console", "chat_id", chatID, "console", console)
+	return nil
+}
+
+func (s *dryRunStorage) SetChatSetting(chatID int64, key, value string) error {
+	slog.Info("[dry-run] would save chat setting", "key", key, "value", value, "chat_id", chatID)
+	return nil
+}
+
+func (s *dryRunStorage) AddHistory(entry HistoryEntry) error {
+	return nil
+}
+
+func (s *dryRunStorage) PruneHistory(before time.Time) error {
+	return nil
+}
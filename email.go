@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Важность события для порога получателей письма
+const (
+	severityInfo     = "info"     // Любая смена статуса
+	severityCritical = "critical" // Недоступность и восстановление консолей
+)
+
+// EmailConfig описывает SMTP-сервер и получателей писем
+type EmailConfig struct {
+	Name        string           `yaml:"name" json:"name"`                 // Имя для логов; по умолчанию «email»
+	Host        string           `yaml:"host" json:"host"`                 // SMTP-сервер
+	Port        int              `yaml:"port" json:"port"`                 // Порт; по умолчанию 587, а при tls — 465
+	TLS         bool             `yaml:"tls" json:"tls"`                   // Сразу подключаться по TLS (SMTPS); иначе STARTTLS, если сервер его поддерживает
+	Username    string           `yaml:"username" json:"username"`         // Логин; пусто — без авторизации
+	Password    string           `yaml:"password" json:"password"`         // Пароль; если пуст, читается из PasswordEnv
+	PasswordEnv string           `yaml:"password_env" json:"password_env"` // Переменная окружения с паролем
+	From        string           `yaml:"from" json:"from"`                 // Адрес отправителя
+	Recipients  []EmailRecipient `yaml:"recipients" json:"recipients"`     // Получатели
+	Language    string           `yaml:"language" json:"language"`         // Язык писем; по умолчанию default_language
+}
+
+// EmailRecipient — получатель писем с порогом важности
+type EmailRecipient struct {
+	Address  string `yaml:"address" json:"address"`   // Адрес получателя
+	Severity string `yaml:"severity" json:"severity"` // info (по умолчанию) — все изменения, critical — только недоступность и восстановление
+}
+
+func (c EmailConfig) validate(i int) error {
+	if c.Host == "" {
+		return fmt.Errorf("notifiers.email[%d]: host must be set", i)
+	}
+	if c.From == "" {
+		return fmt.Errorf("notifiers.email[%d]: from must be set", i)
+	}
+	if len(c.Recipients) == 0 {
+		return fmt.Errorf("notifiers.email[%d]: recipients must not be empty", i)
+	}
+	for j, recipient := range c.Recipients {
+		if recipient.Address == "" {
+			return fmt.Errorf("notifiers.email[%d].recipients[%d]: address must be set", i, j)
+		}
+		switch recipient.Severity {
+		case "", severityInfo, severityCritical:
+		default:
+			return fmt.Errorf("notifiers.email[%d].recipients[%d]: unknown severity %q, expected info or critical", i, j, recipient.Severity)
+		}
+	}
+	if c.Language != "" && catalogs[c.Language] == nil {
+		return fmt.Errorf("notifiers.email[%d]: unknown language %q, available: %s", i, c.Language, languages())
+	}
+	return nil
+}
+
+// password возвращает пароль из конфигурации или из указанной переменной окружения
+func (c EmailConfig) password() string {
+	if c.Password != "" {
+		return c.Password
+	}
+	if c.PasswordEnv != "" {
+		return getenvSecret(c.PasswordEnv)
+	}
+	return ""
+}
+
+// address возвращает адрес SMTP-сервера с портом по умолчанию
+func (c EmailConfig) address() string {
+	port := c.Port
+	if port == 0 {
+		port = 587
+		if c.TLS {
+			port = 465
+		}
+	}
+	return net.JoinHostPort(c.Host, strconv.Itoa(port))
+}
+
+// emailNotifier отправляет события письмами
+type emailNotifier struct {
+	config EmailConfig
+}
+
+func newEmailNotifier(config EmailConfig) *emailNotifier {
+	return &emailNotifier{config: config}
+}
+
+func (n *emailNotifier) Name() string {
+	if n.config.Name != "" {
+		return n.config.Name
+	}
+	return "email"
+}
+
+// eventSeverity возвращает critical, если правила назначили изменению такую
+// важность или консоль без назначенной важности стала недоступна или восстановилась
+func eventSeverity(event StatusEvent) string {
+	for _, change := range event.Changes {
+		if severity, ok := event.Severities[change.Name]; ok {
+			if severity == severityCritical {
+				return severityCritical
+			}
+			continue
+		}
+		switch event.Transitions[change.Name].kind {
+		case changeDown, changeRecovered:
+			return severityCritical
+		}
+	}
+	return severityInfo
+}
+
+func (n *emailNotifier) Notify(ctx context.Context, event StatusEvent) error {
+	severity := eventSeverity(event)
+	var to []string
+	for _, recipient := range n.config.Recipients {
+		if recipient.Severity == severityCritical && severity != severityCritical {
+			continue
+		}
+		to = append(to, recipient.Address)
+	}
+	if len(to) == 0 {
+		return nil
+	}
+
+	text := eventText(notifierLang(n.config.Language), event)
+	subject := text
+	if i := strings.IndexByte(subject, '\n'); i >= 0 {
+		subject = subject[:i]
+	}
+	return n.send(ctx, to, buildEmail(n.config.From, to, subject, text, event.Time))
+}
+
+// buildEmail собирает письмо в UTF-8 с темой subject
+func buildEmail(from string, to []string, subject, body string, date time.Time) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// send доставляет письмо через SMTP-сервер. В отличие от smtp.SendMail
+// соединение учитывает контекст, поэтому зависший сервер не держит рассылку.
+func (n *emailNotifier) send(ctx context.Context, to []string, message []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.config.address())
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	tlsConfig := &tls.Config{ServerName: n.config.Host}
+	if n.config.TLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, n.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if !n.config.TLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+	if n.config.Username != "" {
+		auth := smtp.PlainAuth("", n.config.Username, n.config.password(), n.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	// В from может быть имя отправителя, а в MAIL FROM нужен только адрес
+	from := n.config.From
+	if address, err := mail.ParseAddress(from); err == nil {
+		from = address.Address
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, address := range to {
+		if err := client.Rcpt(address); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
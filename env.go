@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// envPrefix — префикс переменных окружения, переопределяющих настройки
+const envPrefix = "CLOUD_BOT_"
+
+// applyEnvOverrides переопределяет настройки переменными CLOUD_BOT_*.
+// Имя переменной — путь к ключу конфигурации в верхнем регистре через "_":
+// CLOUD_BOT_POLL_INTERVAL=10s, CLOUD_BOT_LOG_LEVEL=debug,
+// CLOUD_BOT_SENDER_RATE=20. Значение разбирается как YAML, поэтому
+// списки и вложенные разделы задаются целиком:
+// CLOUD_BOT_ADMINS='[123, 456]', CLOUD_BOT_ENDPOINTS='[{name: main, url: "https://..."}]'.
+// Раздел, заданный целиком, дополняет значения из файла, а отдельные ключи
+// раздела применяются после него. Переменная с неизвестным именем считается
+// ошибкой, чтобы опечатка не оставалась незамеченной.
+func applyEnvOverrides(config *Config, environ []string) error {
+	fields := make(map[string]reflect.Value)
+	collectEnvFields(reflect.ValueOf(config).Elem(), strings.TrimSuffix(envPrefix, "_"), fields)
+
+	// После сортировки CLOUD_BOT_LOG идёт раньше CLOUD_BOT_LOG_LEVEL
+	environ = append([]string(nil), environ...)
+	sort.Strings(environ)
+
+	var unknown []string
+	for _, entry := range environ {
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || !strings.HasPrefix(name, envPrefix) {
+			continue
+		}
+		field, ok := fields[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		if err := setEnvField(field, value); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown environment variables: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// collectEnvFields собирает поля структуры по именам переменных окружения.
+// Вложенные структуры доступны и целиком, и по отдельным полям; типы со своим
+// разбором YAML (например, Duration) задаются только целиком.
+func collectEnvFields(v reflect.Value, prefix string, fields map[string]reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if key == "" || key == "-" || !t.Field(i).IsExported() {
+			continue
+		}
+		name := prefix + "_" + strings.ToUpper(key)
+		field := v.Field(i)
+		fields[name] = field
+		if field.Kind() == reflect.Struct && !field.Addr().Type().Implements(yamlUnmarshaler) {
+			collectEnvFields(field, name, fields)
+		}
+	}
+}
+
+var yamlUnmarshaler = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+// setEnvField записывает значение переменной в поле. Строки берутся как
+// есть, остальные типы разбираются как YAML: списки и словари заменяются
+// целиком, структуры дополняются, как при чтении файла.
+func setEnvField(field reflect.Value, value string) error {
+	if field.Kind() == reflect.String {
+		field.SetString(value)
+		return nil
+	}
+	parsed := reflect.New(field.Type())
+	if field.Kind() == reflect.Struct {
+		parsed.Elem().Set(field)
+	}
+	if err := yaml.Unmarshal([]byte(value), parsed.Interface()); err != nil {
+		return err
+	}
+	field.Set(parsed.Elem())
+	return nil
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/getsentry/sentry-go"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrorReportingConfig задаёт, куда сообщать о падениях и повторяющихся
+// ошибках бота, чтобы о них узнавали без чтения журнала
+type ErrorReportingConfig struct {
+	SentryDSN    string `yaml:"sentry_dsn" json:"sentry_dsn"`         // DSN проекта в Sentry
+	SentryDSNEnv string `yaml:"sentry_dsn_env" json:"sentry_dsn_env"` // Переменная окружения с DSN, если sentry_dsn пуст
+	Environment  string `yaml:"environment" json:"environment"`       // Окружение в Sentry, например production
+	WebhookURL   string `yaml:"webhook_url" json:"webhook_url"`       // Адрес, куда POST-запросом уходит каждая ошибка в JSON
+	After        int    `yaml:"after" json:"after"`                   // Сколько ошибок опроса API или отправки в чат подряд считать сбоем
+}
+
+func (c ErrorReportingConfig) validate() error {
+	if c.After <= 0 {
+		return fmt.Errorf("error_reporting.after must be positive")
+	}
+	if c.WebhookURL != "" && !strings.HasPrefix(c.WebhookURL, "http://") && !strings.HasPrefix(c.WebhookURL, "https://") {
+		return fmt.Errorf("error_reporting.webhook_url must be an http(s) URL")
+	}
+	return nil
+}
+
+func (c ErrorReportingConfig) dsn() string {
+	if c.SentryDSN != "" {
+		return c.SentryDSN
+	}
+	if c.SentryDSNEnv != "" {
+		return getenvSecret(c.SentryDSNEnv)
+	}
+	return ""
+}
+
+// errorReport — одна ошибка для трекера
+type errorReport struct {
+	Message string            `json:"message"`
+	Error   string            `json:"error"`
+	Panic   bool              `json:"panic"`
+	Stack   string            `json:"stack,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	Time    time.Time         `json:"time"`
+}
+
+// errorReporter доставляет ошибки в трекер. report не должен блокировать
+// вызывающего; flush дожидается отправки накопленного при остановке.
+type errorReporter interface {
+	report(r errorReport)
+	flush(timeout time.Duration)
+}
+
+var (
+	reporters      []errorReporter
+	repeatedErrors = &failureCounter{counts: make(map[string]int)}
+)
+
+// startErrorReporting подключает трекеры ошибок из конфигурации
+func startErrorReporting(config ErrorReportingConfig) error {
+	repeatedErrors.after = config.After
+	if dsn := config.dsn(); dsn != "" {
+		err := sentry.Init(sentry.ClientOptions{Dsn: dsn, Environment: config.Environment})
+		if err != nil {
+			return fmt.Errorf("sentry: %w", err)
+		}
+		reporters = append(reporters, sentryReporter{})
+	}
+	if config.WebhookURL != "" {
+		reporters = append(reporters, &webhookReporter{url: config.WebhookURL})
+	}
+	return nil
+}
+
+// stopErrorReporting дожидается отправки накопленных ошибок
+func stopErrorReporting() {
+	for _, r := range reporters {
+		r.flush(5 * time.Second)
+	}
+}
+
+// reportError передаёт ошибку во все трекеры. tags — контекст: endpoint, chat_id и т. п.
+func reportError(message string, err error, tags map[string]string) {
+	report := errorReport{Message: message, Error: err.Error(), Tags: tags, Time: time.Now()}
+	for _, r := range reporters {
+		r.report(report)
+	}
+}
+
+// recoverPanic перехватывает панику в обработчике, пишет её в журнал
+// и трекеры и даёт боту продолжить работу. Вызывается через defer.
+func recoverPanic(where string, tags map[string]string) {
+	p := recover()
+	if p == nil {
+		return
+	}
+	stack := string(debug.Stack())
+	slog.Error("Recovered from panic", "in", where, "panic", fmt.Sprint(p), "stack", stack)
+
+	all := map[string]string{"in": where}
+	for k, v := range tags {
+		all[k] = v
+	}
+	report := errorReport{Message: "panic in " + where, Error: fmt.Sprint(p), Panic: true, Stack: stack, Tags: all, Time: time.Now()}
+	for _, r := range reporters {
+		r.report(report)
+	}
+}
+
+// reportRepeated считает ошибки подряд по ключу и сообщает в трекеры, когда
+// их число достигает error_reporting.after; успех (err == nil) сбрасывает счёт.
+// Об одном затянувшемся сбое сообщается один раз.
+func reportRepeated(key, message string, err error, tags map[string]string) {
+	if n, ok := repeatedErrors.record(key, err); ok {
+		reportError(fmt.Sprintf("%s (%d times in a row)", message, n), err, tags)
+	}
+}
+
+// chatTags — контекст ошибки, связанной с чатом
+func chatTags(chatID int64) map[string]string {
+	return map[string]string{"chat_id": strconv.FormatInt(chatID, 10)}
+}
+
+// failureCounter — число ошибок подряд по ключу
+type failureCounter struct {
+	mutex  sync.Mutex
+	after  int
+	counts map[string]int
+}
+
+// record учитывает результат и сообщает, что ошибок подряд ровно after
+func (c *failureCounter) record(key string, err error) (int, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if err == nil {
+		delete(c.counts, key)
+		return 0, false
+	}
+	c.counts[key]++
+	return c.counts[key], c.after > 0 && c.counts[key] == c.after
+}
+
+// sentryReporter отправляет ошибки в Sentry через глобальный клиент sentry-go
+type sentryReporter struct{}
+
+func (sentryReporter) report(r errorReport) {
+	event := sentry.NewEvent()
+	event.Level = sentry.LevelError
+	if r.Panic {
+		event.Level = sentry.LevelFatal
+	}
+	event.Message = r.Message
+	event.Timestamp = r.Time
+	event.Tags = r.Tags
+	event.Exception = []sentry.Exception{{Type: r.Message, Value: r.Error, Stacktrace: sentry.NewStacktrace()}}
+	sentry.CaptureEvent(event)
+}
+
+func (sentryReporter) flush(timeout time.Duration) {
+	sentry.Flush(timeout)
+}
+
+// webhookReporter отправляет каждую ошибку POST-запросом с errorReport в JSON
+type webhookReporter struct {
+	url string
+	wg  sync.WaitGroup
+}
+
+func (w *webhookReporter) report(r errorReport) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		if err := w.post(r); err != nil {
+			// Не через reportError, чтобы недоступный трекер не порождал новые отчёты
+			slog.Warn("Error sending error report", "url", w.url, "error", err)
+		}
+	}()
+}
+
+func (w *webhookReporter) post(r errorReport) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := notifierClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		data, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+func (w *webhookReporter) flush(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// EscalationConfig — куда переслать инцидент, который никто не принял вовремя
+type EscalationConfig struct {
+	Chat  int64    `yaml:"chat" json:"chat"`   // Чат эскалации, например группа дежурных; 0 — эскалация выключена
+	After Duration `yaml:"after" json:"after"` // Через сколько после начала непринятый инцидент пересылается
+}
+
+func (c EscalationConfig) validate() error {
+	if c.Chat != 0 && c.After.Duration <= 0 {
+		return fmt.Errorf("escalation.after must be positive")
+	}
+	return nil
+}
+
+// escalateUnacked пересылает в чат эскалации критичные инциденты, которые не
+// приняли за escalation.after. Каждый инцидент пересылается один раз, без
+// учёта тихих часов и других настроек чата.
+func escalateUnacked() {
+	incidents, err := openIncidents()
+	if err != nil {
+		slog.Error("Error loading open incidents", "error", err)
+		return
+	}
+
+	now := time.Now()
+	chatID := cfg().Escalation.Chat
+	for _, incident := range incidents {
+		if !incident.critical() || incident.AckedBy != "" || !incident.Escalated.IsZero() || now.Sub(incident.Started) < cfg().Escalation.After.Duration {
+			continue
+		}
+		if _, _, err := updateIncident(incident.ID, func(i *Incident) bool {
+			i.Escalated = now
+			return true
+		}); err != nil {
+			slog.Error("Error saving incident", "incident_id", incident.ID, "error", err)
+			continue
+		}
+
+		slog.Info("Escalating incident", "incident_id", incident.ID, "chat_id", chatID)
+		lang := chatLang(chatID)
+		text := trMarkup(lang, "escalation.banner", escapeText(formatDuration(lang, now.Sub(incident.Started)))) + "\n" +
+			trMarkup(lang, "status.events", escapeText(incident.Endpoint),
+				trMarkup(lang, "change.down", bold(consoleName(lang, incident.Console)), escapeText(incident.Started.Local().Format("15:04")))+
+					trMarkup(lang, "incident.ref", incident.ID))
+		msg := newMarkupMessage(chatID, text)
+		if keyboard := ackKeyboard(lang, []Incident{incident}); keyboard != nil {
+			msg.ReplyMarkup = *keyboard
+		}
+		enqueueMessage(chatID, msg)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"status-bot/monitor"
+)
+
+func TestEscalateUnackedCriticalOnly(t *testing.T) {
+	fake := setupTestBot(t)
+	const escalationChat = 900
+	config := *cfg()
+	config.Escalation = EscalationConfig{Chat: escalationChat, After: Duration{15 * time.Minute}}
+	setConfig(config)
+
+	started := time.Now().Add(-time.Hour)
+	transitions := make(map[string]transition)
+	for _, console := range []string{"crit", "info", "filtered"} {
+		incident, err := startIncident("api", console, started)
+		if err != nil {
+			t.Fatal(err)
+		}
+		transitions[console] = transition{kind: changeDown, since: started, incident: incident}
+	}
+	// Правило понизило важность "info", а изменение "filtered" отброшено
+	changes := []monitor.StatusChange{{Name: "crit", Old: "Online", New: "Error"}, {Name: "info", Old: "Online", New: "Error"}}
+	recordIncidentSeverities(transitions, changes, map[string]string{"info": severityInfo})
+
+	startSender(cfg().Sender)
+	escalateUnacked()
+	stopSender()
+
+	sent := fake.sentTo(escalationChat)
+	if len(sent) != 1 {
+		t.Fatalf("escalated %d incidents, want 1: %v", len(sent), sent)
+	}
+	if !strings.Contains(sent[0].Text, "crit") {
+		t.Errorf("escalated %q, want the critical incident", sent[0].Text)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"status-bot/monitor"
+	"sync"
+	"time"
+)
+
+// FlappingConfig задаёт, когда консоль считается «мигающей»: не меньше
+// Transitions смен статуса за Window. Transitions = 0 отключает обнаружение.
+type FlappingConfig struct {
+	Transitions int      `yaml:"transitions" json:"transitions"` // Сколько смен статуса подряд считается миганием
+	Window      Duration `yaml:"window" json:"window"`           // За какой срок; столько же консоль должна простоять без смен, чтобы стабилизироваться
+}
+
+func (c FlappingConfig) validate() error {
+	if c.Transitions < 0 {
+		return fmt.Errorf("flapping.transitions must not be negative")
+	}
+	if c.Transitions > 0 && c.Window.Duration <= 0 {
+		return fmt.Errorf("flapping.window must be positive")
+	}
+	return nil
+}
+
+// flapState — недавние смены статуса одной консоли
+type flapState struct {
+	endpoint string
+	console  string
+	changes  []time.Time
+	flapping bool
+}
+
+var (
+	flapMutex = &sync.Mutex{}
+	flaps     = make(map[string]*flapState) // По endpoint + "\x00" + console
+)
+
+// detectFlapping учитывает смены статуса и убирает из уведомлений консоли,
+// которые мигают. Возвращает изменения для обычной рассылки и консоли, которые
+// только что начали мигать.
+func detectFlapping(endpoint string, changes []monitor.StatusChange, now time.Time) (notify, started []monitor.StatusChange) {
+	if cfg().Flapping.Transitions == 0 {
+		return changes, nil
+	}
+
+	flapMutex.Lock()
+	defer flapMutex.Unlock()
+
+	since := now.Add(-cfg().Flapping.Window.Duration)
+	for _, change := range changes {
+		key := endpoint + "\x00" + change.Name
+		state := flaps[key]
+		if state == nil {
+			state = &flapState{endpoint: endpoint, console: change.Name}
+			flaps[key] = state
+		}
+
+		recent := state.changes[:0]
+		for _, t := range state.changes {
+			if t.After(since) {
+				recent = append(recent, t)
+			}
+		}
+		state.changes = append(recent, now)
+
+		switch {
+		case state.flapping:
+			// Уже сообщили о мигании — молчим до стабилизации
+		case len(state.changes) >= cfg().Flapping.Transitions:
+			state.flapping = true
+			started = append(started, change)
+		default:
+			notify = append(notify, change)
+		}
+	}
+	return notify, started
+}
+
+// notifyFlappingStarted сообщает чатам, что консоли начали мигать
+func notifyFlappingStarted(endpoint Endpoint, started []monitor.StatusChange) {
+	for _, change := range started {
+		console := change.Name
+		broadcastConsoleEvent(endpoint.Name, console, false, func(lang string) (string, *tgbotapi.InlineKeyboardMarkup) {
+			return trMarkup(lang, "flap.started", bold(consoleName(lang, console)),
+				cfg().Flapping.Transitions, escapeText(formatDuration(lang, cfg().Flapping.Window.Duration))), nil
+		})
+	}
+}
+
+// resolveFlapping сообщает о консолях, которые простояли без смен статуса
+// целое окно flapping.window, и снимает с них отметку мигания
+func resolveFlapping() {
+	now := time.Now()
+	var resolved []*flapState
+
+	flapMutex.Lock()
+	for key, state := range flaps {
+		last := time.Time{}
+		if len(state.changes) > 0 {
+			last = state.changes[len(state.changes)-1]
+		}
+		if now.Sub(last) < cfg().Flapping.Window.Duration {
+			continue
+		}
+		if state.flapping {
+			resolved = append(resolved, state)
+		}
+		delete(flaps, key)
+	}
+	flapMutex.Unlock()
+
+	for _, state := range resolved {
+		status := ""
+		if console, ok := consoleStatus(state.endpoint, state.console); ok {
+			status = console.Status
+		}
+		console := state.console
+		broadcastConsoleEvent(state.endpoint, console, false, func(lang string) (string, *tgbotapi.InlineKeyboardMarkup) {
+			return trMarkup(lang, "flap.resolved", bold(consoleName(lang, console)), formatStatus(orDash(status))), nil
+		})
+	}
+}
+
+// broadcastConsoleEvent отправляет сообщение о консоли всем чатам, которые на
+// неё подписаны. render возвращает размеченный текст на языке чата и кнопки.
+func broadcastConsoleEvent(endpoint, console string, critical bool, render func(lang string) (string, *tgbotapi.InlineKeyboardMarkup)) {
+	chats, err := store.Chats()
+	if err != nil {
+		slog.Error("Error loading chats", "error", err)
+		return
+	}
+	for _, chat := range chats {
+		if len(chat.Consoles) > 0 && !containsString(chat.Consoles, console) {
+			continue
+		}
+		if consoleMuted(chat.Settings, console, time.Now()) {
+			continue
+		}
+		lang := settingsLang(chat.Settings)
+		text, keyboard := render(lang)
+		notifyChat(context.Background(), chat, trMarkup(lang, "status.events", escapeText(endpoint), text), critical, keyboard)
+	}
+}
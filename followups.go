@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"status-bot/monitor"
+	"strconv"
+	"sync"
+)
+
+// FollowUpsConfig настраивает продолжения уведомлений о недоступности
+type FollowUpsConfig struct {
+	Edit bool `yaml:"edit" json:"edit"` // Дописывать восстановление и смену статуса недоступной консоли в исходное уведомление вместо нового сообщения
+}
+
+// alertMessage — отправленное чату уведомление о недоступности, которое
+// дописывается, пока открыты его инциденты
+type alertMessage struct {
+	Text      string                         `json:"text"` // Текст с разметкой, как он был отправлен
+	Keyboard  *tgbotapi.InlineKeyboardMarkup `json:"keyboard,omitempty"`
+	Incidents []int                          `json:"incidents"` // Открытые инциденты из этого уведомления
+}
+
+// alertRef — уведомление, в котором сообщили об инциденте
+type alertRef struct {
+	ChatID    int64 `json:"chat_id"`
+	MessageID int   `json:"message_id"`
+}
+
+var alertsMutex sync.Mutex
+
+func alertKey(chatID int64, messageID int) string {
+	return fmt.Sprintf("alert:%d:%d", chatID, messageID)
+}
+
+func incidentAlertsKey(id int) string {
+	return "incident_alerts:" + strconv.Itoa(id)
+}
+
+// loadState разбирает JSON из состояния; false, если значения нет
+func loadState(key string, v interface{}) (bool, error) {
+	value, err := store.State(key)
+	if err != nil || value == "" {
+		return false, err
+	}
+	return true, json.Unmarshal([]byte(value), v)
+}
+
+func saveState(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return store.SetState(key, string(data))
+}
+
+// recordAlert запоминает доставленное уведомление об открытых инцидентах
+func recordAlert(chatID int64, sent tgbotapi.Message, msg tgbotapi.Chattable, incidents []int) {
+	m, ok := msg.(tgbotapi.MessageConfig)
+	if !ok || sent.MessageID == 0 {
+		return
+	}
+	alert := alertMessage{Text: m.Text, Incidents: incidents}
+	if keyboard, ok := m.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup); ok {
+		alert.Keyboard = &keyboard
+	}
+
+	alertsMutex.Lock()
+	defer alertsMutex.Unlock()
+	if err := saveState(alertKey(chatID, sent.MessageID), alert); err != nil {
+		slog.Error("Error saving alert message", "chat_id", chatID, "error", err)
+		return
+	}
+	for _, id := range incidents {
+		var refs []alertRef
+		if _, err := loadState(incidentAlertsKey(id), &refs); err != nil {
+			slog.Error("Error loading incident alerts", "incident_id", id, "error", err)
+		}
+		refs = append(refs, alertRef{ChatID: chatID, MessageID: sent.MessageID})
+		if err := saveState(incidentAlertsKey(id), refs); err != nil {
+			slog.Error("Error saving incident alerts", "incident_id", id, "error", err)
+		}
+	}
+}
+
+// editFollowUps дописывает в уведомления о недоступности восстановление и
+// смену статуса ещё недоступных консолей. Возвращает изменения, для которых
+// в чате нет такого уведомления: о них сообщается как обычно.
+func editFollowUps(chat Chat, lang string, event StatusEvent, changes []monitor.StatusChange) []monitor.StatusChange {
+	if !cfg().FollowUps.Edit || len(changes) == 0 {
+		return changes
+	}
+
+	var open []Incident
+	rest := changes[:0:0]
+	for _, change := range changes {
+		t := event.Transitions[change.Name]
+		var id int
+		var line string
+		switch {
+		case t.kind == changeRecovered && t.incident.ID != 0:
+			id = t.incident.ID
+			line = trMarkup(lang, "followup.recovered", bold(consoleName(lang, change.Name)), escapeText(formatDuration(lang, t.downtime)))
+		case isDown(change.Old) && isDown(change.New):
+			if open == nil {
+				var err error
+				if open, err = openIncidents(); err != nil {
+					slog.Error("Error loading open incidents", "error", err)
+				}
+			}
+			for _, incident := range open {
+				if incident.Endpoint == event.Endpoint.Name && incident.Console == change.Name {
+					id = incident.ID
+				}
+			}
+			line = trMarkup(lang, "followup.changed", bold(consoleName(lang, change.Name)), formatStatus(change.Old), formatStatus(change.New), escapeText(event.Time.Local().Format("15:04")))
+		}
+		if id == 0 || !appendToAlert(chat.ID, id, line, t.kind == changeRecovered) {
+			rest = append(rest, change)
+		}
+	}
+	return rest
+}
+
+// appendToAlert дописывает строку в уведомление чата об инциденте; resolved
+// убирает кнопку инцидента и забывает его. false, если уведомления нет или
+// отредактировать его не удалось.
+func appendToAlert(chatID int64, incidentID int, line string, resolved bool) bool {
+	alertsMutex.Lock()
+	defer alertsMutex.Unlock()
+
+	var refs []alertRef
+	if _, err := loadState(incidentAlertsKey(incidentID), &refs); err != nil {
+		slog.Error("Error loading incident alerts", "incident_id", incidentID, "error", err)
+		return false
+	}
+	edited := false
+	kept := refs[:0]
+	for _, ref := range refs {
+		if ref.ChatID != chatID {
+			kept = append(kept, ref)
+			continue
+		}
+		key := alertKey(ref.ChatID, ref.MessageID)
+		var alert alertMessage
+		if ok, err := loadState(key, &alert); !ok || err != nil {
+			if err != nil {
+				slog.Error("Error loading alert message", "chat_id", chatID, "error", err)
+			}
+			continue
+		}
+
+		alert.Text += "\n" + line
+		if resolved {
+			alert.Keyboard = withoutButton(alert.Keyboard, callbackAck+":"+strconv.Itoa(incidentID))
+			alert.Incidents = removeInt(alert.Incidents, incidentID)
+		}
+		edit := tgbotapi.NewEditMessageText(ref.ChatID, ref.MessageID, alert.Text)
+		edit.ParseMode = telegramParseMode()
+		edit.ReplyMarkup = alert.Keyboard
+		if err := editMessage(context.Background(), ref.ChatID, edit); err != nil && !isNotModified(err) {
+			slog.Warn("Error editing alert message", "chat_id", ref.ChatID, "message_id", ref.MessageID, "error", err)
+			store.SetState(key, "")
+			continue
+		}
+		edited = true
+
+		if !resolved {
+			kept = append(kept, ref)
+		}
+		if len(alert.Incidents) == 0 {
+			store.SetState(key, "")
+		} else if err := saveState(key, alert); err != nil {
+			slog.Error("Error saving alert message", "chat_id", ref.ChatID, "error", err)
+		}
+	}
+
+	if len(kept) == 0 {
+		store.SetState(incidentAlertsKey(incidentID), "")
+	} else if err := saveState(incidentAlertsKey(incidentID), kept); err != nil {
+		slog.Error("Error saving incident alerts", "incident_id", incidentID, "error", err)
+	}
+	return edited
+}
+
+// forgetIncidentAlerts убирает закрытый инцидент из уведомлений чатов, которые
+// не получили его восстановление (например, отписались от консоли)
+func forgetIncidentAlerts(incidentID int) {
+	alertsMutex.Lock()
+	defer alertsMutex.Unlock()
+
+	var refs []alertRef
+	if ok, err := loadState(incidentAlertsKey(incidentID), &refs); !ok || err != nil {
+		return
+	}
+	for _, ref := range refs {
+		key := alertKey(ref.ChatID, ref.MessageID)
+		var alert alertMessage
+		if ok, err := loadState(key, &alert); !ok || err != nil {
+			continue
+		}
+		if alert.Incidents = removeInt(alert.Incidents, incidentID); len(alert.Incidents) == 0 {
+			store.SetState(key, "")
+		} else {
+			saveState(key, alert)
+		}
+	}
+	store.SetState(incidentAlertsKey(incidentID), "")
+}
+
+// noteAlertEdited обновляет запомненное уведомление после правки вне
+// editFollowUps (например, отметки о принятии), чтобы следующая правка её не стёрла
+func noteAlertEdited(chatID int64, messageID int, note string, keyboard *tgbotapi.InlineKeyboardMarkup) {
+	alertsMutex.Lock()
+	defer alertsMutex.Unlock()
+
+	key := alertKey(chatID, messageID)
+	var alert alertMessage
+	if ok, err := loadState(key, &alert); !ok || err != nil {
+		return
+	}
+	alert.Text += "\n\n" + escapeText(note)
+	alert.Keyboard = keyboard
+	if err := saveState(key, alert); err != nil {
+		slog.Error("Error saving alert message", "chat_id", chatID, "error", err)
+	}
+}
+
+// withoutButton возвращает клавиатуру без кнопки с данными data; nil, если кнопок не осталось
+func withoutButton(keyboard *tgbotapi.InlineKeyboardMarkup, data string) *tgbotapi.InlineKeyboardMarkup {
+	if keyboard == nil {
+		return nil
+	}
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, row := range keyboard.InlineKeyboard {
+		var kept []tgbotapi.InlineKeyboardButton
+		for _, button := range row {
+			if button.CallbackData == nil || *button.CallbackData != data {
+				kept = append(kept, button)
+			}
+		}
+		if len(kept) > 0 {
+			rows = append(rows, kept)
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	result := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &result
+}
+
+func removeInt(list []int, value int) []int {
+	kept := list[:0]
+	for _, v := range list {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+func incidentIDs(incidents []Incident) []int {
+	ids := make([]int, 0, len(incidents))
+	for _, incident := range incidents {
+		ids = append(ids, incident.ID)
+	}
+	return ids
+}
+
+// closedIncidents — инциденты, закрытые изменениями события
+func closedIncidents(event StatusEvent) []int {
+	var ids []int
+	for _, change := range event.Changes {
+		if t := event.Transitions[change.Name]; t.kind == changeRecovered && t.incident.ID != 0 {
+			ids = append(ids, t.incident.ID)
+		}
+	}
+	return ids
+}
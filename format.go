@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"status-bot/monitor"
+	"strings"
+)
+
+// isDown сообщает, считается ли статус консоли недоступностью
+func isDown(status string) bool {
+	for _, down := range cfg().DownStatuses {
+		if strings.EqualFold(status, down) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatChanges выводит изменения по строке на консоль в виде «имя: старый → новый».
+// Результат размечен для newMarkupMessage.
+func formatChanges(lang string, changes []monitor.StatusChange) string {
+	var b strings.Builder
+	for i, change := range changes {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s: %s → %s", bold(consoleName(lang, change.Name)), formatStatus(orDash(change.Old)), formatStatus(orDash(change.New)))
+	}
+	return b.String()
+}
+
+// consoleName подставляет псевдоним консоли из aliases и заглушку для
+// консолей без имени
+func consoleName(lang, name string) string {
+	if alias := strings.TrimSpace(cfg().Aliases[name]); alias != "" {
+		return alias
+	}
+	if name == "" {
+		return tr(lang, "console.unnamed")
+	}
+	return name
+}
+
+// orDash подставляет прочерк вместо отсутствующего статуса
+func orDash(status string) string {
+	if status == "" {
+		return "—"
+	}
+	return status
+}
+
+// formatStatuses превращает ответ API в сообщение вида «имя: статус» по
+// строке на консоль. Если ответ не удаётся разобрать, возвращается как есть.
+// Результат размечен для newMarkupMessage.
+func formatStatuses(lang, status string) string {
+	consoles, err := monitor.ParseStatuses(status)
+	if err != nil {
+		return escapeText(status)
+	}
+	return formatConsoles(lang, consoles)
+}
+
+// formatConsoles выводит статусы по строке на консоль в виде «имя: статус».
+// Результат размечен для newMarkupMessage.
+func formatConsoles(lang string, consoles []monitor.ConsoleStatus) string {
+	if len(consoles) == 0 {
+		return trMarkup(lang, "status.no_data")
+	}
+
+	var b strings.Builder
+	for i, console := range consoles {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s: %s", bold(consoleName(lang, console.Name)), formatStatus(console.Status))
+	}
+	return b.String()
+}
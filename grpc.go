@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"log/slog"
+	"net"
+	"net/http"
+	"status-bot/grpcapi"
+	"sync"
+	"time"
+)
+
+// GRPCConfig — gRPC-сервер с тем же, что у REST API, управлением и потоком
+// изменений статуса. Доступ по токену API (api.token) в метаданных authorization.
+type GRPCConfig struct {
+	Listen   string `yaml:"listen" json:"listen"`       // Адрес, например ":9443"; пусто — сервер выключен
+	CertFile string `yaml:"cert_file" json:"cert_file"` // Сертификат для TLS; без него сервер работает без шифрования
+	KeyFile  string `yaml:"key_file" json:"key_file"`   // Закрытый ключ сертификата
+}
+
+func (c GRPCConfig) validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("grpc.cert_file and grpc.key_file must be set together")
+	}
+	return nil
+}
+
+// watcherBuffer — сколько событий ждут медленного клиента потока, прежде чем
+// новые события для него начнут отбрасываться
+const watcherBuffer = 100
+
+// statusWatcher — клиент WatchStatusChanges со своим фильтром
+type statusWatcher struct {
+	endpoint string
+	consoles []string
+	events   chan StatusEvent
+}
+
+var (
+	watchersMutex = &sync.Mutex{}
+	watchers      = make(map[*statusWatcher]struct{})
+)
+
+// grpcNotifier передаёт события открытым потокам WatchStatusChanges
+type grpcNotifier struct{}
+
+func (grpcNotifier) Name() string {
+	return "grpc"
+}
+
+func (grpcNotifier) Notify(ctx context.Context, event StatusEvent) error {
+	watchersMutex.Lock()
+	defer watchersMutex.Unlock()
+	for w := range watchers {
+		if w.endpoint != "" && w.endpoint != event.Endpoint.Name {
+			continue
+		}
+		select {
+		case w.events <- event:
+		default:
+			slog.Warn("Dropping status event: gRPC watcher is too slow", "endpoint", event.Endpoint.Name)
+		}
+	}
+	return nil
+}
+
+// startGRPCServer запускает gRPC-сервер; nil, если он не настроен
+func startGRPCServer(config GRPCConfig) (*grpc.Server, error) {
+	if config.Listen == "" {
+		return nil, nil
+	}
+	if cfg().API.token() == "" {
+		return nil, fmt.Errorf("gRPC server requires api token, expected api.token or %s", cfg().API.TokenEnv)
+	}
+
+	options := []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if err := authorizeGRPC(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := authorizeGRPC(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	}
+	if config.CertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+
+	listener, err := net.Listen("tcp", config.Listen)
+	if err != nil {
+		return nil, err
+	}
+	srv := grpc.NewServer(options...)
+	grpcapi.RegisterStatusBotServer(srv, grpcServer{})
+	go func() {
+		slog.Info("Serving gRPC API", "addr", config.Listen)
+		if err := srv.Serve(listener); err != nil {
+			fatal("gRPC server stopped", "error", err)
+		}
+	}()
+	return srv, nil
+}
+
+// stopGRPCServer дожидается текущих вызовов до отмены ctx, затем закрывает
+// оставшиеся соединения, в том числе потоки WatchStatusChanges
+func stopGRPCServer(ctx context.Context, srv *grpc.Server) {
+	if srv == nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		srv.GracefulStop()
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		srv.Stop()
+		<-done
+	}
+}
+
+func authorizeGRPC(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, authorization := range md.Get("authorization") {
+		if authorizeAPI(authorization) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "unauthorized")
+}
+
+// grpcError переводит ошибки общей части API в коды gRPC
+func grpcError(err error) error {
+	var e *apiError
+	if !errors.As(err, &e) {
+		slog.Error("gRPC request failed", "error", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+	switch e.status {
+	case http.StatusNotFound:
+		return status.Error(codes.NotFound, e.message)
+	default:
+		return status.Error(codes.InvalidArgument, e.message)
+	}
+}
+
+// grpcServer реализует grpcapi.StatusBotServer поверх тех же функций, что и REST API
+type grpcServer struct {
+	grpcapi.UnimplementedStatusBotServer
+}
+
+func grpcTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func grpcChat(chat Chat) *grpcapi.Chat {
+	return &grpcapi.Chat{Id: chat.ID, SubscribedAt: grpcTime(chat.SubscribedAt), Consoles: chat.Consoles}
+}
+
+func grpcMaintenance(w maintenanceWindow) *grpcapi.MaintenanceWindow {
+	return &grpcapi.MaintenanceWindow{Id: int32(w.ID), Day: w.Day, Hours: w.Hours, Consoles: w.Consoles, Silent: w.Silent}
+}
+
+func (grpcServer) ListChats(ctx context.Context, req *grpcapi.ListChatsRequest) (*grpcapi.ListChatsResponse, error) {
+	chats, err := store.Chats()
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &grpcapi.ListChatsResponse{}
+	for _, chat := range chats {
+		resp.Chats = append(resp.Chats, grpcChat(chat))
+	}
+	return resp, nil
+}
+
+func (grpcServer) AddChat(ctx context.Context, req *grpcapi.AddChatRequest) (*grpcapi.Chat, error) {
+	chat, err := apiAddChat(req.GetChatId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return grpcChat(chat), nil
+}
+
+func (grpcServer) RemoveChat(ctx context.Context, req *grpcapi.RemoveChatRequest) (*grpcapi.RemoveChatResponse, error) {
+	if err := apiRemoveChat(req.GetChatId()); err != nil {
+		return nil, grpcError(err)
+	}
+	return &grpcapi.RemoveChatResponse{}, nil
+}
+
+func (grpcServer) Check(ctx context.Context, req *grpcapi.CheckRequest) (*grpcapi.CheckResponse, error) {
+	results, err := apiCheck(ctx, req.GetEndpoint())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &grpcapi.CheckResponse{}
+	for _, r := range results {
+		resp.Results = append(resp.Results, &grpcapi.CheckResult{Endpoint: r.Endpoint, Ok: r.OK, Error: r.Error})
+	}
+	return resp, nil
+}
+
+func (grpcServer) GetStatus(ctx context.Context, req *grpcapi.GetStatusRequest) (*grpcapi.GetStatusResponse, error) {
+	resp := &grpcapi.GetStatusResponse{}
+	for _, endpoint := range endpointStatuses() {
+		item := &grpcapi.EndpointStatus{Name: endpoint.Name, Ok: endpoint.OK, LastCheckAt: grpcTime(endpoint.LastCheckAt), LastError: endpoint.LastError}
+		for _, console := range endpoint.Consoles {
+			item.Consoles = append(item.Consoles, &grpcapi.ConsoleStatus{Name: console.Name, Status: console.Status})
+		}
+		resp.Endpoints = append(resp.Endpoints, item)
+	}
+	return resp, nil
+}
+
+func (grpcServer) GetHistory(ctx context.Context, req *grpcapi.GetHistoryRequest) (*grpcapi.GetHistoryResponse, error) {
+	var since time.Time
+	if req.GetSince() != nil {
+		since = req.GetSince().AsTime()
+	}
+	entries, err := apiHistory(req.GetEndpoint(), req.GetConsole(), since)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &grpcapi.GetHistoryResponse{}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, &grpcapi.HistoryEntry{Endpoint: entry.Endpoint, Console: entry.Console, Old: entry.Old, New: entry.New, At: grpcTime(entry.At)})
+	}
+	return resp, nil
+}
+
+func (grpcServer) ListMaintenance(ctx context.Context, req *grpcapi.ListMaintenanceRequest) (*grpcapi.ListMaintenanceResponse, error) {
+	windows, err := loadMaintenance()
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &grpcapi.ListMaintenanceResponse{}
+	for _, w := range windows {
+		resp.Windows = append(resp.Windows, grpcMaintenance(w))
+	}
+	return resp, nil
+}
+
+func (grpcServer) AddMaintenance(ctx context.Context, req *grpcapi.AddMaintenanceRequest) (*grpcapi.MaintenanceWindow, error) {
+	w, err := apiAddMaintenance(req.GetWhen(), time.Duration(req.GetDurationSeconds())*time.Second, req.GetConsoles(), req.GetSilent())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return grpcMaintenance(w), nil
+}
+
+func (grpcServer) RemoveMaintenance(ctx context.Context, req *grpcapi.RemoveMaintenanceRequest) (*grpcapi.RemoveMaintenanceResponse, error) {
+	if err := apiRemoveMaintenance(int(req.GetId())); err != nil {
+		return nil, grpcError(err)
+	}
+	return &grpcapi.RemoveMaintenanceResponse{}, nil
+}
+
+// WatchStatusChanges передаёт клиенту события до его отключения или остановки сервера
+func (grpcServer) WatchStatusChanges(req *grpcapi.WatchStatusChangesRequest, stream grpc.ServerStreamingServer[grpcapi.StatusEvent]) error {
+	if req.GetEndpoint() != "" {
+		if _, err := apiEndpoints(req.GetEndpoint()); err != nil {
+			return grpcError(err)
+		}
+	}
+	w := &statusWatcher{endpoint: req.GetEndpoint(), consoles: req.GetConsoles(), events: make(chan StatusEvent, watcherBuffer)}
+	watchersMutex.Lock()
+	watchers[w] = struct{}{}
+	watchersMutex.Unlock()
+	defer func() {
+		watchersMutex.Lock()
+		delete(watchers, w)
+		watchersMutex.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event := <-w.events:
+			message, ok := w.message(event)
+			if !ok {
+				continue
+			}
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// message переводит событие в сообщение потока, оставляя только консоли из
+// фильтра; false — в событии нет ни одной из них
+func (w *statusWatcher) message(event StatusEvent) (*grpcapi.StatusEvent, bool) {
+	message := &grpcapi.StatusEvent{Endpoint: event.Endpoint.Name, Time: grpcTime(event.Time), Status: event.Status, Test: event.Test}
+	for _, change := range event.Changes {
+		if len(w.consoles) > 0 && !containsString(w.consoles, change.Name) {
+			continue
+		}
+		message.Changes = append(message.Changes, &grpcapi.StatusChange{Console: change.Name, Old: change.Old, New: change.New, Severity: event.Severities[change.Name]})
+	}
+	if len(w.consoles) > 0 && len(message.Changes) == 0 {
+		return nil, false
+	}
+	return message, true
+}
@@ -0,0 +1,486 @@
+// API управления ботом статусов для внутренних сервисов: то же, что REST API
+// на /api/, и поток изменений статуса консолей, которые получают чаты Telegram.
+//
+// Код Go генерируется командой (из каталога grpcapi):
+//   protoc --go_out=. --go_opt=paths=source_relative \
+//     --go-grpc_out=. --go-grpc_opt=paths=source_relative statusbot.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: statusbot.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	StatusBot_ListChats_FullMethodName          = "/statusbot.v1.StatusBot/ListChats"
+	StatusBot_AddChat_FullMethodName            = "/statusbot.v1.StatusBot/AddChat"
+	StatusBot_RemoveChat_FullMethodName         = "/statusbot.v1.StatusBot/RemoveChat"
+	StatusBot_Check_FullMethodName              = "/statusbot.v1.StatusBot/Check"
+	StatusBot_GetStatus_FullMethodName          = "/statusbot.v1.StatusBot/GetStatus"
+	StatusBot_GetHistory_FullMethodName         = "/statusbot.v1.StatusBot/GetHistory"
+	StatusBot_ListMaintenance_FullMethodName    = "/statusbot.v1.StatusBot/ListMaintenance"
+	StatusBot_AddMaintenance_FullMethodName     = "/statusbot.v1.StatusBot/AddMaintenance"
+	StatusBot_RemoveMaintenance_FullMethodName  = "/statusbot.v1.StatusBot/RemoveMaintenance"
+	StatusBot_WatchStatusChanges_FullMethodName = "/statusbot.v1.StatusBot/WatchStatusChanges"
+)
+
+// StatusBotClient is the client API for StatusBot service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type StatusBotClient interface {
+	// Подписанные чаты
+	ListChats(ctx context.Context, in *ListChatsRequest, opts ...grpc.CallOption) (*ListChatsResponse, error)
+	AddChat(ctx context.Context, in *AddChatRequest, opts ...grpc.CallOption) (*Chat, error)
+	RemoveChat(ctx context.Context, in *RemoveChatRequest, opts ...grpc.CallOption) (*RemoveChatResponse, error)
+	// Немедленная проверка, как /check
+	Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error)
+	// Последние статусы консолей каждого API
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error)
+	// Смены статуса консоли
+	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error)
+	// Окна обслуживания
+	ListMaintenance(ctx context.Context, in *ListMaintenanceRequest, opts ...grpc.CallOption) (*ListMaintenanceResponse, error)
+	AddMaintenance(ctx context.Context, in *AddMaintenanceRequest, opts ...grpc.CallOption) (*MaintenanceWindow, error)
+	RemoveMaintenance(ctx context.Context, in *RemoveMaintenanceRequest, opts ...grpc.CallOption) (*RemoveMaintenanceResponse, error)
+	// Поток событий изменения статуса — тех же, что уходят в чаты Telegram
+	WatchStatusChanges(ctx context.Context, in *WatchStatusChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StatusEvent], error)
+}
+
+type statusBotClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStatusBotClient(cc grpc.ClientConnInterface) StatusBotClient {
+	return &statusBotClient{cc}
+}
+
+func (c *statusBotClient) ListChats(ctx context.Context, in *ListChatsRequest, opts ...grpc.CallOption) (*ListChatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListChatsResponse)
+	err := c.cc.Invoke(ctx, StatusBot_ListChats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusBotClient) AddChat(ctx context.Context, in *AddChatRequest, opts ...grpc.CallOption) (*Chat, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Chat)
+	err := c.cc.Invoke(ctx, StatusBot_AddChat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusBotClient) RemoveChat(ctx context.Context, in *RemoveChatRequest, opts ...grpc.CallOption) (*RemoveChatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveChatResponse)
+	err := c.cc.Invoke(ctx, StatusBot_RemoveChat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusBotClient) Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckResponse)
+	err := c.cc.Invoke(ctx, StatusBot_Check_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusBotClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatusResponse)
+	err := c.cc.Invoke(ctx, StatusBot_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusBotClient) GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetHistoryResponse)
+	err := c.cc.Invoke(ctx, StatusBot_GetHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusBotClient) ListMaintenance(ctx context.Context, in *ListMaintenanceRequest, opts ...grpc.CallOption) (*ListMaintenanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMaintenanceResponse)
+	err := c.cc.Invoke(ctx, StatusBot_ListMaintenance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusBotClient) AddMaintenance(ctx context.Context, in *AddMaintenanceRequest, opts ...grpc.CallOption) (*MaintenanceWindow, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MaintenanceWindow)
+	err := c.cc.Invoke(ctx, StatusBot_AddMaintenance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusBotClient) RemoveMaintenance(ctx context.Context, in *RemoveMaintenanceRequest, opts ...grpc.CallOption) (*RemoveMaintenanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveMaintenanceResponse)
+	err := c.cc.Invoke(ctx, StatusBot_RemoveMaintenance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusBotClient) WatchStatusChanges(ctx context.Context, in *WatchStatusChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StatusEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StatusBot_ServiceDesc.Streams[0], StatusBot_WatchStatusChanges_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchStatusChangesRequest, StatusEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StatusBot_WatchStatusChangesClient = grpc.ServerStreamingClient[StatusEvent]
+
+// StatusBotServer is the server API for StatusBot service.
+// All implementations must embed UnimplementedStatusBotServer
+// for forward compatibility.
+type StatusBotServer interface {
+	// Подписанные чаты
+	ListChats(context.Context, *ListChatsRequest) (*ListChatsResponse, error)
+	AddChat(context.Context, *AddChatRequest) (*Chat, error)
+	RemoveChat(context.Context, *RemoveChatRequest) (*RemoveChatResponse, error)
+	// Немедленная проверка, как /check
+	Check(context.Context, *CheckRequest) (*CheckResponse, error)
+	// Последние статусы консолей каждого API
+	GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error)
+	// Смены статуса консоли
+	GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error)
+	// Окна обслуживания
+	ListMaintenance(context.Context, *ListMaintenanceRequest) (*ListMaintenanceResponse, error)
+	AddMaintenance(context.Context, *AddMaintenanceRequest) (*MaintenanceWindow, error)
+	RemoveMaintenance(context.Context, *RemoveMaintenanceRequest) (*RemoveMaintenanceResponse, error)
+	// Поток событий изменения статуса — тех же, что уходят в чаты Telegram
+	WatchStatusChanges(*WatchStatusChangesRequest, grpc.ServerStreamingServer[StatusEvent]) error
+	mustEmbedUnimplementedStatusBotServer()
+}
+
+// UnimplementedStatusBotServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedStatusBotServer struct{}
+
+func (UnimplementedStatusBotServer) ListChats(context.Context, *ListChatsRequest) (*ListChatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListChats not implemented")
+}
+func (UnimplementedStatusBotServer) AddChat(context.Context, *AddChatRequest) (*Chat, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddChat not implemented")
+}
+func (UnimplementedStatusBotServer) RemoveChat(context.Context, *RemoveChatRequest) (*RemoveChatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveChat not implemented")
+}
+func (UnimplementedStatusBotServer) Check(context.Context, *CheckRequest) (*CheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Check not implemented")
+}
+func (UnimplementedStatusBotServer) GetStatus(context.Context, *GetStatusRequest) (*GetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedStatusBotServer) GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHistory not implemented")
+}
+func (UnimplementedStatusBotServer) ListMaintenance(context.Context, *ListMaintenanceRequest) (*ListMaintenanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMaintenance not implemented")
+}
+func (UnimplementedStatusBotServer) AddMaintenance(context.Context, *AddMaintenanceRequest) (*MaintenanceWindow, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMaintenance not implemented")
+}
+func (UnimplementedStatusBotServer) RemoveMaintenance(context.Context, *RemoveMaintenanceRequest) (*RemoveMaintenanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveMaintenance not implemented")
+}
+func (UnimplementedStatusBotServer) WatchStatusChanges(*WatchStatusChangesRequest, grpc.ServerStreamingServer[StatusEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchStatusChanges not implemented")
+}
+func (UnimplementedStatusBotServer) mustEmbedUnimplementedStatusBotServer() {}
+func (UnimplementedStatusBotServer) testEmbeddedByValue()                   {}
+
+// UnsafeStatusBotServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StatusBotServer will
+// result in compilation errors.
+type UnsafeStatusBotServer interface {
+	mustEmbedUnimplementedStatusBotServer()
+}
+
+func RegisterStatusBotServer(s grpc.ServiceRegistrar, srv StatusBotServer) {
+	// If the following call pancis, it indicates UnimplementedStatusBotServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&StatusBot_ServiceDesc, srv)
+}
+
+func _StatusBot_ListChats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListChatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusBotServer).ListChats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatusBot_ListChats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusBotServer).ListChats(ctx, req.(*ListChatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatusBot_AddChat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddChatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusBotServer).AddChat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatusBot_AddChat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusBotServer).AddChat(ctx, req.(*AddChatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatusBot_RemoveChat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveChatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusBotServer).RemoveChat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatusBot_RemoveChat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusBotServer).RemoveChat(ctx, req.(*RemoveChatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatusBot_Check_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusBotServer).Check(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatusBot_Check_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusBotServer).Check(ctx, req.(*CheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatusBot_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusBotServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatusBot_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusBotServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatusBot_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusBotServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatusBot_GetHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusBotServer).GetHistory(ctx, req.(*GetHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatusBot_ListMaintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMaintenanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusBotServer).ListMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatusBot_ListMaintenance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusBotServer).ListMaintenance(ctx, req.(*ListMaintenanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatusBot_AddMaintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddMaintenanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusBotServer).AddMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatusBot_AddMaintenance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusBotServer).AddMaintenance(ctx, req.(*AddMaintenanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatusBot_RemoveMaintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveMaintenanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusBotServer).RemoveMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StatusBot_RemoveMaintenance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusBotServer).RemoveMaintenance(ctx, req.(*RemoveMaintenanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StatusBot_WatchStatusChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchStatusChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StatusBotServer).WatchStatusChanges(m, &grpc.GenericServerStream[WatchStatusChangesRequest, StatusEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StatusBot_WatchStatusChangesServer = grpc.ServerStreamingServer[StatusEvent]
+
+// StatusBot_ServiceDesc is the grpc.ServiceDesc for StatusBot service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var StatusBot_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "statusbot.v1.StatusBot",
+	HandlerType: (*StatusBotServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListChats",
+			Handler:    _StatusBot_ListChats_Handler,
+		},
+		{
+			MethodName: "AddChat",
+			Handler:    _StatusBot_AddChat_Handler,
+		},
+		{
+			MethodName: "RemoveChat",
+			Handler:    _StatusBot_RemoveChat_Handler,
+		},
+		{
+			MethodName: "Check",
+			Handler:    _StatusBot_Check_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _StatusBot_GetStatus_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _StatusBot_GetHistory_Handler,
+		},
+		{
+			MethodName: "ListMaintenance",
+			Handler:    _StatusBot_ListMaintenance_Handler,
+		},
+		{
+			MethodName: "AddMaintenance",
+			Handler:    _StatusBot_AddMaintenance_Handler,
+		},
+		{
+			MethodName: "RemoveMaintenance",
+			Handler:    _StatusBot_RemoveMaintenance_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchStatusChanges",
+			Handler:       _StatusBot_WatchStatusChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "statusbot.proto",
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// endpointHealth — результаты последних проверок одного API
+type endpointHealth struct {
+	Results       []bool    `json:"-"`               // Последние результаты, новые в конце
+	LastCheckAt   time.Time `json:"last_check_at"`   // Время завершения последней проверки
+	LastSuccessAt time.Time `json:"last_success_at"` // Время последней успешной проверки
+	LastError     string    `json:"last_error,omitempty"`
+}
+
+var (
+	healthMutex = &sync.Mutex{}
+	health      = make(map[string]*endpointHealth) // Состояние проверок по имени API
+)
+
+func init() {
+	httpMux.HandleFunc("/healthz", handleHealthz)
+	httpMux.HandleFunc("/readyz", handleReadyz)
+}
+
+// recordCheck запоминает результат проверки API, храня не больше cfg().HealthChecks результатов
+func recordCheck(endpoint string, err error) {
+	healthMutex.Lock()
+	defer healthMutex.Unlock()
+
+	h := health[endpoint]
+	if h == nil {
+		h = &endpointHealth{}
+		health[endpoint] = h
+	}
+	h.Results = append(h.Results, err == nil)
+	if len(h.Results) > cfg().HealthChecks {
+		h.Results = h.Results[len(h.Results)-cfg().HealthChecks:]
+	}
+	h.LastCheckAt = time.Now()
+	h.LastError = ""
+	if err != nil {
+		h.LastError = err.Error()
+	} else {
+		h.LastSuccessAt = h.LastCheckAt
+	}
+}
+
+// healthReport — тело ответа /healthz и /readyz
+type healthReport struct {
+	OK         bool                      `json:"ok"`
+	Authorized bool                      `json:"telegram_authorized"`
+	Endpoints  map[string]endpointReport `json:"endpoints"`
+}
+
+type endpointReport struct {
+	OK          bool      `json:"ok"`
+	Recent      int       `json:"recent_checks"`
+	Failed      int       `json:"recent_failures"`
+	LastCheckAt time.Time `json:"last_check_at,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// buildHealthReport проверяет авторизацию в Telegram и проверки каждого API.
+// Для готовности (ready) нужны N успешных проверок подряд; для живости —
+// чтобы проверки вообще выполнялись, а не зависли.
+func buildHealthReport(ready bool) healthReport {
+	report := healthReport{
+		Authorized: botUser.ID != 0,
+		Endpoints:  make(map[string]endpointReport),
+	}
+	report.OK = report.Authorized
+
+	healthMutex.Lock()
+	defer healthMutex.Unlock()
+
+	for _, endpoint := range cfg().endpoints() {
+		h := health[endpoint.Name]
+		r := endpointReport{}
+		if h != nil {
+			r.Recent = len(h.Results)
+			for _, ok := range h.Results {
+				if !ok {
+					r.Failed++
+				}
+			}
+			r.LastCheckAt = h.LastCheckAt
+			r.LastError = h.LastError
+		}
+
+		if ready {
+			r.OK = r.Recent >= cfg().HealthChecks && r.Failed == 0
+		} else {
+			// Проверка «зависла», если давно не завершалась ни одна попытка
+			stale := 3*endpoint.PollInterval.Duration + time.Minute
+			last := startTime
+			if h != nil {
+				last = h.LastCheckAt
+			}
+			r.OK = time.Since(last) < stale
+		}
+		if !r.OK {
+			report.OK = false
+		}
+		report.Endpoints[endpoint.Name] = r
+	}
+	return report
+}
+
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, buildHealthReport(false))
+}
+
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, buildHealthReport(true))
+}
+
+func writeHealthReport(w http.ResponseWriter, report healthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	if !report.OK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
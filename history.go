@@ -0,0 +1,78 @@
+package main
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHistoryLimit = 10 // Сколько смен статуса показывает /history по умолчанию
+	maxHistoryLimit     = 50
+)
+
+// handleHistory обрабатывает /history <консоль> [N] — последние N смен статуса
+// консоли со временем, которое консоль провела в каждом статусе
+func handleHistory(chatID int64, args string) {
+	fields := consoleFields(args)
+	if len(fields) == 0 || len(fields) > 2 {
+		reply(chatID, "history.usage")
+		return
+	}
+
+	limit := defaultHistoryLimit
+	if len(fields) == 2 {
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n <= 0 || n > maxHistoryLimit {
+			reply(chatID, "history.usage")
+			return
+		}
+		limit = n
+	}
+
+	lang := chatLang(chatID)
+	now := time.Now()
+	var sections []string
+	for _, endpoint := range cfg().endpoints() {
+		console, ok := consoleStatus(endpoint.Name, fields[0])
+		if !ok {
+			continue
+		}
+		entries, err := store.History(endpoint.Name, console.Name, now.Add(-cfg().History.Duration))
+		if err != nil {
+			slog.Error("Error loading history", "endpoint", endpoint.Name, "console", console.Name, "error", err)
+			reply(chatID, "error.load")
+			return
+		}
+		sections = append(sections, tr(lang, "history.endpoint", endpoint.Name, formatHistory(lang, entries, limit, now)))
+	}
+
+	if len(sections) == 0 {
+		reply(chatID, "uptime.unknown", consoleName(lang, fields[0]))
+		return
+	}
+	reply(chatID, "history.header", consoleName(lang, fields[0]), strings.Join(sections, "\n\n"))
+}
+
+// formatHistory выводит последние limit смен статуса, новые сверху. Длительность
+// статуса — время до следующей смены; для последней — до текущего момента.
+func formatHistory(lang string, entries []HistoryEntry, limit int, now time.Time) string {
+	if len(entries) == 0 {
+		return tr(lang, "history.empty")
+	}
+
+	lines := make([]string, 0, limit)
+	for i := len(entries) - 1; i >= 0 && len(lines) < limit; i-- {
+		entry := entries[i]
+		until := now
+		key := "history.current"
+		if i+1 < len(entries) {
+			until = entries[i+1].At
+			key = "history.line"
+		}
+		lines = append(lines, tr(lang, key,
+			entry.At.Format("02.01 15:04"), orDash(entry.Old), orDash(entry.New), formatDuration(lang, until.Sub(entry.At))))
+	}
+	return strings.Join(lines, "\n")
+}
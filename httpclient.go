@@ -0,0 +1,190 @@
+package main
+
+import (
+	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// HTTPClientConfig настраивает HTTP-клиент для запросов к API статусов
+type HTTPClientConfig struct {
+	ConnectTimeout  Duration `yaml:"connect_timeout" json:"connect_timeout"`     // Время на установку TCP- и TLS-соединения
+	ReadTimeout     Duration `yaml:"read_timeout" json:"read_timeout"`           // Полное время запроса, включая чтение ответа
+	KeepAlive       Duration `yaml:"keep_alive" json:"keep_alive"`               // Период TCP keep-alive; отрицательный отключает повторное использование соединений
+	MaxIdleConns    int      `yaml:"max_idle_conns" json:"max_idle_conns"`       // Максимум простаивающих соединений на хост
+	IdleConnTimeout Duration `yaml:"idle_conn_timeout" json:"idle_conn_timeout"` // Через сколько закрывать простаивающее соединение
+	Proxy           string   `yaml:"proxy" json:"proxy"`                         // Прокси http://, https:// или socks5://; пусто — HTTP_PROXY/HTTPS_PROXY из окружения
+	MaxBodySize     int64    `yaml:"max_body_size" json:"max_body_size"`         // Максимальный размер ответа API в байтах после распаковки
+}
+
+func (c HTTPClientConfig) validate() error {
+	if c.ConnectTimeout.Duration <= 0 {
+		return fmt.Errorf("http_client.connect_timeout must be positive")
+	}
+	if c.ReadTimeout.Duration <= 0 {
+		return fmt.Errorf("http_client.read_timeout must be positive")
+	}
+	if c.MaxIdleConns < 0 {
+		return fmt.Errorf("http_client.max_idle_conns must not be negative")
+	}
+	if c.MaxBodySize <= 0 {
+		return fmt.Errorf("http_client.max_body_size must be positive")
+	}
+	if _, err := proxyFunc(c.Proxy); err != nil {
+		return fmt.Errorf("http_client.proxy: %w", err)
+	}
+	return nil
+}
+
+// proxyFunc возвращает выбор прокси для http.Transport: заданный адрес или,
+// если он пуст, переменные окружения HTTP_PROXY, HTTPS_PROXY и NO_PROXY.
+// socks5h:// разрешает имена хостов на стороне прокси.
+func proxyFunc(raw string) (func(*http.Request) (*url.URL, error), error) {
+	if raw == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, expected http, https, socks5 or socks5h", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy %q has no host", raw)
+	}
+	return http.ProxyURL(u), nil
+}
+
+// apiClient используется для всех запросов к API статусов
+var apiClient = http.DefaultClient
+
+// requestHeader собирает заголовки запроса к API из настроек endpoint
+func requestHeader(headers map[string]string) http.Header {
+	header := make(http.Header, len(headers))
+	for name, value := range headers {
+		header.Set(name, value)
+	}
+	return header
+}
+
+// setHeader добавляет заголовки к запросу. Host меняет адресуемый
+// виртуальный хост, а не просто записывается в заголовки.
+func setHeader(req *http.Request, header http.Header) {
+	for name, values := range header {
+		if name == "Host" {
+			req.Host = header.Get("Host")
+			continue
+		}
+		req.Header[name] = values
+	}
+}
+
+// readBody читает ответ API, распаковывая gzip, и возвращает ошибку, если
+// распакованное тело больше cfg().HTTPClient.MaxBodySize
+func readBody(resp *http.Response) ([]byte, error) {
+	var body io.Reader = resp.Body
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("gzip: %w", err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	limit := cfg().HTTPClient.MaxBodySize
+	data, err := ioutil.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("response body exceeds %d bytes", limit)
+	}
+	return data, nil
+}
+
+// ClientTLSConfig — TLS запросов к API за mTLS или с сертификатом своего
+// удостоверяющего центра
+type ClientTLSConfig struct {
+	CertFile   string `yaml:"cert_file" json:"cert_file"`     // Клиентский сертификат в PEM
+	KeyFile    string `yaml:"key_file" json:"key_file"`       // Ключ клиентского сертификата в PEM
+	CAFile     string `yaml:"ca_file" json:"ca_file"`         // Сертификаты УЦ в PEM для проверки сервера вместо системных
+	ServerName string `yaml:"server_name" json:"server_name"` // Имя сервера для проверки сертификата, если отличается от хоста в url
+}
+
+// load читает сертификаты; nil — настройки не заданы и используется общий клиент
+func (c ClientTLSConfig) load() (*tls.Config, error) {
+	if c.CertFile == "" && c.KeyFile == "" && c.CAFile == "" && c.ServerName == "" {
+		return nil, nil
+	}
+	config := &tls.Config{ServerName: c.ServerName}
+	if c.CertFile != "" || c.KeyFile != "" {
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, fmt.Errorf("client_tls: cert_file and key_file must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("client_tls: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if c.CAFile != "" {
+		data, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("client_tls: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("client_tls: no certificates in %s", c.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// newAPIClient собирает HTTP-клиент с таймаутами, чтобы зависшее API
+// не останавливало проверки навсегда. tlsConfig — TLS отдельного API
+// (client_tls); nil — системные настройки.
+func newAPIClient(config HTTPClientConfig, tlsConfig *tls.Config) *http.Client {
+	return &http.Client{
+		Transport: otelhttp.NewTransport(newAPITransport(config, tlsConfig, nil)),
+		Timeout:   config.ReadTimeout.Duration,
+	}
+}
+
+// newAPITransport собирает транспорт с таймаутами и соединениями из
+// http_client. control, если задан, проверяет адрес перед каждым подключением.
+func newAPITransport(config HTTPClientConfig, tlsConfig *tls.Config, control func(network, address string, c syscall.RawConn) error) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   config.ConnectTimeout.Duration,
+		KeepAlive: config.KeepAlive.Duration,
+		Control:   control,
+	}
+
+	// Адрес уже проверен в validate
+	proxy, _ := proxyFunc(config.Proxy)
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   config.ConnectTimeout.Duration,
+		ResponseHeaderTimeout: config.ReadTimeout.Duration,
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.MaxIdleConns,
+		IdleConnTimeout:       config.IdleConnTimeout.Duration,
+		DisableKeepAlives:     config.KeepAlive.Duration < 0,
+	}
+	return transport
+}
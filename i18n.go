@@ -0,0 +1,740 @@
+package main
+
+import (
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// languageSetting — ключ настройки чата с выбранным языком
+const languageSetting = "language"
+
+// catalogs — тексты сообщений по языкам. Значения — строки формата fmt.
+var catalogs = map[string]map[string]string{
+	"ru": {
+		"language.name": "Русский",
+
+		"start":             "Теперь вы будете получать уведомления о статусе консолей.",
+		"stop":              "Вы больше не будете получать уведомления о статусе консолей.",
+		"welcome":           "Привет! Я сообщаю об изменении статуса консолей. Нажмите «Подписаться», чтобы получать уведомления в этот чат.",
+		"status.current":    "[%s] Текущий статус:\n%s",
+		"status.failed":     "[%s] Не удалось получить статус консолей. Попробуйте позже.",
+		"status.changed":    "[%s] Статус изменился:\n%s",
+		"status.events":     "[%s]\n%s",
+		"change.down":       "❌ Консоль %s недоступна с %s",
+		"change.recovered":  "✅ Консоль %s восстановилась через %s",
+		"status.no_data":    "Нет данных о консолях.",
+		"console.unnamed":   "Без имени",
+		"error.save":        "Не удалось сохранить настройку. Попробуйте позже.",
+		"error.load":        "Не удалось загрузить данные. Попробуйте позже.",
+		"admin.only":        "Извините, эта команда доступна только администраторам бота.",
+		"admin.check.done":  "Проверка выполнена:\n%s",
+		"admin.check.ok":    "[%s] проверено",
+		"admin.check.error": "[%s] ошибка: %v",
+
+		"subscribers.empty":    "Подписчиков пока нет.",
+		"subscribers.count":    "Подписчиков: %d",
+		"subscribers.all":      "все консоли",
+		"subscribers.since":    " (с %s)",
+		"broadcast.usage":      "Укажите текст объявления: /broadcast <текст>",
+		"broadcast.message":    "📢 Объявление:\n%s",
+		"broadcast.queued":     "Объявление поставлено в очередь для %d чатов.",
+		"subscribe.usage":      "Укажите имя консоли: /subscribe <консоль>",
+		"subscribe.done":       "Вы подписаны на консоль %s.\nВаши консоли: %s",
+		"unsubscribe.usage":    "Укажите имя консоли: /unsubscribe <консоль>",
+		"unsubscribe.not_sub":  "Вы не подписаны на консоль %s отдельно. Используйте /stop, чтобы отключить все уведомления.",
+		"unsubscribe.done":     "Подписка на консоль %s отменена.\nВаши консоли: %s",
+		"unsubscribe.all_left": "Подписка на консоль %s отменена. Теперь вы получаете уведомления обо всех консолях. Используйте /stop, чтобы отключить их.",
+
+		"digest.header":       "Сводка изменений:",
+		"digest.quiet_header": "Пока действовали тихие часы, произошли изменения:",
+		"frequency.none":      "Уведомления приходят сразу. Чтобы собирать изменения в сводку, задайте интервал: /frequency 15m",
+		"frequency.current":   "Уведомления приходят не чаще раза в %s. Отключить: /frequency off",
+		"frequency.off":       "Уведомления снова приходят сразу.",
+		"frequency.usage":     "Укажите интервал не меньше минуты, например: /frequency 15m или /frequency 1h",
+		"frequency.set":       "Уведомления будут приходить не чаще раза в %s: изменения внутри интервала соберутся в одну сводку.",
+		"quiet.none":          "Тихие часы не заданы. Пример: /quiet 23:00-08:00",
+		"quiet.current":       "Тихие часы: %s. Отключить: /quiet off",
+		"quiet.off":           "Тихие часы отключены.",
+		"quiet.usage":         "Не удалось разобрать интервал. Пример: /quiet 23:00-08:00",
+		"quiet.set": "Тихие часы: %s. В это время уведомления о недоступности консолей приходят без звука, " +
+			"а остальные изменения собираются в сводку, которая придёт после окончания тихих часов.",
+
+		"message.too_long": "Сообщение слишком длинное для Telegram, полный текст — в файле.",
+
+		"uptime.usage":     "Использование: /uptime <консоль> [7d|30d]",
+		"uptime.unknown":   "Консоль %s не найдена в последних ответах API.",
+		"uptime.header":    "Доступность %s за %s:\n%s",
+		"uptime.line":      "[%s] %.2f%%, простой %s, инцидентов: %d",
+		"history.usage":    "Использование: /history <консоль> [число записей, до 50]",
+		"history.header":   "История статусов %s:\n\n%s",
+		"history.endpoint": "[%s]\n%s",
+		"history.empty":    "Смен статуса пока не было.",
+		"history.line":     "%s %s → %s (%s)",
+		"history.current":  "%s %s → %s (%s, продолжается)",
+
+		"report.header":    "📊 Сводка за %s",
+		"report.console":   "%s: %s%%, простой %s, инцидентов: %d",
+		"report.healthy":   "Без сбоев: %d консолей",
+		"report.down_now":  "Сейчас недоступны: %s",
+		"report.all_up":    "Сейчас все консоли доступны.",
+		"report.none":      "Регулярная сводка не настроена. Пример: /digest daily 09:00, /digest weekly mon 09:00 или /digest cron 0 9 * * 1-5",
+		"report.current":   "Регулярная сводка: %s. Отключить: /digest off",
+		"report.off":       "Регулярная сводка отключена.",
+		"report.usage":     "Не удалось разобрать расписание. Пример: /digest daily 09:00, /digest weekly mon 09:00 или /digest cron 0 9 * * 1-5",
+		"report.too_often": "Регулярная сводка приходит не чаще, чем раз в %s.",
+		"report.set":       "Регулярная сводка: %s.",
+		"health.header":    "🩺 Состояние проверок API:",
+		"health.ok":        "✅ [%s] проверки проходят",
+		"health.pending":   "⏳ [%s] проверок пока недостаточно",
+		"health.failing":   "❌ [%s] ошибок в последних проверках: %d из %d, последняя: %s",
+
+		"button.ack":   "✔ Принять: %s",
+		"ack.done":     "Инцидент принят",
+		"ack.already":  "Инцидент уже принял %s",
+		"ack.unknown":  "Инцидент не найден",
+		"ack.note":     "✔ %s: принято %s в %s",
+		"ack.reminder": "⏰ Консоль %s недоступна уже %s, инцидент никто не принял.",
+
+		"snooze.usage":   "Укажите срок: /snooze 2h, /snooze until tomorrow (до 09:00) или /snooze until 18:00",
+		"snooze.none":    "Уведомления включены. Выключить на время: /snooze 2h",
+		"snooze.current": "Уведомления выключены %s. Включить: /snooze off",
+		"snooze.set":     "Уведомления выключены %s.",
+		"snooze.off":     "Уведомления снова включены.",
+		"snooze.expired": "⏰ Срок /snooze истёк, уведомления снова включены.",
+		"snooze.until":   "до %s",
+		"mute.usage":     "Использование: /mute <консоль> [2h | until tomorrow | until 18:00], /unmute <консоль>",
+		"mute.none":      "Отключённых консолей нет.",
+		"mute.list":      "Отключённые консоли:\n%s",
+		"mute.set":       "Уведомления о консоли %s отключены %s.",
+		"mute.off":       "Уведомления о консоли %s снова включены.",
+		"mute.expired":   "⏰ Уведомления о консоли %s снова включены.",
+		"mute.forever":   "до /unmute",
+
+		"maintenance.usage": "Использование:\n/maintenance — список окон\n" +
+			"/maintenance add \"sat 02:00-04:00\" [консоли…] [silent] — окно раз в неделю, каждый день (\"02:00-04:00\") " +
+			"или в дату (\"2026-10-20 02:00-04:00\"); silent — без сводки после окна\n/maintenance remove <id>",
+		"maintenance.none":         "Окон обслуживания нет.",
+		"maintenance.list":         "Окна обслуживания:\n%s",
+		"maintenance.active":       "(сейчас действует)",
+		"maintenance.all_consoles": "все консоли",
+		"maintenance.added":        "Окно обслуживания #%d добавлено: %s",
+		"maintenance.removed":      "Окно обслуживания #%d удалено.",
+		"maintenance.not_found":    "Окно обслуживания #%d не найдено.",
+		"maintenance.summary":      "🛠 Обслуживание (%s) завершено. Изменения за это время:",
+
+		"flap.started":  "🔁 Консоль %s нестабильна: %d смен статуса за %s. Уведомления о ней приостановлены до стабилизации.",
+		"flap.resolved": "🟰 Консоль %s стабилизировалась, текущий статус: %s",
+
+		"stats.chats":         "Подписанных чатов: %d",
+		"stats.chats_unknown": "Подписанных чатов: неизвестно",
+		"stats.uptime":        "Время работы: %s",
+		"stats.sent":          "Отправлено уведомлений: %d",
+		"stats.last_check":    "[%s] последняя успешная проверка: %s",
+		"stats.never":         "ещё не было",
+		"stats.ago":           "%s (%s назад)",
+
+		"duration.days":    "%dд",
+		"duration.hours":   "%dч",
+		"duration.minutes": "%dм",
+		"duration.seconds": "%dс",
+
+		"language.current": "Язык: %s. Доступные языки: %s. Сменить: /language <код>",
+		"language.unknown": "Неизвестный язык. Доступные языки: %s",
+		"language.set":     "Язык переключён на русский.",
+
+		"button.subscribe":    "🔔 Подписаться",
+		"button.unsubscribe":  "🔕 Отписаться",
+		"button.status":       "📊 Статус сейчас",
+		"button.subscribed":   "Подписка оформлена",
+		"button.unsubscribed": "Подписка отменена",
+
+		"incident.ref":        " · инцидент #%d",
+		"incidents.header":    "Инциденты\n\n%s",
+		"incidents.open":      "Открытые:\n%s",
+		"incidents.none_open": "Открытых инцидентов нет.",
+		"incidents.recent":    "Последние закрытые:\n%s",
+		"incidents.line":      "#%d %s [%s] с %s",
+		"incidents.ongoing":   "— продолжается %s",
+		"incidents.lasted":    "— длился %s",
+		"incidents.acked":     "принял %s",
+
+		"escalation.banner": "🚨 НЕ ПРИНЯТ уже %s",
+
+		"oncall.none":           "График дежурств не настроен.",
+		"oncall.current":        "Сейчас дежурит: %s",
+		"oncall.next":           "Через неделю: %s",
+		"oncall.override_until": "Временная замена до %s",
+		"oncall.mention":        "Дежурный: %s",
+		"oncall.override_usage": "Использование: /override <id или имя> [2h | until tomorrow], /override off",
+		"oncall.override_set":   "Дежурит %s до %s.",
+		"oncall.override_off":   "Временная замена отменена, дежурства идут по графику.",
+		"oncall.unknown_user":   "Участник %s не найден в графике дежурств. Укажите Telegram ID.",
+
+		"testalert.usage":    "Использование: /testalert <консоль> [статус] — пробное уведомление всем подписчикам; по умолчанию консоль становится недоступна",
+		"testalert.sent":     "Пробное уведомление отправлено: [%s] %s: %s → %s. Каналов: %d.",
+		"testalert.filtered": "Правила не пропустили изменение консоли %s, уведомление не отправлено.",
+		"testalert.mark":     "\n\n🧪 Это пробное уведомление (/testalert).",
+
+		"reload.done":   "Конфигурация перечитана, API: %d.",
+		"reload.failed": "Не удалось перечитать конфигурацию, работают прежние настройки: %s",
+
+		"monitors.disabled": "Собственные мониторы в этом боте выключены.",
+		"monitors.usage": "Использование:\n/add_monitor <адрес> [every <интервал>] — проверять свой адрес, например /add_monitor https://example.com/status.json every 1m\n" +
+			"/my_monitors — ваши мониторы в этом чате\n/remove_monitor <id> — удалить монитор",
+		"monitors.added":     "Монитор #%d добавлен: %s, проверка раз в %s. Об изменениях статусов сообщу в этот чат.",
+		"monitors.none":      "У вас нет мониторов. Добавьте: /add_monitor <адрес>",
+		"monitors.list":      "Ваши мониторы:\n%s",
+		"monitors.removed":   "Монитор #%d удалён.",
+		"monitors.not_found": "Монитор #%d не найден среди ваших.",
+
+		"monitors.interval_too_short": "Слишком частая проверка: интервал должен быть не меньше %s.",
+		"monitors.quota":              "Достигнут лимит: не больше %d мониторов на пользователя. Удалите ненужный: /remove_monitor <id>",
+		"monitors.full":               "Сейчас нельзя добавить монитор: достигнут общий лимит бота.",
+		"monitors.duplicate":          "Этот адрес уже проверяется монитором #%d.",
+		"monitors.down":               "❌ Монитор %s недоступен: %s",
+		"monitors.recovered":          "✅ Монитор %s восстановился через %s",
+		"monitors.rate_limited":       "Слишком много уведомлений от ваших мониторов: не больше %d в час. Следующие изменения в течение часа не отправляются.",
+
+		"dashboard.title":        "Статус консолей",
+		"dashboard.meta":         "Подписчиков: %d · работает %s · обновлено %s",
+		"dashboard.last_check":   "Последняя проверка",
+		"dashboard.incidents":    "Инциденты",
+		"dashboard.console":      "Консоль",
+		"dashboard.started":      "Начало",
+		"dashboard.resolved":     "Восстановлена",
+		"dashboard.open":         "продолжается",
+		"dashboard.no_incidents": "Инцидентов пока не было.",
+
+		"webapp.button":       "Статус",
+		"webapp.title":        "Статус консолей",
+		"webapp.unauthorized": "Откройте мини-приложение из Telegram: кнопка «Статус» в меню чата с ботом.",
+		"webapp.status":       "Консоли",
+		"webapp.uptime":       "Доступность за сутки",
+		"webapp.settings":     "Настройки",
+		"webapp.subscribed":   "Получать уведомления",
+		"webapp.language":     "Язык",
+		"webapp.quiet_hours":  "Тихие часы",
+		"webapp.quiet_hint":   "23:00-08:00, пусто — выключены",
+		"webapp.digest":       "Сводка",
+		"webapp.digest_hint":  "daily 09:00, weekly mon 09:00, пусто — выключена",
+		"webapp.save":         "Сохранить",
+		"webapp.saved":        "Сохранено",
+
+		"inline.summary":             "Статус всех консолей",
+		"inline.summary.description": "Работают: %d, недоступны: %d",
+		"inline.console":             "[%s] %s: %s",
+
+		"command.status":         "Текущий статус консолей",
+		"command.start":          "Подписаться на уведомления",
+		"command.stop":           "Отписаться от уведомлений",
+		"command.subscribe":      "Подписаться на отдельную консоль",
+		"command.unsubscribe":    "Отписаться от консоли",
+		"command.history":        "История смен статуса консоли",
+		"command.uptime":         "Доступность консоли за период",
+		"command.incidents":      "Последние инциденты",
+		"command.quiet":          "Тихие часы без звука",
+		"command.frequency":      "Не чаще одного уведомления за период",
+		"command.digest":         "Регулярная сводка",
+		"command.snooze":         "Приостановить уведомления",
+		"command.mute":           "Заглушить консоль",
+		"command.unmute":         "Снова уведомлять о консоли",
+		"command.language":       "Язык бота",
+		"command.oncall":         "Кто сейчас дежурит",
+		"command.stats":          "Статистика бота",
+		"command.add_monitor":    "Добавить свой монитор",
+		"command.my_monitors":    "Мои мониторы",
+		"command.remove_monitor": "Удалить свой монитор",
+		"command.check":          "Проверить API немедленно",
+		"command.subscribers":    "Подписанные чаты",
+		"command.broadcast":      "Сообщение всем подписчикам",
+		"command.maintenance":    "Окна обслуживания",
+		"command.override":       "Назначить дежурного вручную",
+		"command.testalert":      "Пробное уведомление",
+		"command.reload":         "Перечитать конфигурацию",
+
+		"menu.status":         "📊 Статус",
+		"menu.history":        "🕘 История",
+		"menu.settings":       "⚙️ Настройки",
+		"menu.mute":           "🔕 Тишина на час",
+		"menu.placeholder":    "Команда или кнопка",
+		"menu.on":             "Клавиатура с командами включена. Убрать её: /keyboard off",
+		"menu.off":            "Клавиатура с командами убрана. Вернуть: /keyboard",
+		"menu.usage":          "Использование: /keyboard [on|off]",
+		"menu.private_only":   "Клавиатура с командами доступна только в личном чате с ботом.",
+		"menu.choose_console": "История какой консоли?",
+		"command.keyboard":    "Клавиатура с командами",
+
+		"settings.title":            "Настройки чата",
+		"settings.language":         "Язык: %s",
+		"settings.quiet":            "Тихие часы: %s",
+		"settings.consoles":         "Консоли: %s",
+		"settings.verbosity":        "Уведомления: %s",
+		"settings.digest":           "Сводка: %s",
+		"settings.close":            "Закрыть",
+		"settings.back":             "« Назад",
+		"settings.off":              "выключено",
+		"settings.all_consoles":     "все",
+		"settings.saved":            "Сохранено",
+		"settings.choose_language":  "Выберите язык:",
+		"settings.choose_quiet":     "Тихие часы (свой интервал: /quiet 21:00-07:00):",
+		"settings.choose_consoles":  "Отметьте консоли, о которых уведомлять:",
+		"settings.choose_verbosity": "О каких изменениях уведомлять?",
+		"settings.choose_digest":    "Сводка (своё расписание: /digest):",
+		"verbosity.all":             "обо всех изменениях",
+		"verbosity.outages":         "только о сбоях и восстановлении",
+		"verbosity.critical":        "только о критичных",
+		"verbosity.current":         "Уведомления: %s. Изменить: /verbosity all|outages|critical",
+		"verbosity.set":             "Уведомления: %s.",
+		"verbosity.usage":           "Использование: /verbosity all|outages|critical",
+		"command.settings":          "Настройки чата",
+		"command.verbosity":         "О каких изменениях уведомлять",
+
+		"channel.usage":       "Использование: /channel — список каналов, /channel add <@канал|ID> [консоли...], /channel remove <@канал|ID>",
+		"channel.list":        "Подписанные каналы:\n%s",
+		"channel.empty":       "Подписанных каналов нет. Назначьте бота администратором канала с правом публикации и выполните /channel add @канал.",
+		"channel.added":       "Канал %s подписан на уведомления.",
+		"channel.removed":     "Канал %s отписан от уведомлений.",
+		"channel.not_channel": "%s — не канал.",
+		"channel.cannot_post": "Бот не может публиковать в %s: назначьте его администратором с правом публикации сообщений.",
+		"channel.not_found":   "Канал %s не найден или бот в нём не состоит.",
+		"channel.error":       "Не удалось проверить канал %s, попробуйте позже.",
+		"channel.offer":       "Бота назначили администратором канала %s. Подписать канал на уведомления?",
+		"channel.subscribe":   "Подписать канал",
+		"command.channel":     "Каналы для уведомлений",
+
+		"topic.usage":           "Использование: /topic — куда приходят уведомления, /topic here [консоли...] — в эту тему (отправьте в теме, не отвечая на сообщение), /topic <ID|ссылка на тему> [консоли...], /topic off [консоли...]",
+		"topic.supergroup_only": "Темы есть только в супергруппах с включёнными темами форума.",
+		"topic.not_in_topic":    "Отправьте /topic here в нужной теме, не отвечая на сообщение, или укажите ID темы либо ссылку на неё.",
+		"topic.not_found":       "Не удалось написать в тему %d: проверьте, что она существует и открыта.",
+		"topic.here":            "Уведомления чата будут приходить в эту тему.",
+		"topic.here_consoles":   "Уведомления о консолях %s будут приходить в эту тему.",
+		"topic.off":             "Уведомления будут приходить в тему чата по умолчанию.",
+		"topic.general":         "General",
+		"topic.default":         "По умолчанию: %s",
+		"topic.list":            "Темы для уведомлений:\n%s",
+		"command.topic":         "Тема форума для уведомлений",
+
+		"live.usage":        "Использование: /live on — закреплённая сводка вместе с уведомлениями, /live only — вместо уведомлений, /live off",
+		"live.none":         "Закреплённая сводка выключена. Включить: /live on или /live only",
+		"live.current_on":   "Закреплённая сводка обновляется вместе с уведомлениями. Выключить: /live off",
+		"live.current_only": "Изменения показываются только в закреплённой сводке. Выключить: /live off",
+		"live.on":           "Закреплённая сводка включена и будет обновляться при каждом изменении.",
+		"live.only":         "Закреплённая сводка включена; отдельных уведомлений об изменениях больше не будет.",
+		"live.off":          "Закреплённая сводка выключена.",
+		"live.error":        "Не удалось отправить сводку, попробуйте позже.",
+		"live.updated":      "Обновлено: %s",
+		"command.live":      "Закреплённая сводка статусов",
+
+		"followup.recovered": "✅ %s восстановлена через %s",
+		"followup.changed":   "%s: %s → %s (%s)",
+
+		"chart.usage":   "Использование: /chart <консоль> [24h|7d]",
+		"chart.caption": "Доступность %s за %s:\n%s",
+		"chart.error":   "Не удалось построить график, попробуйте позже.",
+		"command.chart": "График доступности консоли",
+	},
+	"en": {
+		"language.name": "English",
+
+		"start":             "You will now receive console status notifications.",
+		"stop":              "You will no longer receive console status notifications.",
+		"welcome":           "Hi! I report console status changes. Press “Subscribe” to receive notifications in this chat.",
+		"status.current":    "[%s] Current status:\n%s",
+		"status.failed":     "[%s] Could not get console status. Please try again later.",
+		"status.changed":    "[%s] Status changed:\n%s",
+		"status.events":     "[%s]\n%s",
+		"change.down":       "❌ Console %s went down at %s",
+		"change.recovered":  "✅ Console %s recovered after %s",
+		"status.no_data":    "No console data.",
+		"console.unnamed":   "Unnamed",
+		"error.save":        "Could not save the setting. Please try again later.",
+		"error.load":        "Could not load data. Please try again later.",
+		"admin.only":        "Sorry, this command is available to bot administrators only.",
+		"admin.check.done":  "Check completed:\n%s",
+		"admin.check.ok":    "[%s] checked",
+		"admin.check.error": "[%s] error: %v",
+
+		"subscribers.empty":    "No subscribers yet.",
+		"subscribers.count":    "Subscribers: %d",
+		"subscribers.all":      "all consoles",
+		"subscribers.since":    " (since %s)",
+		"broadcast.usage":      "Provide the announcement text: /broadcast <text>",
+		"broadcast.message":    "📢 Announcement:\n%s",
+		"broadcast.queued":     "Announcement queued for %d chats.",
+		"subscribe.usage":      "Provide a console name: /subscribe <console>",
+		"subscribe.done":       "You are subscribed to console %s.\nYour consoles: %s",
+		"unsubscribe.usage":    "Provide a console name: /unsubscribe <console>",
+		"unsubscribe.not_sub":  "You are not subscribed to console %s separately. Use /stop to turn off all notifications.",
+		"unsubscribe.done":     "Unsubscribed from console %s.\nYour consoles: %s",
+		"unsubscribe.all_left": "Unsubscribed from console %s. You now receive notifications about all consoles. Use /stop to turn them off.",
+
+		"digest.header":       "Summary of changes:",
+		"digest.quiet_header": "Changes during quiet hours:",
+		"frequency.none":      "Notifications are sent immediately. To group changes into a summary, set an interval: /frequency 15m",
+		"frequency.current":   "Notifications are sent at most once every %s. Turn off: /frequency off",
+		"frequency.off":       "Notifications are sent immediately again.",
+		"frequency.usage":     "Provide an interval of at least a minute, e.g. /frequency 15m or /frequency 1h",
+		"frequency.set":       "Notifications will be sent at most once every %s: changes within the interval are grouped into one summary.",
+		"quiet.none":          "Quiet hours are not set. Example: /quiet 23:00-08:00",
+		"quiet.current":       "Quiet hours: %s. Turn off: /quiet off",
+		"quiet.off":           "Quiet hours are turned off.",
+		"quiet.usage":         "Could not parse the interval. Example: /quiet 23:00-08:00",
+		"quiet.set": "Quiet hours: %s. During this time console outage alerts arrive silently, " +
+			"and other changes are collected into a summary sent when quiet hours end.",
+
+		"message.too_long": "The message is too long for Telegram, the full text is attached.",
+
+		"uptime.usage":     "Usage: /uptime <console> [7d|30d]",
+		"uptime.unknown":   "Console %s was not found in the latest API responses.",
+		"uptime.header":    "Availability of %s over %s:\n%s",
+		"uptime.line":      "[%s] %.2f%%, downtime %s, incidents: %d",
+		"history.usage":    "Usage: /history <console> [number of entries, up to 50]",
+		"history.header":   "Status history of %s:\n\n%s",
+		"history.endpoint": "[%s]\n%s",
+		"history.empty":    "No status changes yet.",
+		"history.line":     "%s %s → %s (%s)",
+		"history.current":  "%s %s → %s (%s, ongoing)",
+
+		"report.header":    "📊 Summary for the last %s",
+		"report.console":   "%s: %s%%, downtime %s, incidents: %d",
+		"report.healthy":   "No issues: %d consoles",
+		"report.down_now":  "Down right now: %s",
+		"report.all_up":    "All consoles are up right now.",
+		"report.none":      "Scheduled summary is not set. Example: /digest daily 09:00, /digest weekly mon 09:00 or /digest cron 0 9 * * 1-5",
+		"report.current":   "Scheduled summary: %s. Turn off: /digest off",
+		"report.off":       "Scheduled summary is turned off.",
+		"report.usage":     "Could not parse the schedule. Example: /digest daily 09:00, /digest weekly mon 09:00 or /digest cron 0 9 * * 1-5",
+		"report.too_often": "Scheduled summary can be sent at most once per %s.",
+		"report.set":       "Scheduled summary: %s.",
+		"health.header":    "🩺 API check status:",
+		"health.ok":        "✅ [%s] checks are passing",
+		"health.pending":   "⏳ [%s] not enough checks yet",
+		"health.failing":   "❌ [%s] failed recent checks: %d of %d, last error: %s",
+
+		"button.ack":   "✔ Acknowledge: %s",
+		"ack.done":     "Incident acknowledged",
+		"ack.already":  "Already acknowledged by %s",
+		"ack.unknown":  "Incident not found",
+		"ack.note":     "✔ %s: acknowledged by %s at %s",
+		"ack.reminder": "⏰ Console %s has been down for %s and nobody has acknowledged it.",
+
+		"snooze.usage":   "Provide a period: /snooze 2h, /snooze until tomorrow (until 09:00) or /snooze until 18:00",
+		"snooze.none":    "Notifications are on. To pause them: /snooze 2h",
+		"snooze.current": "Notifications are paused %s. Resume: /snooze off",
+		"snooze.set":     "Notifications are paused %s.",
+		"snooze.off":     "Notifications are on again.",
+		"snooze.expired": "⏰ Snooze is over, notifications are on again.",
+		"snooze.until":   "until %s",
+		"mute.usage":     "Usage: /mute <console> [2h | until tomorrow | until 18:00], /unmute <console>",
+		"mute.none":      "No muted consoles.",
+		"mute.list":      "Muted consoles:\n%s",
+		"mute.set":       "Notifications about console %s are muted %s.",
+		"mute.off":       "Notifications about console %s are on again.",
+		"mute.expired":   "⏰ Notifications about console %s are on again.",
+		"mute.forever":   "until /unmute",
+
+		"maintenance.usage": "Usage:\n/maintenance — list windows\n" +
+			"/maintenance add \"sat 02:00-04:00\" [consoles…] [silent] — weekly window, daily (\"02:00-04:00\") " +
+			"or on a date (\"2026-10-20 02:00-04:00\"); silent — no summary after the window\n/maintenance remove <id>",
+		"maintenance.none":         "No maintenance windows.",
+		"maintenance.list":         "Maintenance windows:\n%s",
+		"maintenance.active":       "(active now)",
+		"maintenance.all_consoles": "all consoles",
+		"maintenance.added":        "Maintenance window #%d added: %s",
+		"maintenance.removed":      "Maintenance window #%d removed.",
+		"maintenance.not_found":    "Maintenance window #%d not found.",
+		"maintenance.summary":      "🛠 Maintenance (%s) is over. Changes during the window:",
+
+		"flap.started":  "🔁 Console %s is flapping: %d status changes within %s. Its notifications are paused until it stabilizes.",
+		"flap.resolved": "🟰 Console %s has stabilized, current status: %s",
+
+		"stats.chats":         "Subscribed chats: %d",
+		"stats.chats_unknown": "Subscribed chats: unknown",
+		"stats.uptime":        "Uptime: %s",
+		"stats.sent":          "Notifications sent: %d",
+		"stats.last_check":    "[%s] last successful check: %s",
+		"stats.never":         "never",
+		"stats.ago":           "%s (%s ago)",
+
+		"duration.days":    "%dd",
+		"duration.hours":   "%dh",
+		"duration.minutes": "%dm",
+		"duration.seconds": "%ds",
+
+		"language.current": "Language: %s. Available languages: %s. Change: /language <code>",
+		"language.unknown": "Unknown language. Available languages: %s",
+		"language.set":     "Language switched to English.",
+
+		"button.subscribe":    "🔔 Subscribe",
+		"button.unsubscribe":  "🔕 Unsubscribe",
+		"button.status":       "📊 Status now",
+		"button.subscribed":   "Subscribed",
+		"button.unsubscribed": "Unsubscribed",
+
+		"incident.ref":        " · incident #%d",
+		"incidents.header":    "Incidents\n\n%s",
+		"incidents.open":      "Open:\n%s",
+		"incidents.none_open": "No open incidents.",
+		"incidents.recent":    "Recently closed:\n%s",
+		"incidents.line":      "#%d %s [%s] since %s",
+		"incidents.ongoing":   "— ongoing for %s",
+		"incidents.lasted":    "— lasted %s",
+		"incidents.acked":     "acknowledged by %s",
+
+		"escalation.banner": "🚨 UNACKED for %s",
+
+		"oncall.none":           "No on-call schedule is configured.",
+		"oncall.current":        "On call now: %s",
+		"oncall.next":           "Next week: %s",
+		"oncall.override_until": "Temporary override until %s",
+		"oncall.mention":        "On call: %s",
+		"oncall.override_usage": "Usage: /override <id or name> [2h | until tomorrow], /override off",
+		"oncall.override_set":   "%s is on call until %s.",
+		"oncall.override_off":   "Override cancelled, on-call follows the schedule.",
+		"oncall.unknown_user":   "%s is not in the on-call schedule. Use a Telegram ID.",
+
+		"testalert.usage":    "Usage: /testalert <console> [status] — test notification to all subscribers; by default the console goes down",
+		"testalert.sent":     "Test notification sent: [%s] %s: %s → %s. Channels: %d.",
+		"testalert.filtered": "Rules filtered out the change of console %s, nothing was sent.",
+		"testalert.mark":     "\n\n🧪 This is a test notification (/testalert).",
+
+		"reload.done":   "Config reloaded, endpoints: %d.",
+		"reload.failed": "Could not reload the config, keeping the previous settings: %s",
+
+		"monitors.disabled": "Personal monitors are disabled in this bot.",
+		"monitors.usage": "Usage:\n/add_monitor <url> [every <interval>] — watch your own URL, e.g. /add_monitor https://example.com/status.json every 1m\n" +
+			"/my_monitors — your monitors in this chat\n/remove_monitor <id> — remove a monitor",
+		"monitors.added":     "Monitor #%d added: %s, checked every %s. Status changes will be reported to this chat.",
+		"monitors.none":      "You have no monitors. Add one: /add_monitor <url>",
+		"monitors.list":      "Your monitors:\n%s",
+		"monitors.removed":   "Monitor #%d removed.",
+		"monitors.not_found": "Monitor #%d is not one of yours.",
+
+		"monitors.interval_too_short": "Checks are too frequent: the interval must be at least %s.",
+		"monitors.quota":              "Limit reached: at most %d monitors per user. Remove one you no longer need: /remove_monitor <id>",
+		"monitors.full":               "Cannot add a monitor right now: the bot-wide limit is reached.",
+		"monitors.duplicate":          "This URL is already watched by monitor #%d.",
+		"monitors.down":               "❌ Monitor %s is down: %s",
+		"monitors.recovered":          "✅ Monitor %s recovered after %s",
+		"monitors.rate_limited":       "Too many notifications from your monitors: at most %d per hour. Further changes within the hour are not sent.",
+
+		"dashboard.title":        "Console status",
+		"dashboard.meta":         "Subscribers: %d · up %s · updated %s",
+		"dashboard.last_check":   "Last check",
+		"dashboard.incidents":    "Incidents",
+		"dashboard.console":      "Console",
+		"dashboard.started":      "Started",
+		"dashboard.resolved":     "Resolved",
+		"dashboard.open":         "ongoing",
+		"dashboard.no_incidents": "No incidents yet.",
+
+		"webapp.button":       "Status",
+		"webapp.title":        "Console status",
+		"webapp.unauthorized": "Open the mini app from Telegram: the \"Status\" button in the bot chat menu.",
+		"webapp.status":       "Consoles",
+		"webapp.uptime":       "Availability over 24h",
+		"webapp.settings":     "Settings",
+		"webapp.subscribed":   "Receive notifications",
+		"webapp.language":     "Language",
+		"webapp.quiet_hours":  "Quiet hours",
+		"webapp.quiet_hint":   "23:00-08:00, empty to disable",
+		"webapp.digest":       "Digest",
+		"webapp.digest_hint":  "daily 09:00, weekly mon 09:00, empty to disable",
+		"webapp.save":         "Save",
+		"webapp.saved":        "Saved",
+
+		"inline.summary":             "Status of all consoles",
+		"inline.summary.description": "Online: %d, down: %d",
+		"inline.console":             "[%s] %s: %s",
+
+		"command.status":         "Current console status",
+		"command.start":          "Subscribe to notifications",
+		"command.stop":           "Unsubscribe from notifications",
+		"command.subscribe":      "Subscribe to a single console",
+		"command.unsubscribe":    "Unsubscribe from a console",
+		"command.history":        "Status change history of a console",
+		"command.uptime":         "Console availability over a period",
+		"command.incidents":      "Recent incidents",
+		"command.quiet":          "Quiet hours without sound",
+		"command.frequency":      "At most one notification per period",
+		"command.digest":         "Scheduled digest",
+		"command.snooze":         "Pause notifications",
+		"command.mute":           "Mute a console",
+		"command.unmute":         "Unmute a console",
+		"command.language":       "Bot language",
+		"command.oncall":         "Who is on call now",
+		"command.stats":          "Bot statistics",
+		"command.add_monitor":    "Add your own monitor",
+		"command.my_monitors":    "My monitors",
+		"command.remove_monitor": "Remove your monitor",
+		"command.check":          "Check the APIs now",
+		"command.subscribers":    "Subscribed chats",
+		"command.broadcast":      "Message all subscribers",
+		"command.maintenance":    "Maintenance windows",
+		"command.override":       "Override who is on call",
+		"command.testalert":      "Send a test alert",
+		"command.reload":         "Reload the configuration",
+
+		"menu.status":         "📊 Status",
+		"menu.history":        "🕘 History",
+		"menu.settings":       "⚙️ Settings",
+		"menu.mute":           "🔕 Mute 1h",
+		"menu.placeholder":    "Command or button",
+		"menu.on":             "Command keyboard is on. To remove it: /keyboard off",
+		"menu.off":            "Command keyboard removed. To bring it back: /keyboard",
+		"menu.usage":          "Usage: /keyboard [on|off]",
+		"menu.private_only":   "The command keyboard is only available in a private chat with the bot.",
+		"menu.choose_console": "History of which console?",
+		"command.keyboard":    "Command keyboard",
+
+		"settings.title":            "Chat settings",
+		"settings.language":         "Language: %s",
+		"settings.quiet":            "Quiet hours: %s",
+		"settings.consoles":         "Consoles: %s",
+		"settings.verbosity":        "Notifications: %s",
+		"settings.digest":           "Digest: %s",
+		"settings.close":            "Close",
+		"settings.back":             "« Back",
+		"settings.off":              "off",
+		"settings.all_consoles":     "all",
+		"settings.saved":            "Saved",
+		"settings.choose_language":  "Choose a language:",
+		"settings.choose_quiet":     "Quiet hours (custom range: /quiet 21:00-07:00):",
+		"settings.choose_consoles":  "Select the consoles to be notified about:",
+		"settings.choose_verbosity": "Which changes should be notified?",
+		"settings.choose_digest":    "Digest (custom schedule: /digest):",
+		"verbosity.all":             "all changes",
+		"verbosity.outages":         "outages and recoveries only",
+		"verbosity.critical":        "critical only",
+		"verbosity.current":         "Notifications: %s. Change with /verbosity all|outages|critical",
+		"verbosity.set":             "Notifications: %s.",
+		"verbosity.usage":           "Usage: /verbosity all|outages|critical",
+		"command.settings":          "Chat settings",
+		"command.verbosity":         "Which changes to notify about",
+
+		"channel.usage":       "Usage: /channel — list channels, /channel add <@channel|ID> [consoles...], /channel remove <@channel|ID>",
+		"channel.list":        "Subscribed channels:\n%s",
+		"channel.empty":       "No channels are subscribed. Make the bot a channel administrator allowed to post messages and run /channel add @channel.",
+		"channel.added":       "Channel %s is subscribed to notifications.",
+		"channel.removed":     "Channel %s is unsubscribed from notifications.",
+		"channel.not_channel": "%s is not a channel.",
+		"channel.cannot_post": "The bot cannot post to %s: make it an administrator allowed to post messages.",
+		"channel.not_found":   "Channel %s was not found or the bot is not a member.",
+		"channel.error":       "Could not check channel %s, try again later.",
+		"channel.offer":       "The bot was made an administrator of channel %s. Subscribe the channel to notifications?",
+		"channel.subscribe":   "Subscribe channel",
+		"command.channel":     "Notification channels",
+
+		"topic.usage":           "Usage: /topic — where notifications go, /topic here [consoles...] — to this topic (send it in the topic without replying to a message), /topic <ID|topic link> [consoles...], /topic off [consoles...]",
+		"topic.supergroup_only": "Topics are only available in supergroups with forum topics enabled.",
+		"topic.not_in_topic":    "Send /topic here in the topic without replying to a message, or give the topic ID or link.",
+		"topic.not_found":       "Could not post to topic %d: make sure it exists and is open.",
+		"topic.here":            "Chat notifications will be posted to this topic.",
+		"topic.here_consoles":   "Notifications about %s will be posted to this topic.",
+		"topic.off":             "Notifications will go to the chat's default topic.",
+		"topic.general":         "General",
+		"topic.default":         "Default: %s",
+		"topic.list":            "Notification topics:\n%s",
+		"command.topic":         "Forum topic for notifications",
+
+		"live.usage":        "Usage: /live on — pinned dashboard in addition to notifications, /live only — instead of notifications, /live off",
+		"live.none":         "The pinned dashboard is off. Turn it on: /live on or /live only",
+		"live.current_on":   "The pinned dashboard is updated alongside notifications. Turn it off: /live off",
+		"live.current_only": "Changes are shown only in the pinned dashboard. Turn it off: /live off",
+		"live.on":           "The pinned dashboard is on and will be updated on every change.",
+		"live.only":         "The pinned dashboard is on; separate change notifications will no longer be sent.",
+		"live.off":          "The pinned dashboard is off.",
+		"live.error":        "Could not send the dashboard, try again later.",
+		"live.updated":      "Updated: %s",
+		"command.live":      "Pinned status dashboard",
+
+		"followup.recovered": "✅ %s recovered after %s",
+		"followup.changed":   "%s: %s → %s (%s)",
+
+		"chart.usage":   "Usage: /chart <console> [24h|7d]",
+		"chart.caption": "Availability of %s over %s:\n%s",
+		"chart.error":   "Could not draw the chart, try again later.",
+		"command.chart": "Console availability chart",
+	},
+}
+
+// tr возвращает текст сообщения на языке lang. Если перевода нет, берётся
+// язык по умолчанию, затем русский.
+func tr(lang, key string, args ...interface{}) string {
+	format, ok := catalogs[lang][key]
+	if !ok {
+		format, ok = catalogs[cfg().DefaultLanguage][key]
+	}
+	if !ok {
+		format, ok = catalogs["ru"][key]
+	}
+	if !ok {
+		slog.Warn("Missing translation", "key", key)
+		return key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// settingsLang возвращает язык из настроек чата или язык по умолчанию
+func settingsLang(settings map[string]string) string {
+	if lang := settings[languageSetting]; catalogs[lang] != nil {
+		return lang
+	}
+	return cfg().DefaultLanguage
+}
+
+// chatLang возвращает язык, выбранный в чате
+func chatLang(chatID int64) string {
+	settings, err := store.ChatSettings(chatID)
+	if err != nil {
+		slog.Error("Error loading chat settings", "chat_id", chatID, "error", err)
+	}
+	return settingsLang(settings)
+}
+
+// reply отправляет в чат сообщение на его языке
+func reply(chatID int64, key string, args ...interface{}) {
+	bot.Send(tgbotapi.NewMessage(chatID, tr(chatLang(chatID), key, args...)))
+}
+
+// languages возвращает отсортированный список кодов доступных языков
+func languages() string {
+	codes := make([]string, 0, len(catalogs))
+	for code := range catalogs {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return strings.Join(codes, ", ")
+}
+
+// handleLanguage обрабатывает /language <код> и /language без аргументов
+func handleLanguage(chatID int64, args string) {
+	lang := strings.ToLower(strings.TrimSpace(args))
+	if lang == "" {
+		current := chatLang(chatID)
+		reply(chatID, "language.current", tr(current, "language.name"), languages())
+		return
+	}
+	if catalogs[lang] == nil {
+		reply(chatID, "language.unknown", languages())
+		return
+	}
+
+	if err := store.SetChatSetting(chatID, languageSetting, lang); err != nil {
+		slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+	msg := tgbotapi.NewMessage(chatID, tr(lang, "language.set"))
+	// Постоянная клавиатура отправляется заново с подписями на новом языке
+	if settings, err := store.ChatSettings(chatID); err == nil && settings[replyKeyboardSetting] != "" {
+		msg.ReplyMarkup = replyKeyboard(lang)
+	}
+	bot.Send(msg)
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"status-bot/monitor"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	incidentSeqKey  = "incident_seq"   // Ключ состояния с последним номером инцидента
+	openIncidentKey = "incidents_open" // Ключ состояния со списком открытых инцидентов
+)
+
+// Incident — недоступность одной консоли от перехода в down до восстановления
+type Incident struct {
+	ID       int       `json:"id"`
+	Endpoint string    `json:"endpoint"`
+	Console  string    `json:"console"`
+	Started  time.Time `json:"started"`
+	Resolved time.Time `json:"resolved,omitempty"`
+
+	AckedBy   string    `json:"acked_by,omitempty"` // Кто принял инцидент в работу
+	AckedAt   time.Time `json:"acked_at,omitempty"`
+	Reminded  time.Time `json:"reminded,omitempty"`  // Когда в последний раз напоминали о непринятом инциденте
+	Escalated time.Time `json:"escalated,omitempty"` // Когда инцидент переслан в чат эскалации
+	Severity  string    `json:"severity,omitempty"`  // Важность уведомления о начале; пусто у инцидентов, записанных до появления поля
+}
+
+// open сообщает, что консоль ещё не восстановилась
+func (i Incident) open() bool {
+	return i.Resolved.IsZero()
+}
+
+// critical сообщает, что о начале инцидента было критичное уведомление.
+// Инциденты без записанной важности считаются критичными, как раньше.
+func (i Incident) critical() bool {
+	return i.Severity != severityInfo
+}
+
+// maxRecentIncidents — сколько закрытых инцидентов показывает /incidents
+const maxRecentIncidents = 10
+
+var incidentsMutex = &sync.Mutex{}
+
+func incidentKey(id int) string {
+	return "incident:" + strconv.Itoa(id)
+}
+
+// loadIncident читает инцидент; второй результат false, если его нет
+func loadIncident(id int) (Incident, bool, error) {
+	value, err := store.State(incidentKey(id))
+	if err != nil || value == "" {
+		return Incident{}, false, err
+	}
+	var incident Incident
+	if err := json.Unmarshal([]byte(value), &incident); err != nil {
+		return Incident{}, false, err
+	}
+	return incident, true, nil
+}
+
+func saveIncident(incident Incident) error {
+	data, err := json.Marshal(incident)
+	if err != nil {
+		return err
+	}
+	return store.SetState(incidentKey(incident.ID), string(data))
+}
+
+// openIncidentIDs возвращает номера открытых инцидентов
+func openIncidentIDs() ([]int, error) {
+	value, err := store.State(openIncidentKey)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	var ids []int
+	err = json.Unmarshal([]byte(value), &ids)
+	return ids, err
+}
+
+func saveOpenIncidentIDs(ids []int) error {
+	if len(ids) == 0 {
+		return store.SetState(openIncidentKey, "")
+	}
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+	return store.SetState(openIncidentKey, string(data))
+}
+
+// openIncidents возвращает открытые инциденты
+func openIncidents() ([]Incident, error) {
+	ids, err := openIncidentIDs()
+	if err != nil {
+		return nil, err
+	}
+	incidents := make([]Incident, 0, len(ids))
+	for _, id := range ids {
+		incident, ok, err := loadIncident(id)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			incidents = append(incidents, incident)
+		}
+	}
+	return incidents, nil
+}
+
+// startIncident открывает инцидент для консоли, ставшей недоступной
+func startIncident(endpoint, console string, now time.Time) (Incident, error) {
+	incidentsMutex.Lock()
+	defer incidentsMutex.Unlock()
+
+	seq, err := store.State(incidentSeqKey)
+	if err != nil {
+		return Incident{}, err
+	}
+	id, _ := strconv.Atoi(seq)
+	id++
+	if err := store.SetState(incidentSeqKey, strconv.Itoa(id)); err != nil {
+		return Incident{}, err
+	}
+
+	incident := Incident{ID: id, Endpoint: endpoint, Console: console, Started: now}
+	if err := saveIncident(incident); err != nil {
+		return Incident{}, err
+	}
+	ids, err := openIncidentIDs()
+	if err != nil {
+		return Incident{}, err
+	}
+	return incident, saveOpenIncidentIDs(append(ids, id))
+}
+
+// recordIncidentSeverities запоминает в открытых событием инцидентах
+// важность уведомления о них. Об инцидентах, изменения которых отброшены
+// правилами, обслуживанием или подавлением мигания, не уведомляли — им info.
+func recordIncidentSeverities(transitions map[string]transition, changes []monitor.StatusChange, severities map[string]string) {
+	notified := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		notified[change.Name] = true
+	}
+	for name, t := range transitions {
+		if t.kind != changeDown || t.incident.ID == 0 {
+			continue
+		}
+		severity := severityInfo
+		if notified[name] {
+			severity = severities[name]
+			if severity == "" {
+				severity = severityCritical
+			}
+		}
+		if _, _, err := updateIncident(t.incident.ID, func(i *Incident) bool {
+			i.Severity = severity
+			return true
+		}); err != nil {
+			slog.Error("Error saving incident", "incident_id", t.incident.ID, "error", err)
+		}
+	}
+}
+
+// resolveIncident закрывает открытый инцидент консоли и возвращает его;
+// нулевой Incident, если открытого инцидента не было
+func resolveIncident(endpoint, console string, now time.Time) Incident {
+	incidentsMutex.Lock()
+	defer incidentsMutex.Unlock()
+
+	ids, err := openIncidentIDs()
+	if err != nil {
+		slog.Error("Error loading open incidents", "error", err)
+		return Incident{}
+	}
+	var resolved Incident
+	kept := ids[:0:0]
+	for _, id := range ids {
+		incident, ok, err := loadIncident(id)
+		if err != nil {
+			slog.Error("Error loading incident", "incident_id", id, "error", err)
+		}
+		if !ok || incident.Endpoint != endpoint || incident.Console != console {
+			if ok {
+				kept = append(kept, id)
+			}
+			continue
+		}
+		incident.Resolved = now
+		if err := saveIncident(incident); err != nil {
+			slog.Error("Error saving incident", "incident_id", id, "error", err)
+		}
+		resolved = incident
+	}
+	if err := saveOpenIncidentIDs(kept); err != nil {
+		slog.Error("Error saving open incidents", "error", err)
+	}
+	return resolved
+}
+
+// recentIncidents возвращает до limit последних инцидентов, новые первыми
+func recentIncidents(limit int) ([]Incident, error) {
+	seq, err := store.State(incidentSeqKey)
+	if err != nil {
+		return nil, err
+	}
+	last, _ := strconv.Atoi(seq)
+
+	var incidents []Incident
+	for id := last; id > 0 && len(incidents) < limit; id-- {
+		incident, ok, err := loadIncident(id)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			incidents = append(incidents, incident)
+		}
+	}
+	return incidents, nil
+}
+
+// formatIncident — строка об инциденте для /incidents
+func formatIncident(lang string, incident Incident, now time.Time) string {
+	line := tr(lang, "incidents.line", incident.ID, consoleName(lang, incident.Console), incident.Endpoint,
+		incident.Started.Local().Format("02.01 15:04"))
+	if incident.open() {
+		line += " " + tr(lang, "incidents.ongoing", formatDuration(lang, now.Sub(incident.Started)))
+	} else {
+		line += " " + tr(lang, "incidents.lasted", formatDuration(lang, incident.Resolved.Sub(incident.Started)))
+	}
+	if incident.AckedBy != "" {
+		line += ", " + tr(lang, "incidents.acked", incident.AckedBy)
+	}
+	return line
+}
+
+// handleIncidents обрабатывает /incidents — открытые и последние закрытые инциденты
+func handleIncidents(chatID int64) {
+	lang := chatLang(chatID)
+	active, err := openIncidents()
+	if err != nil {
+		slog.Error("Error loading incidents", "error", err)
+		reply(chatID, "error.load")
+		return
+	}
+	recent, err := recentIncidents(maxRecentIncidents)
+	if err != nil {
+		slog.Error("Error loading incidents", "error", err)
+		reply(chatID, "error.load")
+		return
+	}
+
+	now := time.Now()
+	var open, closed []string
+	for _, incident := range active {
+		open = append(open, formatIncident(lang, incident, now))
+	}
+	// Открытые уже перечислены выше, в недавних остаются только закрытые
+	for _, incident := range recent {
+		if !incident.open() {
+			closed = append(closed, formatIncident(lang, incident, now))
+		}
+	}
+
+	var sections []string
+	if len(open) > 0 {
+		sections = append(sections, tr(lang, "incidents.open", strings.Join(open, "\n")))
+	} else {
+		sections = append(sections, tr(lang, "incidents.none_open"))
+	}
+	if len(closed) > 0 {
+		sections = append(sections, tr(lang, "incidents.recent", strings.Join(closed, "\n")))
+	}
+	reply(chatID, "incidents.header", strings.Join(sections, "\n\n"))
+}
+
+// updateIncident изменяет инцидент под общим мьютексом
+func updateIncident(id int, fn func(incident *Incident) bool) (Incident, bool, error) {
+	incidentsMutex.Lock()
+	defer incidentsMutex.Unlock()
+
+	incident, ok, err := loadIncident(id)
+	if err != nil || !ok {
+		return incident, false, err
+	}
+	if !fn(&incident) {
+		return incident, false, nil
+	}
+	return incident, true, saveIncident(incident)
+}
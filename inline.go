@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"status-bot/monitor"
+	"strings"
+)
+
+const (
+	// inlineCacheTime — сколько секунд Telegram хранит ответ на inline-запрос
+	inlineCacheTime = 30
+	// maxInlineResults — больше результатов Telegram не принимает
+	maxInlineResults = 50
+)
+
+// handleInlineQuery отвечает на «@бот [консоль]» в любом чате карточками со
+// сводкой статуса и с отдельными консолями, подходящими под запрос. Чат, куда
+// отправляют карточку, не обязан быть подписан. Статусы берутся из последней
+// проверки, без запроса к API. Inline-режим включается у @BotFather (/setinline).
+func handleInlineQuery(ctx context.Context, query *tgbotapi.InlineQuery) {
+	lang := inlineLang(query.From)
+	filter := strings.ToLower(strings.TrimSpace(query.Query))
+
+	var sections []string
+	var consoles []inlineConsole
+	online, down := 0, 0
+	for _, endpoint := range cfg().endpoints() {
+		statuses, err := monitor.ParseStatuses(statusEngine().Last(endpoint.Name))
+		if err != nil {
+			slog.Error("Error parsing last status", "endpoint", endpoint.Name, "error", err)
+		}
+		sections = append(sections, trMarkup(lang, "status.current", escapeText(endpoint.Name), formatConsoles(lang, statuses)))
+		for _, console := range statuses {
+			if isDown(console.Status) {
+				down++
+			} else {
+				online++
+			}
+			if filter == "" || strings.Contains(strings.ToLower(console.Name), filter) || strings.Contains(strings.ToLower(consoleName(lang, console.Name)), filter) {
+				consoles = append(consoles, inlineConsole{endpoint.Name, console})
+			}
+		}
+	}
+
+	results := []interface{}{inlineArticle("summary", tr(lang, "inline.summary"), tr(lang, "inline.summary.description", online, down), strings.Join(sections, "\n\n"))}
+	for i, c := range consoles {
+		if len(results) == maxInlineResults {
+			break
+		}
+		name := consoleName(lang, c.console.Name)
+		title := name
+		if len(cfg().endpoints()) > 1 {
+			title = fmt.Sprintf("[%s] %s", c.endpoint, name)
+		}
+		text := trMarkup(lang, "inline.console", escapeText(c.endpoint), bold(name), formatStatus(orDash(c.console.Status)))
+		results = append(results, inlineArticle(fmt.Sprintf("console:%d", i), title, orDash(c.console.Status), text))
+	}
+
+	answer := tgbotapi.InlineConfig{InlineQueryID: query.ID, Results: results, CacheTime: inlineCacheTime}
+	if _, err := bot.Request(answer); err != nil {
+		slog.Error("Error answering inline query", "user_id", query.From.ID, "error", err)
+	}
+}
+
+type inlineConsole struct {
+	endpoint string
+	console  monitor.ConsoleStatus
+}
+
+func inlineArticle(id, title, description, text string) tgbotapi.InlineQueryResultArticle {
+	article := tgbotapi.NewInlineQueryResultArticle(id, title, text)
+	article.Description = description
+	article.InputMessageContent = tgbotapi.InputTextMessageContent{Text: text, ParseMode: telegramParseMode()}
+	return article
+}
+
+// inlineLang выбирает язык карточек: язык из настроек личного чата с ботом,
+// затем язык клиента Telegram, затем язык по умолчанию
+func inlineLang(user *tgbotapi.User) string {
+	if user == nil {
+		return cfg().DefaultLanguage
+	}
+	settings, err := store.ChatSettings(user.ID)
+	if err != nil {
+		slog.Error("Error loading chat settings", "chat_id", user.ID, "error", err)
+	}
+	if settings[languageSetting] == "" && catalogs[user.LanguageCode] != nil {
+		return user.LanguageCode
+	}
+	return settingsLang(settings)
+}
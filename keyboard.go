@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+)
+
+// Данные кнопок главного меню
+const (
+	callbackSubscribe   = "subscribe"
+	callbackUnsubscribe = "unsubscribe"
+	callbackStatus      = "status"
+)
+
+// mainKeyboard — кнопки подписки, отписки и запроса статуса
+func mainKeyboard(lang string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(lang, "button.subscribe"), callbackSubscribe),
+			tgbotapi.NewInlineKeyboardButtonData(tr(lang, "button.unsubscribe"), callbackUnsubscribe),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(tr(lang, "button.status"), callbackStatus),
+		),
+	)
+}
+
+// sendWithKeyboard отправляет сообщение с кнопками главного меню
+func sendWithKeyboard(chatID int64, key string) {
+	lang := chatLang(chatID)
+	msg := tgbotapi.NewMessage(chatID, tr(lang, key))
+	msg.ReplyMarkup = mainKeyboard(lang)
+	bot.Send(msg)
+}
+
+// handleSubscribeButton подписывает чат по кнопке главного меню
+func handleSubscribeButton(ctx context.Context, query *tgbotapi.CallbackQuery, payload string) {
+	if query.Message == nil {
+		bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+	lang := chatLang(chatID)
+	if err := store.AddChat(chatID); err != nil {
+		slog.Error("Error saving chat", "chat_id", chatID, "error", err)
+		bot.Request(tgbotapi.NewCallback(query.ID, tr(lang, "error.save")))
+		return
+	}
+	bot.Request(tgbotapi.NewCallback(query.ID, tr(lang, "button.subscribed")))
+	reply(chatID, "start")
+}
+
+// handleUnsubscribeButton отписывает чат по кнопке главного меню
+func handleUnsubscribeButton(ctx context.Context, query *tgbotapi.CallbackQuery, payload string) {
+	if query.Message == nil {
+		bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+
+	chatID := query.Message.Chat.ID
+	lang := chatLang(chatID)
+	if err := store.RemoveChat(chatID); err != nil {
+		slog.Error("Error removing chat", "chat_id", chatID, "error", err)
+		bot.Request(tgbotapi.NewCallback(query.ID, tr(lang, "error.save")))
+		return
+	}
+	bot.Request(tgbotapi.NewCallback(query.ID, tr(lang, "button.unsubscribed")))
+	reply(chatID, "stop")
+}
+
+// handleStatusButton отправляет текущий статус по кнопке главного меню
+func handleStatusButton(ctx context.Context, query *tgbotapi.CallbackQuery, payload string) {
+	bot.Request(tgbotapi.NewCallback(query.ID, ""))
+	if query.Message != nil {
+		sendCurrentStatus(ctx, query.Message.Chat.ID)
+	}
+}
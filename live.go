@@ -0,0 +1,175 @@
+package main
+
+import (
+	"errors"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"net/http"
+	"status-bot/monitor"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	liveSetting        = "live"         // Закреплённая сводка статусов: "on" — вместе с уведомлениями, "only" — вместо них
+	liveMessageSetting = "live_message" // ID закреплённого сообщения со сводкой
+)
+
+// Режимы закреплённой сводки
+const (
+	liveOn   = "on"
+	liveOnly = "only"
+)
+
+// liveMutex не даёт двум событиям одновременно пересоздать сводку чата
+var liveMutex sync.Mutex
+
+// liveOnlyMode сообщает, что чат получает изменения только в закреплённой сводке
+func liveOnlyMode(settings map[string]string) bool {
+	return settings[liveSetting] == liveOnly
+}
+
+// handleLive обрабатывает /live, /live on, /live only и /live off
+func handleLive(chatID int64, args string) {
+	settings, err := store.ChatSettings(chatID)
+	if err != nil {
+		slog.Error("Error loading chat settings", "chat_id", chatID, "error", err)
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(args))
+	switch mode {
+	case "":
+		key := "live.none"
+		switch settings[liveSetting] {
+		case liveOn:
+			key = "live.current_on"
+		case liveOnly:
+			key = "live.current_only"
+		}
+		reply(chatID, key)
+	case liveOn, liveOnly:
+		if err := store.SetChatSetting(chatID, liveSetting, mode); err != nil {
+			slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+			reply(chatID, "error.save")
+			return
+		}
+		chat, _, err := store.Chat(chatID)
+		if err != nil {
+			slog.Error("Error loading chat", "chat_id", chatID, "error", err)
+			reply(chatID, "error.load")
+			return
+		}
+		chat.ID = chatID
+		if !updateLiveDashboard(chat) {
+			reply(chatID, "live.error")
+			return
+		}
+		reply(chatID, "live."+mode)
+	case "off":
+		if id, err := strconv.Atoi(settings[liveMessageSetting]); err == nil {
+			// Сообщение остаётся в истории чата, но больше не обновляется
+			bot.Request(tgbotapi.UnpinChatMessageConfig{ChatID: chatID, MessageID: id})
+		}
+		for _, key := range []string{liveSetting, liveMessageSetting} {
+			if err := store.SetChatSetting(chatID, key, ""); err != nil {
+				slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+				reply(chatID, "error.save")
+				return
+			}
+		}
+		reply(chatID, "live.off")
+	default:
+		reply(chatID, "live.usage")
+	}
+}
+
+// updateLiveDashboard обновляет закреплённую сводку чата, а если её нет или
+// её удалили — отправляет и закрепляет новую. Возвращает false, если сводку
+// не удалось ни обновить, ни создать.
+func updateLiveDashboard(chat Chat) bool {
+	liveMutex.Lock()
+	defer liveMutex.Unlock()
+
+	// Настройки могли измениться после загрузки списка чатов
+	settings, err := store.ChatSettings(chat.ID)
+	if err != nil {
+		slog.Error("Error loading chat settings", "chat_id", chat.ID, "error", err)
+		return false
+	}
+	if settings[liveSetting] == "" {
+		return true
+	}
+	text := liveText(settingsLang(settings), chat.Consoles)
+
+	if id, err := strconv.Atoi(settings[liveMessageSetting]); err == nil {
+		edit := tgbotapi.NewEditMessageText(chat.ID, id, text)
+		edit.ParseMode = telegramParseMode()
+		_, err := bot.Request(edit)
+		if err == nil || isNotModified(err) {
+			return true
+		}
+		if !isMessageMissing(err) {
+			slog.Error("Error updating live dashboard", "chat_id", chat.ID, "message_id", id, "error", err)
+			return false
+		}
+		slog.Warn("Live dashboard message is gone, sending a new one", "chat_id", chat.ID, "message_id", id)
+	}
+
+	msg := newMarkupMessage(chat.ID, text)
+	msg.DisableNotification = true
+	sent, err := sendToTopic(msg, chatTopic(settings, ""))
+	if err != nil {
+		slog.Error("Error sending live dashboard", "chat_id", chat.ID, "error", err)
+		return false
+	}
+	if err := store.SetChatSetting(chat.ID, liveMessageSetting, strconv.Itoa(sent.MessageID)); err != nil {
+		slog.Error("Error saving chat settings", "chat_id", chat.ID, "error", err)
+	}
+	// Без права закреплять сводка всё равно обновляется, просто не закреплена
+	pin := tgbotapi.PinChatMessageConfig{ChatID: chat.ID, MessageID: sent.MessageID, DisableNotification: true}
+	if _, err := bot.Request(pin); err != nil {
+		slog.Warn("Error pinning live dashboard", "chat_id", chat.ID, "error", err)
+	}
+	return true
+}
+
+// liveText — текущие статусы консолей, на которые подписан чат, по всем API
+func liveText(lang string, subscribed []string) string {
+	sections := make([]string, 0, len(cfg().endpoints())+1)
+	for _, endpoint := range cfg().endpoints() {
+		consoles, err := monitor.ParseStatuses(statusEngine().Last(endpoint.Name))
+		if err != nil {
+			slog.Error("Error parsing last status", "endpoint", endpoint.Name, "error", err)
+		}
+		if len(subscribed) > 0 {
+			filtered := consoles[:0:0]
+			for _, console := range consoles {
+				if containsString(subscribed, console.Name) {
+					filtered = append(filtered, console)
+				}
+			}
+			consoles = filtered
+		}
+		sections = append(sections, trMarkup(lang, "status.current", escapeText(endpoint.Name), formatConsoles(lang, consoles)))
+	}
+	sections = append(sections, trMarkup(lang, "live.updated", escapeText(time.Now().Format("02.01.2006 15:04:05"))))
+	return strings.Join(sections, "\n\n")
+}
+
+// isNotModified сообщает, что текст сводки не изменился с прошлого обновления
+func isNotModified(err error) bool {
+	var tgErr *tgbotapi.Error
+	return errors.As(err, &tgErr) && strings.Contains(tgErr.Message, "message is not modified")
+}
+
+// isMessageMissing сообщает, что сообщение удалили или его больше нельзя редактировать
+func isMessageMissing(err error) bool {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) || tgErr.Code != http.StatusBadRequest {
+		return false
+	}
+	message := strings.ToLower(tgErr.Message)
+	return strings.Contains(message, "message to edit not found") || strings.Contains(message, "message can't be edited")
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LogConfig задаёт уровень и формат журнала
+type LogConfig struct {
+	Level  string `yaml:"level" json:"level"`   // debug, info, warn или error
+	Format string `yaml:"format" json:"format"` // text — ключ=значение, json — по объекту на строку
+
+	File        string   `yaml:"file" json:"file"`                 // Файл журнала; пусто — stderr
+	MaxSize     int      `yaml:"max_size_mb" json:"max_size_mb"`   // Размер файла в мегабайтах, после которого он ротируется
+	MaxBackups  int      `yaml:"max_backups" json:"max_backups"`   // Сколько старых файлов хранить; 0 — без ограничения
+	MaxAge      Duration `yaml:"max_age" json:"max_age"`           // Сколько хранить старые файлы; 0 — без ограничения
+	RotateEvery Duration `yaml:"rotate_every" json:"rotate_every"` // Дополнительно ротировать по времени, например 24h; 0 — только по размеру
+	Compress    bool     `yaml:"compress" json:"compress"`         // Сжимать старые файлы gzip
+}
+
+var (
+	logFile       *lumberjack.Logger // Файл журнала с ротацией; nil — журнал пишется в stderr
+	stopLogRotate chan struct{}      // Останавливает ротацию по времени
+)
+
+func (c LogConfig) validate() error {
+	if _, err := c.level(); err != nil {
+		return err
+	}
+	switch strings.ToLower(c.Format) {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("log.format must be text or json, got %q", c.Format)
+	}
+	if c.File == "" {
+		return nil
+	}
+	if c.MaxSize <= 0 {
+		return fmt.Errorf("log.max_size_mb must be positive")
+	}
+	if c.MaxBackups < 0 {
+		return fmt.Errorf("log.max_backups must not be negative")
+	}
+	if c.MaxAge.Duration < 0 || c.RotateEvery.Duration < 0 {
+		return fmt.Errorf("log.max_age and log.rotate_every must not be negative")
+	}
+	return nil
+}
+
+// level разбирает уровень журнала; пустой означает info
+func (c LogConfig) level() (slog.Level, error) {
+	var level slog.Level
+	if c.Level == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return level, fmt.Errorf("log.level must be debug, info, warn or error, got %q", c.Level)
+	}
+	return level, nil
+}
+
+// setupLogging направляет журнал бота, стандартного log и библиотеки
+// Bot API в slog с заданными уровнем и форматом, в stderr или в файл с ротацией
+func setupLogging(config LogConfig) error {
+	var out io.Writer = os.Stderr
+	if config.File != "" {
+		if err := openLogFile(config); err != nil {
+			return err
+		}
+		out = logFile
+	}
+
+	level, _ := config.level()
+	options := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	if strings.EqualFold(config.Format, "json") {
+		handler = slog.NewJSONHandler(out, options)
+	} else {
+		handler = slog.NewTextHandler(out, options)
+	}
+	slog.SetDefault(slog.New(handler))
+	// Отладочный вывод библиотеки Bot API идёт в тот же журнал
+	tgbotapi.SetLogger(slog.NewLogLogger(handler, slog.LevelDebug))
+	return nil
+}
+
+// openLogFile открывает файл журнала и запускает ротацию по времени. Файл
+// открывается сразу, чтобы ошибка доступа обнаружилась при запуске, а не
+// при первой записи.
+func openLogFile(config LogConfig) error {
+	if err := os.MkdirAll(filepath.Dir(config.File), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(config.File, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	f.Close()
+
+	logFile = &lumberjack.Logger{
+		Filename:   config.File,
+		MaxSize:    config.MaxSize,
+		MaxBackups: config.MaxBackups,
+		MaxAge:     days(config.MaxAge.Duration),
+		Compress:   config.Compress,
+		LocalTime:  true,
+	}
+	if config.RotateEvery.Duration > 0 {
+		stopLogRotate = make(chan struct{})
+		go rotateLogPeriodically(config.File, config.RotateEvery.Duration)
+	}
+	return nil
+}
+
+// days переводит срок хранения в целые дни с округлением вверх
+func days(d time.Duration) int {
+	return int((d + 24*time.Hour - 1) / (24 * time.Hour))
+}
+
+// rotateLogPeriodically начинает новый файл журнала каждые every;
+// пустой файл не ротируется, чтобы не копить пустые архивы
+func rotateLogPeriodically(path string, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopLogRotate:
+			return
+		case <-ticker.C:
+			if info, err := os.Stat(path); err == nil && info.Size() == 0 {
+				continue
+			}
+			if err := logFile.Rotate(); err != nil {
+				slog.Error("Error rotating log file", "error", err)
+			}
+		}
+	}
+}
+
+// closeLogging останавливает ротацию и закрывает файл журнала
+func closeLogging() {
+	if stopLogRotate != nil {
+		close(stopLogRotate)
+	}
+	if logFile != nil {
+		logFile.Close()
+	}
+}
+
+// debugLogging сообщает, что журнал пишет отладочные записи
+func debugLogging() bool {
+	return slog.Default().Enabled(context.Background(), slog.LevelDebug)
+}
+
+// fatal пишет ошибку в журнал и завершает процесс
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -1,186 +1,296 @@
 package main
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
+	"flag"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
-	"io/ioutil"
-	"log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
 	"net/http"
 	"os"
-	"sync"
+	"os/signal"
+	"status-bot/monitor"
+	"strings"
+	"syscall"
 	"time"
 )
 
 var (
-	bot          *tgbotapi.BotAPI
-	chatIDs      = make(map[int64]bool) // Хранит ID чатов, куда нужно отправлять уведомления
-	chatIDsMutex = &sync.Mutex{}        // Мьютекс для безопасного доступа к chatIDs
-)
+	bot     Telegram      // Клиент Bot API
+	botUser tgbotapi.User // Аккаунт бота, известен после авторизации
+	store   Storage       // Подписки чатов и состояние бота
 
-const (
-	apiURL         = "https://4cloud.pro/api.php?method=get-consoles-status"
-	errorResponse  = `[{"Status":"Error"}]`
-	checkInterval  = 5 * time.Second
-	configFileName = "chat_ids.json" // Файл для сохранения chat IDs
+	startTime = time.Now() // Время запуска процесса
 )
 
 func main() {
-	var err error
-	token := os.Getenv("TELEGRAM_BOT_TOKEN")
+	os.Exit(runCLI(os.Args[1:]))
+}
+
+// runServe запускает бота: status-bot serve [-config path] [-dry-run]
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	configPath := flags.String("config", "", "path to YAML or JSON config file")
+	flags.BoolVar(&dryRun, "dry-run", false, "poll and log notifications without sending them or saving state")
+	flags.Parse(args)
+
+	configFile = *configPath
+	config, err := loadConfig(configFile)
+	if err != nil {
+		fatal("Error loading config", "error", err)
+	}
+	setConfig(config)
+	if err := setupLogging(cfg().Log); err != nil {
+		fatal("Error opening log file", "error", err)
+	}
+	defer closeLogging()
+	if err := startVault(cfg().Vault); err != nil {
+		fatal("Error loading secrets from Vault", "error", err)
+	}
+	defer stopVault()
+	if err := startErrorReporting(cfg().ErrorReporting); err != nil {
+		fatal("Error starting error reporting", "error", err)
+	}
+	defer stopErrorReporting()
+	if err := startTracing(cfg().Tracing); err != nil {
+		fatal("Error starting tracing", "error", err)
+	}
+	defer stopTracing()
+
+	token := cfg().botToken()
 	if token == "" {
-		log.Fatal("TELEGRAM_BOT_TOKEN environment variable not set")
+		fatal("Bot token environment variable not set", "env", cfg().TokenEnv)
 	}
 
-	bot, err = tgbotapi.NewBotAPI(token)
+	api, err := newTelegramAPI(token, cfg().Telegram)
 	if err != nil {
-		log.Panic(err)
+		fatal("Error creating Telegram client", "error", err)
 	}
 
-	bot.Debug = true
-	log.Printf("Authorized on account %s", bot.Self.UserName)
+	api.Debug = debugLogging()
+	bot, botUser = api, api.Self
+	slog.Info("Authorized", "account", botUser.UserName)
 
-	// Загружаем сохранённые chat IDs
-	loadChatIDs()
+	// Открываем хранилище подписок
+	store, err = openStorage(*cfg())
+	if err != nil {
+		fatal("Error opening storage", "error", err)
+	}
+	defer store.Close()
+	if dryRun {
+		store = newDryRunStorage(store)
+		bot = dryRunBot{bot}
+		slog.Info("Dry run: notifications are logged instead of sent, state is not saved")
+	}
 
-	// Запускаем проверку статуса в фоне
-	go checkStatusPeriodically()
+	apiClient = newAPIClient(cfg().HTTPClient, nil)
+	next, err := buildRuntime(*cfg())
+	if err != nil {
+		fatal("Error preparing config", "error", err)
+	}
+	setRuntime(next)
 
-	// Настраиваем обработчик сообщений
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-	updates := bot.GetUpdatesChan(u)
+	// Загружаем последний известный статус, чтобы перезапуск не считался изменением
+	loadLastStatuses()
 
-	for update := range updates {
-		if update.Message == nil {
-			continue
-		}
+	registerHandlers(router)
+	registerBotCommands(nil)
+	setupWebAppMenu()
 
-		chatID := update.Message.Chat.ID
-		msgText := update.Message.Text
+	// Корневой контекст отменяется по SIGINT/SIGTERM и останавливает все циклы
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-		if msgText == "/start" {
-			// Добавляем чат в список для уведомлений
-			addChatID(chatID)
-			saveChatIDs()
+	// SIGHUP перечитывает конфигурацию
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
 
-			msg := tgbotapi.NewMessage(chatID, "Теперь вы будете получать уведомления о статусе консолей.")
-			bot.Send(msg)
-		} else if msgText == "/stop" {
-			// Удаляем чат из списка для уведомлений
-			removeChatID(chatID)
-			saveChatIDs()
+	startSender(cfg().Sender)
+	startDispatcher(cfg().Notifiers)
 
-			msg := tgbotapi.NewMessage(chatID, "Вы больше не будете получать уведомления о статусе консолей.")
-			bot.Send(msg)
-		}
+	var servers []*http.Server
+	if srv := startHTTPServer(cfg().HTTPListen); srv != nil {
+		servers = append(servers, srv)
+	}
+	if srv := startPprofServer(cfg().PprofListen); srv != nil {
+		servers = append(servers, srv)
+	}
+	grpcSrv, err := startGRPCServer(cfg().GRPC)
+	if err != nil {
+		fatal("Error starting gRPC server", "error", err)
 	}
-}
 
-func checkStatusPeriodically() {
-	for {
-		status, err := getAPIStatus()
+	// Запускаем проверку статуса каждого API в фоне
+	startEngine(ctx)
+	startUserMonitors(ctx)
+
+	// Отложенные уведомления, регулярные сводки и задачи из конфигурации
+	startScheduler()
+
+	// Настраиваем получение обновлений
+	var updates tgbotapi.UpdatesChannel
+	switch {
+	case dryRun:
+		// Канал остаётся nil: бот только опрашивает API до сигнала остановки
+	case cfg().Mode == "webhook":
+		var srv *http.Server
+		updates, srv, err = startWebhook(cfg().Webhook)
 		if err != nil {
-			log.Printf("Error getting status: %v", err)
-			time.Sleep(checkInterval)
-			continue
+			fatal("Error starting webhook", "error", err)
 		}
+		servers = append(servers, srv)
+	default:
+		updates = startPolling()
+	}
 
-		if status != errorResponse {
-			notifyChats(status)
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-hangup:
+			if err := reloadConfig(ctx); err != nil {
+				slog.Error("Error reloading config", "error", err)
+			}
+		case update, ok := <-updates:
+			if !ok {
+				break loop
+			}
+			router.Dispatch(ctx, update)
 		}
-
-		time.Sleep(checkInterval)
 	}
-}
 
-func getAPIStatus() (string, error) {
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		return "", err
+	slog.Info("Shutting down")
+	if cfg().Mode != "webhook" && !dryRun {
+		bot.StopReceivingUpdates()
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return "", err
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, srv := range servers {
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Error shutting down HTTP server", "error", err)
+		}
 	}
+	stopGRPCServer(shutdownCtx, grpcSrv)
+
+	// Дожидаемся проверок, чтобы они успели сохранить состояние до закрытия хранилища,
+	// и дорассылаем уже поставленные в очередь уведомления
+	stopEngineAndWait()
+	stopUserMonitors()
+	stopDispatcher()
+	stopScheduler()
+	stopSender()
+	return nil
+}
 
-	// Нормализуем JSON для сравнения
-	var data interface{}
-	if err := json.Unmarshal(body, &data); err != nil {
-		return "", err
+// newEngine создаёт monitor.Engine для всех API из конфигурации: пороги
+// и подтверждение изменений применяются до сравнения, изменения
+// сохраняются и рассылаются, проверки учитываются в метриках и /healthz
+func newEngine(config Config, sources map[string]monitor.Source) *monitor.Engine {
+	endpoints := config.endpoints()
+	byName := make(map[string]Endpoint, len(endpoints))
+	targets := make([]monitor.Target, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		byName[endpoint.Name] = endpoint
+		targets = append(targets, monitor.Target{Name: endpoint.Name, Source: sources[endpoint.Name], Interval: endpoint.PollInterval.Duration})
 	}
 
-	normalized, err := json.Marshal(data)
-	if err != nil {
-		return "", err
+	engine := monitor.NewEngine(targets)
+	engine.Backoff = config.Backoff.monitor()
+	engine.Prepare = func(name string, consoles []monitor.ConsoleStatus) []monitor.ConsoleStatus {
+		return applyThresholds(name, consoles, time.Now())
 	}
-
-	return string(normalized), nil
+	engine.Confirm = confirmStatuses
+	engine.OnChange = func(ctx context.Context, name, prev, status string) {
+		defer recoverPanic("status change", map[string]string{"endpoint": name})
+		if err := store.SetState(lastStatusKey(name), status); err != nil {
+			slog.Error("Error saving last status", "endpoint", name, "error", err)
+		}
+		notifyChats(ctx, byName[name], prev, status)
+	}
+	engine.OnCheck = func(check monitor.Check) {
+		if check.Polled {
+			pollAttempts.WithLabelValues(check.Target).Inc()
+			apiLatency.WithLabelValues(check.Target).Observe(check.Latency.Seconds())
+		}
+		recordCheck(check.Target, check.Err)
+		if check.Err != nil {
+			pollFailures.WithLabelValues(check.Target).Inc()
+		}
+		reportRepeated("poll:"+check.Target, "Error getting status from "+check.Target, check.Err, map[string]string{"endpoint": check.Target})
+	}
+	return engine
 }
 
-func notifyChats(status string) {
-	chatIDsMutex.Lock()
-	defer chatIDsMutex.Unlock()
-
-	for chatID := range chatIDs {
-		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("Статус изменился:\n%s", status))
-		_, err := bot.Send(msg)
+func sendCurrentStatus(ctx context.Context, chatID int64) {
+	lang := chatLang(chatID)
+	endpoints := cfg().endpoints()
+	sections := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		consoles, err := endpointSource(endpoint.Name).Fetch(ctx)
 		if err != nil {
-			log.Printf("Error sending message to chat %d: %v", chatID, err)
+			slog.Error("Error getting status for chat", "endpoint", endpoint.Name, "chat_id", chatID, "error", err)
+			sections = append(sections, trMarkup(lang, "status.failed", escapeText(endpoint.Name)))
+			continue
 		}
+		sections = append(sections, trMarkup(lang, "status.current", escapeText(endpoint.Name), formatConsoles(lang, consoles)))
 	}
-}
 
-func addChatID(chatID int64) {
-	chatIDsMutex.Lock()
-	defer chatIDsMutex.Unlock()
-	chatIDs[chatID] = true
+	sendNow(chatID, newMarkupMessage(chatID, strings.Join(sections, "\n\n")))
 }
 
-func removeChatID(chatID int64) {
-	chatIDsMutex.Lock()
-	defer chatIDsMutex.Unlock()
-	delete(chatIDs, chatID)
-}
-
-func saveChatIDs() {
-	chatIDsMutex.Lock()
-	defer chatIDsMutex.Unlock()
+func notifyChats(ctx context.Context, endpoint Endpoint, prev, status string) {
+	ctx, span := tracer.Start(ctx, "notify.changes", trace.WithAttributes(attribute.String("endpoint", endpoint.Name)))
+	defer span.End()
 
-	data, err := json.Marshal(chatIDs)
+	_, diffSpan := tracer.Start(ctx, "notify.diff")
+	changes, err := monitor.DiffPayloads(prev, status)
+	diffSpan.SetAttributes(attribute.Int("changes", len(changes)))
+	endSpan(diffSpan, err)
 	if err != nil {
-		log.Printf("Error marshaling chat IDs: %v", err)
-		return
+		// Ответ не похож на массив консолей — отправляем его целиком
+		slog.Error("Error computing status diff", "endpoint", endpoint.Name, "error", err)
 	}
 
-	err = ioutil.WriteFile(configFileName, data, 0644)
-	if err != nil {
-		log.Printf("Error saving chat IDs to file: %v", err)
-	}
-}
+	now := time.Now()
+	transitions := trackTransitions(endpoint.Name, changes, now)
+	recordHistory(endpoint.Name, changes, now)
 
-func loadChatIDs() {
-	data, err := ioutil.ReadFile(configFileName)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return // Файл ещё не создан
-		}
-		log.Printf("Error reading chat IDs file: %v", err)
+	// Мигающие консоли получают одно уведомление вместо потока изменений
+	changes, flapping := detectFlapping(endpoint.Name, changes, now)
+	notifyFlappingStarted(endpoint, flapping)
+
+	// Изменения консолей на обслуживании попадают только в историю
+	changes = filterMaintenance(endpoint.Name, changes, now)
+
+	// Правила решают, о каких изменениях уведомлять и насколько они важны
+	changes, severities := applyRules(endpoint.Name, changes, transitions)
+	severities = applyStatusSeverities(changes, severities)
+	recordIncidentSeverities(transitions, changes, severities)
+	if err == nil && len(changes) == 0 {
 		return
 	}
-
-	var loadedChatIDs map[int64]bool
-	err = json.Unmarshal(data, &loadedChatIDs)
 	if err != nil {
-		log.Printf("Error unmarshaling chat IDs: %v", err)
-		return
+		changes = nil
 	}
 
-	chatIDsMutex.Lock()
-	defer chatIDsMutex.Unlock()
-	for id, val := range loadedChatIDs {
-		chatIDs[id] = val
+	span.SetAttributes(attribute.Int("notify.changes", len(changes)))
+	dispatchEvent(ctx, StatusEvent{Endpoint: endpoint, Time: now, Status: status, Changes: changes, Transitions: transitions, Severities: severities})
+}
+
+// loadLastStatuses загружает последний известный ответ каждого API
+func loadLastStatuses() {
+	for _, endpoint := range cfg().endpoints() {
+		status, err := store.State(lastStatusKey(endpoint.Name))
+		if err != nil {
+			slog.Error("Error loading last status", "endpoint", endpoint.Name, "error", err)
+			continue
+		}
+		if status != "" {
+			statusEngine().SetLast(endpoint.Name, status)
+		}
 	}
 }
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"status-bot/monitor"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maintenanceStateKey — ключ состояния со списком окон обслуживания
+const maintenanceStateKey = "maintenance"
+
+// maintenanceWindow — окно обслуживания, в которое изменения консолей
+// записываются в историю, но не рассылаются
+type maintenanceWindow struct {
+	ID       int      `json:"id"`
+	Day      string   `json:"day"`      // Пусто — каждый день, mon…sun — раз в неделю, 2006-01-02 — один раз
+	Hours    string   `json:"hours"`    // Интервал вида "02:00-04:00"; может переходить через полночь
+	Consoles []string `json:"consoles"` // Затронутые консоли; пусто — все
+	Silent   bool     `json:"silent"`   // Не отправлять сводку после окончания окна
+}
+
+// maintenanceChange — изменение, скрытое окном обслуживания
+type maintenanceChange struct {
+	endpoint string
+	change   monitor.StatusChange
+}
+
+// suppressedChanges — изменения, скрытые одним окном, до его окончания
+type suppressedChanges struct {
+	window  maintenanceWindow
+	changes []maintenanceChange
+}
+
+var (
+	maintenanceMutex = &sync.Mutex{}
+	suppressed       = make(map[int]*suppressedChanges) // По ID окна
+)
+
+// parseMaintenanceWhen разбирает "sat 02:00-04:00", "2026-10-20 02:00-04:00" или "02:00-04:00"
+func parseMaintenanceWhen(s string) (day string, hours quietHours, err error) {
+	fields := strings.Fields(strings.ToLower(s))
+	switch len(fields) {
+	case 1:
+	case 2:
+		day = fields[0]
+		if _, ok := weekdays[day]; !ok {
+			if _, err := time.Parse("2006-01-02", day); err != nil {
+				return "", quietHours{}, fmt.Errorf("expected weekday or date, got %q", day)
+			}
+		}
+	default:
+		return "", quietHours{}, fmt.Errorf("expected [DAY] HH:MM-HH:MM")
+	}
+	hours, err = parseQuietHours(fields[len(fields)-1])
+	return day, hours, err
+}
+
+// when описывает окно так же, как его задают в /maintenance add
+func (w maintenanceWindow) when() string {
+	if w.Day == "" {
+		return w.Hours
+	}
+	return w.Day + " " + w.Hours
+}
+
+// matchesDay сообщает, подходит ли день t под поле Day окна
+func (w maintenanceWindow) matchesDay(t time.Time) bool {
+	switch {
+	case w.Day == "":
+		return true
+	case len(w.Day) == 3:
+		return weekdays[w.Day] == t.Weekday()
+	}
+	return t.Format("2006-01-02") == w.Day
+}
+
+// active сообщает, действует ли окно в момент t. Для окна через полночь
+// время после полуночи относится к предыдущему дню.
+func (w maintenanceWindow) active(t time.Time) bool {
+	_, hours, err := parseMaintenanceWhen(w.when())
+	if err != nil || !hours.contains(t) {
+		return false
+	}
+	minutes := t.Hour()*60 + t.Minute()
+	if hours.start > hours.end && minutes < hours.end {
+		return w.matchesDay(t.AddDate(0, 0, -1))
+	}
+	return w.matchesDay(t)
+}
+
+// expired сообщает, что разовое окно уже никогда не наступит
+func (w maintenanceWindow) expired(t time.Time) bool {
+	day, err := time.ParseInLocation("2006-01-02", w.Day, t.Location())
+	if err != nil {
+		return false
+	}
+	// Окно через полночь заканчивается на следующий день
+	return t.After(day.AddDate(0, 0, 2)) || (t.After(day.AddDate(0, 0, 1)) && !w.active(t))
+}
+
+func (w maintenanceWindow) covers(console string) bool {
+	return len(w.Consoles) == 0 || containsString(w.Consoles, console)
+}
+
+// loadMaintenance читает окна обслуживания из хранилища
+func loadMaintenance() ([]maintenanceWindow, error) {
+	value, err := store.State(maintenanceStateKey)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	var windows []maintenanceWindow
+	if err := json.Unmarshal([]byte(value), &windows); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+func saveMaintenance(windows []maintenanceWindow) error {
+	if len(windows) == 0 {
+		return store.SetState(maintenanceStateKey, "")
+	}
+	data, err := json.Marshal(windows)
+	if err != nil {
+		return err
+	}
+	return store.SetState(maintenanceStateKey, string(data))
+}
+
+// filterMaintenance убирает из рассылки изменения консолей, для которых сейчас
+// действует окно обслуживания, и запоминает их для сводки после окна
+func filterMaintenance(endpoint string, changes []monitor.StatusChange, now time.Time) []monitor.StatusChange {
+	windows, err := loadMaintenance()
+	if err != nil {
+		slog.Error("Error loading maintenance windows", "error", err)
+		return changes
+	}
+	if len(windows) == 0 {
+		return changes
+	}
+
+	maintenanceMutex.Lock()
+	defer maintenanceMutex.Unlock()
+
+	notify := changes[:0:0]
+	for _, change := range changes {
+		hidden := false
+		for _, w := range windows {
+			if w.active(now) && w.covers(change.Name) {
+				if suppressed[w.ID] == nil {
+					suppressed[w.ID] = &suppressedChanges{window: w}
+				}
+				suppressed[w.ID].changes = append(suppressed[w.ID].changes, maintenanceChange{endpoint: endpoint, change: change})
+				hidden = true
+				break
+			}
+		}
+		if !hidden {
+			notify = append(notify, change)
+		}
+	}
+	return notify
+}
+
+// closeMaintenance рассылает сводки по закончившимся окнам и удаляет разовые
+// окна, которые уже прошли
+func closeMaintenance() {
+	windows, err := loadMaintenance()
+	if err != nil {
+		slog.Error("Error loading maintenance windows", "error", err)
+		return
+	}
+
+	now := time.Now()
+	kept := windows[:0:0]
+	for _, w := range windows {
+		if !w.expired(now) {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) != len(windows) {
+		if err := saveMaintenance(kept); err != nil {
+			slog.Error("Error saving maintenance windows", "error", err)
+		}
+	}
+
+	active := make(map[int]maintenanceWindow, len(kept))
+	for _, w := range kept {
+		active[w.ID] = w
+	}
+
+	// Окно закончилось, если оно больше не действует или его удалили
+	maintenanceMutex.Lock()
+	var finished []*suppressedChanges
+	for id, s := range suppressed {
+		if w, ok := active[id]; ok && w.active(now) {
+			continue
+		}
+		delete(suppressed, id)
+		if !s.window.Silent {
+			finished = append(finished, s)
+		}
+	}
+	maintenanceMutex.Unlock()
+
+	for _, s := range finished {
+		sendMaintenanceSummary(s.window, s.changes)
+	}
+}
+
+// sendMaintenanceSummary сообщает подписчикам об изменениях, скрытых окном
+func sendMaintenanceSummary(w maintenanceWindow, changes []maintenanceChange) {
+	chats, err := store.Chats()
+	if err != nil {
+		slog.Error("Error loading chats", "error", err)
+		return
+	}
+
+	for _, chat := range chats {
+		lang := settingsLang(chat.Settings)
+		byEndpoint := make(map[string][]monitor.StatusChange)
+		var order []string
+		for _, c := range changes {
+			if len(chat.Consoles) > 0 && !containsString(chat.Consoles, c.change.Name) {
+				continue
+			}
+			if consoleMuted(chat.Settings, c.change.Name, time.Now()) {
+				continue
+			}
+			if byEndpoint[c.endpoint] == nil {
+				order = append(order, c.endpoint)
+			}
+			byEndpoint[c.endpoint] = append(byEndpoint[c.endpoint], c.change)
+		}
+		if len(order) == 0 {
+			continue
+		}
+
+		sections := []string{trMarkup(lang, "maintenance.summary", escapeText(w.when()))}
+		for _, endpoint := range order {
+			sections = append(sections, trMarkup(lang, "history.endpoint", escapeText(endpoint), formatChanges(lang, byEndpoint[endpoint])))
+		}
+		notifyChat(context.Background(), chat, strings.Join(sections, "\n\n"), false, nil)
+	}
+}
+
+// handleMaintenance обрабатывает /maintenance add "<когда>" [консоли…] [silent],
+// /maintenance remove <id> и /maintenance без аргументов (список окон)
+func handleMaintenance(chatID int64, args string) {
+	command, rest := splitCommandArgs(args)
+	switch command {
+	case "", "list":
+		listMaintenance(chatID)
+	case "add":
+		addMaintenance(chatID, rest)
+	case "remove", "rm", "del":
+		removeMaintenance(chatID, rest)
+	default:
+		reply(chatID, "maintenance.usage")
+	}
+}
+
+// splitCommandArgs отделяет первое слово аргументов от остальных
+func splitCommandArgs(args string) (string, string) {
+	args = strings.TrimSpace(args)
+	if i := strings.IndexAny(args, " \t\n"); i >= 0 {
+		return strings.ToLower(args[:i]), strings.TrimSpace(args[i+1:])
+	}
+	return strings.ToLower(args), ""
+}
+
+func addMaintenance(chatID int64, args string) {
+	var when string
+	var rest []string
+	if strings.HasPrefix(args, `"`) {
+		end := strings.Index(args[1:], `"`)
+		if end < 0 {
+			reply(chatID, "maintenance.usage")
+			return
+		}
+		when, rest = args[1:end+1], consoleFields(args[end+2:])
+	} else {
+		// Без кавычек: день (если есть) и интервал
+		fields := consoleFields(args)
+		n := 1
+		if len(fields) > 1 && !strings.Contains(fields[0], ":") {
+			n = 2
+		}
+		if len(fields) < n {
+			reply(chatID, "maintenance.usage")
+			return
+		}
+		when, rest = strings.Join(fields[:n], " "), fields[n:]
+	}
+
+	day, hours, err := parseMaintenanceWhen(when)
+	if err != nil {
+		reply(chatID, "maintenance.usage")
+		return
+	}
+	w := maintenanceWindow{Day: day, Hours: hours.String()}
+	for _, field := range rest {
+		if strings.EqualFold(field, "silent") {
+			w.Silent = true
+			continue
+		}
+		w.Consoles = append(w.Consoles, field)
+	}
+
+	w, err = addMaintenanceWindow(w)
+	if err != nil {
+		slog.Error("Error saving maintenance window", "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+	reply(chatID, "maintenance.added", w.ID, describeMaintenance(chatLang(chatID), w))
+}
+
+// addMaintenanceWindow сохраняет новое окно, назначая ему следующий свободный ID
+func addMaintenanceWindow(w maintenanceWindow) (maintenanceWindow, error) {
+	windows, err := loadMaintenance()
+	if err != nil {
+		return w, err
+	}
+	w.ID = 1
+	for _, existing := range windows {
+		if existing.ID >= w.ID {
+			w.ID = existing.ID + 1
+		}
+	}
+	return w, saveMaintenance(append(windows, w))
+}
+
+// removeMaintenanceWindow удаляет окно; false — окна с таким ID нет
+func removeMaintenanceWindow(id int) (bool, error) {
+	windows, err := loadMaintenance()
+	if err != nil {
+		return false, err
+	}
+	kept := windows[:0:0]
+	for _, w := range windows {
+		if w.ID != id {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) == len(windows) {
+		return false, nil
+	}
+	return true, saveMaintenance(kept)
+}
+
+func removeMaintenance(chatID int64, args string) {
+	id, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(args), "#"))
+	if err != nil {
+		reply(chatID, "maintenance.usage")
+		return
+	}
+
+	removed, err := removeMaintenanceWindow(id)
+	if err != nil {
+		slog.Error("Error saving maintenance windows", "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+	if !removed {
+		reply(chatID, "maintenance.not_found", id)
+		return
+	}
+	reply(chatID, "maintenance.removed", id)
+}
+
+func listMaintenance(chatID int64) {
+	windows, err := loadMaintenance()
+	if err != nil {
+		slog.Error("Error loading maintenance windows", "error", err)
+		reply(chatID, "error.load")
+		return
+	}
+	if len(windows) == 0 {
+		reply(chatID, "maintenance.none")
+		return
+	}
+
+	lang := chatLang(chatID)
+	now := time.Now()
+	lines := make([]string, 0, len(windows))
+	for _, w := range windows {
+		line := fmt.Sprintf("#%d %s", w.ID, describeMaintenance(lang, w))
+		if w.active(now) {
+			line += " " + tr(lang, "maintenance.active")
+		}
+		lines = append(lines, line)
+	}
+	reply(chatID, "maintenance.list", strings.Join(lines, "\n"))
+}
+
+// describeMaintenance — «когда: консоли» для ответов на /maintenance
+func describeMaintenance(lang string, w maintenanceWindow) string {
+	consoles := tr(lang, "maintenance.all_consoles")
+	if len(w.Consoles) > 0 {
+		consoles = consoleNames(lang, w.Consoles)
+	}
+	return w.when() + ": " + consoles
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"html"
+	"strings"
+)
+
+// Режимы разметки уведомлений (parse_mode в конфигурации)
+const (
+	parseModeNone       = ""
+	parseModeHTML       = "html"
+	parseModeMarkdownV2 = "markdownv2"
+)
+
+// markdownV2Escaper экранирует все символы, которые MarkdownV2 считает разметкой
+var markdownV2Escaper = strings.NewReplacer(
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+	"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+	"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+func validateParseMode(mode string) error {
+	switch mode {
+	case parseModeNone, parseModeHTML, parseModeMarkdownV2:
+		return nil
+	}
+	return fmt.Errorf("unknown parse_mode %q, expected html or markdownv2", mode)
+}
+
+// escapeText экранирует строку из API, чтобы она не ломала разметку сообщения
+func escapeText(s string) string {
+	switch cfg().ParseMode {
+	case parseModeHTML:
+		return html.EscapeString(s)
+	case parseModeMarkdownV2:
+		return markdownV2Escaper.Replace(s)
+	}
+	return s
+}
+
+// bold экранирует строку и выделяет её жирным
+func bold(s string) string {
+	return wrapBold(escapeText(s))
+}
+
+// code экранирует строку и выводит её моноширинным шрифтом
+func code(s string) string {
+	return wrapCode(escapeText(s))
+}
+
+// wrapBold выделяет жирным уже экранированный текст
+func wrapBold(s string) string {
+	switch cfg().ParseMode {
+	case parseModeHTML:
+		return "<b>" + s + "</b>"
+	case parseModeMarkdownV2:
+		return "*" + s + "*"
+	}
+	return s
+}
+
+// wrapCode выводит моноширинным шрифтом уже экранированный текст
+func wrapCode(s string) string {
+	switch cfg().ParseMode {
+	case parseModeHTML:
+		return "<code>" + s + "</code>"
+	case parseModeMarkdownV2:
+		return "`" + s + "`"
+	}
+	return s
+}
+
+// trMarkup переводит сообщение для отправки с разметкой: текст каталога
+// экранируется, а аргументы подставляются как есть, поэтому они должны быть
+// уже экранированы (escapeText, bold, code)
+func trMarkup(lang, key string, args ...interface{}) string {
+	format := escapeText(tr(lang, key))
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// newMarkupMessage создаёт сообщение с режимом разметки из конфигурации
+func newMarkupMessage(chatID int64, text string) tgbotapi.MessageConfig {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = telegramParseMode()
+	return msg
+}
+
+// telegramParseMode переводит parse_mode из конфигурации в режим Bot API
+func telegramParseMode() string {
+	switch cfg().ParseMode {
+	case parseModeHTML:
+		return tgbotapi.ModeHTML
+	case parseModeMarkdownV2:
+		return tgbotapi.ModeMarkdownV2
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestMarkup(t *testing.T) {
+	defer setConfig(*cfg())
+
+	tests := []struct {
+		mode      string
+		bold      string
+		code      string
+		parseMode string
+	}{
+		{mode: parseModeNone, bold: "a<b>_1.", code: "a<b>_1.", parseMode: ""},
+		{mode: parseModeHTML, bold: "<b>a&lt;b&gt;_1.</b>", code: "<code>a&lt;b&gt;_1.</code>", parseMode: tgbotapi.ModeHTML},
+		{mode: parseModeMarkdownV2, bold: `*a<b\>\_1\.*`, code: "`a<b\\>\\_1\\.`", parseMode: tgbotapi.ModeMarkdownV2},
+	}
+	for _, tt := range tests {
+		t.Run("parse_mode="+tt.mode, func(t *testing.T) {
+			config := defaultConfig()
+			config.ParseMode = tt.mode
+			setConfig(config)
+
+			if got := bold("a<b>_1."); got != tt.bold {
+				t.Errorf("bold() = %q, want %q", got, tt.bold)
+			}
+			if got := code("a<b>_1."); got != tt.code {
+				t.Errorf("code() = %q, want %q", got, tt.code)
+			}
+			if got := newMarkupMessage(1, "x").ParseMode; got != tt.parseMode {
+				t.Errorf("ParseMode = %q, want %q", got, tt.parseMode)
+			}
+			// Разметка снимается обратно без потерь
+			if got := plainText(bold("a<b>_1.")+" "+code("[x]"), tt.parseMode); got != "a<b>_1. [x]" {
+				t.Errorf("plainText() = %q", got)
+			}
+		})
+	}
+}
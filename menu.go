@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"sort"
+	"status-bot/monitor"
+	"strings"
+)
+
+// replyKeyboardSetting — настройка чата: показывать постоянную клавиатуру с командами
+const replyKeyboardSetting = "reply_keyboard"
+
+// callbackHistory — кнопка выбора консоли для истории: "history:<консоль>"
+const callbackHistory = "history"
+
+// menuButton — кнопка постоянной клавиатуры: подпись и действие вместо команды
+type menuButton struct {
+	key    string
+	action func(ctx context.Context, chatID int64)
+}
+
+// menuButtons — ряды постоянной клавиатуры
+var menuButtons = [][]menuButton{
+	{
+		{"menu.status", sendCurrentStatus},
+		{"menu.history", func(ctx context.Context, chatID int64) { chooseHistoryConsole(chatID) }},
+	},
+	{
+		{"menu.settings", func(ctx context.Context, chatID int64) { handleSettings(chatID) }},
+		{"menu.mute", func(ctx context.Context, chatID int64) { handleSnooze(chatID, "1h") }},
+	},
+}
+
+// replyKeyboard — постоянная клавиатура на языке чата
+func replyKeyboard(lang string) tgbotapi.ReplyKeyboardMarkup {
+	rows := make([][]tgbotapi.KeyboardButton, 0, len(menuButtons))
+	for _, buttons := range menuButtons {
+		row := make([]tgbotapi.KeyboardButton, 0, len(buttons))
+		for _, button := range buttons {
+			row = append(row, tgbotapi.NewKeyboardButton(tr(lang, button.key)))
+		}
+		rows = append(rows, row)
+	}
+	keyboard := tgbotapi.NewReplyKeyboard(rows...)
+	keyboard.InputFieldPlaceholder = tr(lang, "menu.placeholder")
+	return keyboard
+}
+
+// handleKeyboard обрабатывает /keyboard, /keyboard on и /keyboard off
+func handleKeyboard(chatID int64, args string) {
+	// Клавиатура мешала бы переписке в группах, поэтому только в личных чатах
+	if chatID < 0 {
+		reply(chatID, "menu.private_only")
+		return
+	}
+
+	lang := chatLang(chatID)
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "", "on":
+		if err := store.SetChatSetting(chatID, replyKeyboardSetting, "on"); err != nil {
+			slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+			reply(chatID, "error.save")
+			return
+		}
+		msg := tgbotapi.NewMessage(chatID, tr(lang, "menu.on"))
+		msg.ReplyMarkup = replyKeyboard(lang)
+		bot.Send(msg)
+	case "off":
+		if err := store.SetChatSetting(chatID, replyKeyboardSetting, ""); err != nil {
+			slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+			reply(chatID, "error.save")
+			return
+		}
+		msg := tgbotapi.NewMessage(chatID, tr(lang, "menu.off"))
+		msg.ReplyMarkup = tgbotapi.NewRemoveKeyboard(false)
+		bot.Send(msg)
+	default:
+		reply(chatID, "menu.usage")
+	}
+}
+
+// handleMenuButton выполняет действие нажатой кнопки постоянной клавиатуры.
+// Подписи сверяются со всеми языками: у клиента может остаться клавиатура,
+// отправленная до смены языка.
+func handleMenuButton(ctx context.Context, message *tgbotapi.Message) bool {
+	if !message.Chat.IsPrivate() || message.IsCommand() || message.Text == "" {
+		return false
+	}
+	for _, buttons := range menuButtons {
+		for _, button := range buttons {
+			for lang := range catalogs {
+				if message.Text == tr(lang, button.key) {
+					button.action(ctx, message.Chat.ID)
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// chooseHistoryConsole предлагает выбрать консоль для /history кнопками
+func chooseHistoryConsole(chatID int64) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, endpoint := range cfg().endpoints() {
+		consoles, _ := monitor.ParseStatuses(statusEngine().Last(endpoint.Name))
+		for _, console := range consoles {
+			// Данные кнопки ограничены 64 байтами
+			if console.Name != "" && !seen[console.Name] && len(callbackHistory)+1+len(console.Name) <= 64 {
+				seen[console.Name] = true
+				names = append(names, console.Name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		reply(chatID, "history.usage")
+		return
+	}
+	sort.Strings(names)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(names); i += 2 {
+		row := []tgbotapi.InlineKeyboardButton{tgbotapi.NewInlineKeyboardButtonData(names[i], callbackHistory+":"+names[i])}
+		if i+1 < len(names) {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(names[i+1], callbackHistory+":"+names[i+1]))
+		}
+		rows = append(rows, row)
+	}
+	msg := tgbotapi.NewMessage(chatID, tr(chatLang(chatID), "menu.choose_console"))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	bot.Send(msg)
+}
+
+// handleHistoryButton показывает историю консоли, выбранной кнопкой
+func handleHistoryButton(ctx context.Context, query *tgbotapi.CallbackQuery, payload string) {
+	bot.Request(tgbotapi.NewCallback(query.ID, ""))
+	if query.Message == nil {
+		return
+	}
+	handleHistory(query.Message.Chat.ID, payload)
+}
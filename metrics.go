@@ -0,0 +1,80 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"log/slog"
+	"net/http"
+)
+
+var (
+	pollAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "status_bot_poll_attempts_total",
+		Help: "Number of status API polls.",
+	}, []string{"endpoint"})
+
+	pollFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "status_bot_poll_failures_total",
+		Help: "Number of status API polls that failed.",
+	}, []string{"endpoint"})
+
+	apiLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "status_bot_api_request_duration_seconds",
+		Help:    "Status API response latency.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	notificationsSent = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "status_bot_notifications_sent_total",
+		Help: "Number of notifications delivered to chats.",
+	})
+
+	sendErrors = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "status_bot_send_errors_total",
+		Help: "Number of notifications Telegram refused to deliver.",
+	})
+
+	eventsDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "status_bot_events_dropped_total",
+		Help: "Number of status events dropped before delivery to a notifier.",
+	}, []string{"notifier"})
+
+	_ = promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "status_bot_subscribers",
+		Help: "Number of subscribed chats.",
+	}, func() float64 {
+		if store == nil {
+			return 0
+		}
+		chats, err := store.Chats()
+		if err != nil {
+			return 0
+		}
+		return float64(len(chats))
+	})
+)
+
+// httpMux — обработчики служебного HTTP-сервера (метрики, проверки здоровья)
+var httpMux = http.NewServeMux()
+
+func init() {
+	httpMux.Handle("/metrics", promhttp.Handler())
+}
+
+// startHTTPServer запускает служебный HTTP-сервер, если задан http_listen.
+// Возвращает nil, если сервер выключен.
+func startHTTPServer(listen string) *http.Server {
+	if listen == "" {
+		return nil
+	}
+
+	srv := &http.Server{Addr: listen, Handler: httpMux}
+	go func() {
+		slog.Info("Serving metrics and health checks", "addr", listen)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fatal("HTTP server stopped", "error", err)
+		}
+	}()
+	return srv
+}
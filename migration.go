@@ -0,0 +1,82 @@
+package main
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+)
+
+// migrateChat переносит подписку, консоли и настройки чата на новый ID.
+// Telegram меняет ID, когда группа становится супергруппой.
+func migrateChat(oldID, newID int64) {
+	if oldID == newID {
+		return
+	}
+
+	chat, ok, err := store.Chat(oldID)
+	if err != nil {
+		slog.Error("Error loading chat for migration", "chat_id", oldID, "error", err)
+		return
+	}
+	settings, err := store.ChatSettings(oldID)
+	if err != nil {
+		slog.Error("Error loading chat settings for migration", "chat_id", oldID, "error", err)
+		return
+	}
+
+	for key, value := range settings {
+		if err := store.SetChatSetting(newID, key, value); err != nil {
+			slog.Error("Error migrating setting", "key", key, "chat_id", oldID, "error", err)
+			return
+		}
+	}
+	if ok {
+		if err := store.AddChat(newID); err != nil {
+			slog.Error("Error migrating chat", "chat_id", oldID, "error", err)
+			return
+		}
+		for _, console := range chat.Consoles {
+			if err := store.AddChatConsole(newID, console); err != nil {
+				slog.Error("Error migrating console subscription", "console", console, "chat_id", oldID, "error", err)
+				return
+			}
+		}
+		if err := store.RemoveChat(oldID); err != nil {
+			slog.Error("Error removing migrated chat", "chat_id", oldID, "error", err)
+		}
+	}
+	for key := range settings {
+		store.SetChatSetting(oldID, key, "")
+	}
+
+	slog.Info("Migrated chat", "chat_id", oldID, "new_chat_id", newID)
+}
+
+// handleMigration обрабатывает служебные сообщения о переходе группы в супергруппу.
+// Возвращает true, если сообщение было служебным.
+func handleMigration(message *tgbotapi.Message) bool {
+	switch {
+	case message.MigrateToChatID != 0:
+		// Приходит в старую группу
+		migrateChat(message.Chat.ID, message.MigrateToChatID)
+		return true
+	case message.MigrateFromChatID != 0:
+		// Приходит в новую супергруппу
+		migrateChat(message.MigrateFromChatID, message.Chat.ID)
+		return true
+	}
+	return false
+}
+
+// withChatID возвращает копию сообщения, адресованную другому чату.
+// Второй результат false, если тип сообщения не поддерживается.
+func withChatID(msg tgbotapi.Chattable, chatID int64) (tgbotapi.Chattable, bool) {
+	switch m := msg.(type) {
+	case tgbotapi.MessageConfig:
+		m.ChatID = chatID
+		return m, true
+	case tgbotapi.DocumentConfig:
+		m.ChatID = chatID
+		return m, true
+	}
+	return nil, false
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"status-bot/monitor"
+	"sync"
+	"time"
+)
+
+// MockScript описывает состояние поддельного API статусов и его изменения во времени
+type MockScript struct {
+	Consoles []MockConsole `yaml:"consoles" json:"consoles"` // Консоли и их статусы на старте
+	Steps    []MockStep    `yaml:"steps" json:"steps"`       // Изменения по порядку
+	Loop     bool          `yaml:"loop" json:"loop"`         // Начинать сценарий заново после последнего шага
+}
+
+// MockConsole — консоль поддельного API
+type MockConsole struct {
+	Name   string `yaml:"name" json:"name"`
+	Status string `yaml:"status" json:"status"`
+}
+
+// MockStep — изменение состояния через After после предыдущего шага
+type MockStep struct {
+	After  Duration          `yaml:"after" json:"after"`   // Пауза перед шагом
+	Set    map[string]string `yaml:"set" json:"set"`       // Новые статусы консолей; новая консоль добавляется
+	Remove []string          `yaml:"remove" json:"remove"` // Пропавшие консоли
+	Error  *bool             `yaml:"error" json:"error"`   // true — отвечать error_response, false — снова отвечать статусами
+}
+
+// defaultMockScript — сценарий без файла: две консоли, одна раз в минуту падает и поднимается
+var defaultMockScript = MockScript{
+	Consoles: []MockConsole{{Name: "console-1", Status: "OK"}, {Name: "console-2", Status: "OK"}},
+	Steps: []MockStep{
+		{After: Duration{30 * time.Second}, Set: map[string]string{"console-2": "Error"}},
+		{After: Duration{30 * time.Second}, Set: map[string]string{"console-2": "OK"}},
+	},
+	Loop: true,
+}
+
+// mockAPI — текущее состояние поддельного API
+type mockAPI struct {
+	mutex    sync.Mutex
+	consoles []monitor.ConsoleStatus
+	failing  bool
+}
+
+// runMockAPI запускает поддельное API статусов для локальной разработки:
+// status-bot mockapi [-listen :8081] [-script mock.yaml]
+func runMockAPI(args []string) error {
+	flags := flag.NewFlagSet("mockapi", flag.ExitOnError)
+	listen := flags.String("listen", ":8081", "address to serve the mock status API on")
+	scriptPath := flags.String("script", "", "YAML or JSON file with consoles and state transitions")
+	flags.Parse(args)
+
+	script := defaultMockScript
+	if *scriptPath != "" {
+		data, err := ioutil.ReadFile(*scriptPath)
+		if err != nil {
+			return err
+		}
+		script = MockScript{}
+		if err := yaml.Unmarshal(data, &script); err != nil {
+			return fmt.Errorf("parse %s: %w", *scriptPath, err)
+		}
+	}
+
+	api := &mockAPI{}
+	for _, console := range script.Consoles {
+		api.consoles = append(api.consoles, monitor.ConsoleStatus{Name: console.Name, Status: console.Status})
+	}
+	go api.play(script)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/set", api.handleSet)
+	mux.HandleFunc("/", api.handleStatus)
+	slog.Info("Mock status API listening", "addr", *listen)
+	return http.ListenAndServe(*listen, mux)
+}
+
+// play применяет шаги сценария по расписанию
+func (a *mockAPI) play(script MockScript) {
+	if len(script.Steps) == 0 {
+		return
+	}
+	for {
+		for _, step := range script.Steps {
+			time.Sleep(step.After.Duration)
+			a.apply(step)
+		}
+		if !script.Loop {
+			return
+		}
+	}
+}
+
+// apply меняет состояние по одному шагу
+func (a *mockAPI) apply(step MockStep) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for name, status := range step.Set {
+		a.set(name, status)
+		slog.Info("Mock console changed", "console", name, "status", status)
+	}
+	for _, name := range step.Remove {
+		for i, console := range a.consoles {
+			if console.Name == name {
+				a.consoles = append(a.consoles[:i], a.consoles[i+1:]...)
+				slog.Info("Mock console removed", "console", name)
+				break
+			}
+		}
+	}
+	if step.Error != nil {
+		a.failing = *step.Error
+		slog.Info("Mock API error response toggled", "failing", a.failing)
+	}
+}
+
+// set меняет статус консоли или добавляет её; вызывается под mutex
+func (a *mockAPI) set(name, status string) {
+	for i := range a.consoles {
+		if a.consoles[i].Name == name {
+			a.consoles[i].Status = status
+			return
+		}
+	}
+	a.consoles = append(a.consoles, monitor.ConsoleStatus{Name: name, Status: status})
+}
+
+// handleStatus отвечает как get-consoles-status на любой адрес
+func (a *mockAPI) handleStatus(w http.ResponseWriter, r *http.Request) {
+	a.mutex.Lock()
+	consoles := append([]monitor.ConsoleStatus{}, a.consoles...)
+	failing := a.failing
+	a.mutex.Unlock()
+
+	body := []byte(cfg().ErrorResponse)
+	if !failing {
+		body, _ = json.Marshal(consoles)
+	}
+
+	// ETag позволяет проверить условные запросы бота: неизменный ответ — 304
+	etag := fmt.Sprintf(`"%x"`, sha1.Sum(body))
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// handleSet меняет статус вручную: POST /set?console=console-1&status=Error
+func (a *mockAPI) handleSet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	name, status := r.FormValue("console"), r.FormValue("status")
+	if name == "" || status == "" {
+		http.Error(w, "console and status are required", http.StatusBadRequest)
+		return
+	}
+	a.apply(MockStep{Set: map[string]string{name: status}})
+	w.WriteHeader(http.StatusNoContent)
+}
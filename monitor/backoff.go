@@ -0,0 +1,49 @@
+package monitor
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff задаёт паузы между повторными запросами к недоступному API
+type Backoff struct {
+	Initial    time.Duration // Первая пауза после ошибки; 0 — интервал проверки API
+	Max        time.Duration // Максимальная пауза
+	Multiplier float64       // Во сколько раз растёт пауза после каждой ошибки подряд
+}
+
+// retry считает паузу перед следующим запросом. Пока ошибок нет,
+// пауза равна обычному интервалу.
+type retry struct {
+	config   Backoff
+	interval time.Duration // Обычный интервал проверки
+	failures int           // Ошибок подряд
+}
+
+// next возвращает паузу перед следующей проверкой с учётом её результата
+func (r *retry) next(err error) time.Duration {
+	if err == nil {
+		r.failures = 0
+		return r.interval
+	}
+	r.failures++
+
+	delay := r.config.Initial
+	if delay <= 0 {
+		delay = r.interval
+	}
+	for i := 1; i < r.failures && delay < r.config.Max; i++ {
+		delay = time.Duration(float64(delay) * r.config.Multiplier)
+	}
+	if r.config.Max > 0 && delay > r.config.Max {
+		delay = r.config.Max
+	}
+
+	// Случайный разброс в пределах второй половины паузы, чтобы реплики
+	// и разные API не повторяли запросы синхронно
+	half := delay / 2
+	if half > 0 {
+		delay = half + time.Duration(rand.Int63n(int64(half)))
+	}
+	return delay
+}
@@ -0,0 +1,306 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// tracer создаёт спаны проверок; без настроенного провайдера OpenTelemetry они ничего не делают
+var tracer = otel.Tracer("status-bot/monitor")
+
+// Target — отслеживаемое API: имя, источник статусов и интервал проверки
+type Target struct {
+	Name     string
+	Source   Source
+	Interval time.Duration // Интервал опроса; для StreamSource — как часто открытое соединение считается успешной проверкой
+}
+
+// Check — результат одной проверки для метрик и проверки здоровья
+type Check struct {
+	Target  string
+	Err     error
+	Polled  bool          // Вызов Fetch, а не событие или пульс потока
+	Latency time.Duration // Длительность Fetch
+}
+
+// Engine следит за статусами набора API: опрашивает источники по интервалу
+// или слушает их потоки, сравнивает нормализованный ответ с предыдущим
+// и вызывает OnChange, если он изменился. Все обработчики необязательны
+// и вызываются из горутин проверок разных API одновременно.
+type Engine struct {
+	Backoff Backoff // Паузы между повторами после ошибок
+
+	// Prepare дополняет или меняет статусы перед сравнением
+	Prepare func(target string, consoles []ConsoleStatus) []ConsoleStatus
+	// Confirm получает предыдущий и новый ответ и возвращает тот, что
+	// считать текущим, — так изменение можно придержать до повторной проверки
+	Confirm func(target, prev, status string) string
+	// OnChange получает предыдущий и новый нормализованный ответ API; ctx
+	// несёт спан проверки, в которой обнаружено изменение
+	OnChange func(ctx context.Context, target, prev, status string)
+	// OnCheck вызывается после каждой проверки и каждого события потока
+	OnCheck func(Check)
+	// Logger получает ошибки проверок; nil — slog.Default()
+	Logger *slog.Logger
+
+	targets []Target
+	mutex   sync.Mutex
+	last    map[string]string      // Последний нормализованный ответ по имени API
+	checks  map[string]*sync.Mutex // По одной проверке API за раз: опрос, поток и внеочередные Check
+}
+
+// NewEngine создаёт Engine для списка API; имена должны быть уникальны
+func NewEngine(targets []Target) *Engine {
+	return &Engine{targets: targets, last: make(map[string]string), checks: make(map[string]*sync.Mutex)}
+}
+
+// checkLock возвращает мьютекс, под которым проверяется API. Запрос, подтверждение,
+// сравнение и OnChange одной проверки должны идти целиком, иначе две
+// одновременные проверки обе продвинут подтверждение и обе сообщат об одном изменении.
+func (e *Engine) checkLock(target string) *sync.Mutex {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	lock, ok := e.checks[target]
+	if !ok {
+		lock = &sync.Mutex{}
+		e.checks[target] = lock
+	}
+	return lock
+}
+
+// Last возвращает последний нормализованный ответ API или пустую строку
+func (e *Engine) Last(target string) string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.last[target]
+}
+
+// SetLast задаёт последний известный ответ API, например сохранённый до
+// перезапуска, чтобы первая проверка не считалась изменением
+func (e *Engine) SetLast(target, status string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.last[target] = status
+}
+
+// Run проверяет все API до отмены ctx и возвращается, когда все проверки завершены
+func (e *Engine) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, target := range e.targets {
+		wg.Add(1)
+		go func(target Target) {
+			defer wg.Done()
+			if stream, ok := target.Source.(StreamSource); ok {
+				e.stream(ctx, target, stream)
+				return
+			}
+			e.poll(ctx, target)
+		}(target)
+	}
+	wg.Wait()
+}
+
+// Check выполняет одну внеочередную проверку API; если API как раз
+// проверяется по расписанию, она дождётся окончания той проверки
+func (e *Engine) Check(ctx context.Context, target string) error {
+	for _, t := range e.targets {
+		if t.Name == target {
+			return e.check(ctx, t)
+		}
+	}
+	return fmt.Errorf("unknown target %q", target)
+}
+
+// Apply сравнивает статусы с предыдущими и вызывает OnChange, если ответ изменился
+func (e *Engine) Apply(ctx context.Context, target string, consoles []ConsoleStatus) error {
+	lock := e.checkLock(target)
+	lock.Lock()
+	defer lock.Unlock()
+	return e.apply(ctx, target, consoles)
+}
+
+// apply — Apply под уже взятым мьютексом проверки API
+func (e *Engine) apply(ctx context.Context, target string, consoles []ConsoleStatus) error {
+	if e.Prepare != nil {
+		consoles = e.Prepare(target, consoles)
+	}
+	status, err := EncodeStatuses(consoles)
+	if err != nil {
+		return err
+	}
+	if e.Confirm != nil {
+		status = e.Confirm(target, e.Last(target), status)
+	}
+
+	prev, changed := e.changed(target, status)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("monitor.changed", changed))
+	if changed && e.OnChange != nil {
+		e.OnChange(ctx, target, prev, status)
+	}
+	return nil
+}
+
+// changed сравнивает ответ с предыдущим для того же API, запоминает
+// его и возвращает предыдущий ответ. Ответы нормализованы (ключи объектов
+// отсортированы), поэтому равенство строк означает отсутствие смысловых изменений.
+func (e *Engine) changed(target, status string) (string, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	prev, ok := e.last[target]
+	if ok && prev == status {
+		return prev, false
+	}
+	e.last[target] = status
+	return prev, true
+}
+
+// logger возвращает журнал для ошибок проверок
+func (e *Engine) logger() *slog.Logger {
+	if e.Logger != nil {
+		return e.Logger
+	}
+	return slog.Default()
+}
+
+// report передаёт результат проверки в OnCheck
+func (e *Engine) report(check Check) {
+	if e.OnCheck != nil {
+		e.OnCheck(check)
+	}
+}
+
+// check запрашивает статусы у источника и применяет их
+func (e *Engine) check(ctx context.Context, target Target) error {
+	ctx, span := tracer.Start(ctx, "monitor.check", trace.WithAttributes(attribute.String("monitor.target", target.Name)))
+	defer span.End()
+
+	lock := e.checkLock(target.Name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	consoles, err := e.fetch(ctx, target)
+	if err == nil {
+		err = e.apply(ctx, target.Name, consoles)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// fetch вызывает Fetch источника в отдельном спане и учитывает проверку
+func (e *Engine) fetch(ctx context.Context, target Target) ([]ConsoleStatus, error) {
+	ctx, span := tracer.Start(ctx, "monitor.fetch")
+	defer span.End()
+
+	start := time.Now()
+	consoles, err := target.Source.Fetch(ctx)
+	e.report(Check{Target: target.Name, Err: err, Polled: true, Latency: time.Since(start)})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("monitor.consoles", len(consoles)))
+	return consoles, nil
+}
+
+// poll проверяет API каждые Interval, увеличивая паузу после ошибок
+func (e *Engine) poll(ctx context.Context, target Target) {
+	retry := retry{config: e.Backoff, interval: target.Interval}
+	for {
+		err := e.check(ctx, target)
+		if ctx.Err() != nil {
+			return
+		}
+
+		delay := retry.next(err)
+		if err != nil {
+			e.logger().Error("Error getting status", "endpoint", target.Name, "error", err, "retry_in", delay.Round(time.Millisecond).String())
+		}
+		if !SleepContext(ctx, delay) {
+			return
+		}
+	}
+}
+
+// stream держит соединение с потоковым источником и применяет статусы
+// из каждого события. Пока соединение открыто, оно считается успешной
+// проверкой раз в Interval, чтобы тихий поток не выглядел зависшим.
+func (e *Engine) stream(ctx context.Context, target Target, source StreamSource) {
+	var connected atomic.Bool
+	go func() {
+		ticker := time.NewTicker(target.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if connected.Load() {
+					e.report(Check{Target: target.Name})
+				}
+			}
+		}
+	}()
+
+	retry := retry{config: e.Backoff, interval: target.Interval}
+	for {
+		connected.Store(true)
+		received := false
+		err := source.Stream(ctx, func(consoles []ConsoleStatus) {
+			received = true
+			e.report(Check{Target: target.Name})
+			// Каждое событие потока — отдельная трасса, а не часть долгого соединения
+			eventCtx, span := tracer.Start(ctx, "monitor.event", trace.WithNewRoot(),
+				trace.WithAttributes(attribute.String("monitor.target", target.Name), attribute.Int("monitor.consoles", len(consoles))))
+			if err := e.Apply(eventCtx, target.Name, consoles); err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				e.logger().Error("Error applying statuses", "endpoint", target.Name, "error", err)
+			}
+			span.End()
+		})
+		connected.Store(false)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Соединение, успевшее прислать события, переподключается без роста паузы
+		if received {
+			retry.next(nil)
+		}
+		if err == nil {
+			err = fmt.Errorf("stream closed")
+		}
+		e.report(Check{Target: target.Name, Err: err})
+		delay := retry.next(err)
+		e.logger().Warn("Stream interrupted", "endpoint", target.Name, "error", err, "retry_in", delay.Round(time.Millisecond).String())
+		if !SleepContext(ctx, delay) {
+			return
+		}
+	}
+}
+
+// SleepContext ждёт d или отмены контекста; возвращает false, если контекст отменён
+func SleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,169 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// change — вызов OnChange
+type change struct {
+	prev, status string
+}
+
+func TestEngineApply(t *testing.T) {
+	online := []ConsoleStatus{{Name: "a", Status: "Online"}}
+	down := []ConsoleStatus{{Name: "a", Status: "Error"}}
+	const onlineJSON = `[{"Name":"a","Status":"Online"}]`
+	const downJSON = `[{"Name":"a","Status":"Error"}]`
+
+	// holdDown придерживает недоступность до второй проверки подряд
+	holdDown := func() func(target, prev, status string) string {
+		polls := 0
+		return func(target, prev, status string) string {
+			if prev == "" || !strings.Contains(status, "Error") || strings.Contains(prev, "Error") {
+				polls = 0
+				return status
+			}
+			if polls++; polls < 2 {
+				return prev
+			}
+			polls = 0
+			return status
+		}
+	}
+
+	tests := []struct {
+		name    string
+		last    string
+		confirm func(target, prev, status string) string
+		applies [][]ConsoleStatus
+		want    []change
+	}{
+		{
+			name:    "первая проверка",
+			applies: [][]ConsoleStatus{online},
+			want:    []change{{"", onlineJSON}},
+		},
+		{
+			name:    "сохранённый статус не считается изменением",
+			last:    onlineJSON,
+			applies: [][]ConsoleStatus{online, online},
+		},
+		{
+			name:    "изменение и возврат",
+			last:    onlineJSON,
+			applies: [][]ConsoleStatus{down, down, online},
+			want:    []change{{onlineJSON, downJSON}, {downJSON, onlineJSON}},
+		},
+		{
+			name:    "подтверждение со второй проверки",
+			last:    onlineJSON,
+			confirm: holdDown(),
+			applies: [][]ConsoleStatus{down, down},
+			want:    []change{{onlineJSON, downJSON}},
+		},
+		{
+			name:    "неподтверждённый сбой",
+			last:    onlineJSON,
+			confirm: holdDown(),
+			applies: [][]ConsoleStatus{down, online, down, online},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEngine(nil)
+			if tt.last != "" {
+				e.SetLast("api", tt.last)
+			}
+			e.Confirm = tt.confirm
+			var got []change
+			e.OnChange = func(ctx context.Context, target, prev, status string) {
+				got = append(got, change{prev, status})
+			}
+			for _, consoles := range tt.applies {
+				if err := e.Apply(context.Background(), "api", consoles); err != nil {
+					t.Fatalf("Apply() error = %v", err)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("OnChange calls = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("OnChange[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEngineApplyPrepare(t *testing.T) {
+	e := NewEngine(nil)
+	e.Prepare = func(target string, consoles []ConsoleStatus) []ConsoleStatus {
+		return append(consoles, ConsoleStatus{Name: target + ": load", Status: "OK"})
+	}
+	if err := e.Apply(context.Background(), "api", []ConsoleStatus{{Name: "a", Status: "Online"}}); err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"Name":"a","Status":"Online"},{"Name":"api: load","Status":"OK"}]`
+	if got := e.Last("api"); got != want {
+		t.Errorf("Last() = %s, want %s", got, want)
+	}
+}
+
+// slowSource отвечает с задержкой и замечает одновременные вызовы Fetch
+type slowSource struct {
+	inFlight atomic.Int32
+	overlaps atomic.Int32
+	status   atomic.Value // string
+}
+
+func (s *slowSource) Fetch(ctx context.Context) ([]ConsoleStatus, error) {
+	if s.inFlight.Add(1) > 1 {
+		s.overlaps.Add(1)
+	}
+	defer s.inFlight.Add(-1)
+	time.Sleep(5 * time.Millisecond)
+	return []ConsoleStatus{{Name: "a", Status: s.status.Load().(string)}}, nil
+}
+
+func TestEngineCheckSerialized(t *testing.T) {
+	source := &slowSource{}
+	source.status.Store("Error")
+	e := NewEngine([]Target{{Name: "api", Source: source, Interval: time.Hour}})
+	e.SetLast("api", `[{"Name":"a","Status":"Online"}]`)
+
+	var changes atomic.Int32
+	e.OnChange = func(ctx context.Context, target, prev, status string) {
+		changes.Add(1)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := e.Check(context.Background(), "api"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := source.overlaps.Load(); n != 0 {
+		t.Errorf("Fetch ran concurrently %d times", n)
+	}
+	if n := changes.Load(); n != 1 {
+		t.Errorf("OnChange calls = %d, want 1", n)
+	}
+}
+
+func TestEngineCheckUnknownTarget(t *testing.T) {
+	if err := NewEngine(nil).Check(context.Background(), "missing"); err == nil {
+		t.Error("Check() of unknown target returned no error")
+	}
+}
@@ -0,0 +1,212 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONPath — разобранное выражение из подмножества JSONPath:
+// $ (или @ — текущий узел), .key, ['key'], [n], [*] и .*
+type JSONPath []pathStep
+
+// pathStep — один шаг пути: ключ объекта, индекс массива или все элементы
+type pathStep struct {
+	key   string
+	index int
+	kind  int
+}
+
+const (
+	stepKey = iota
+	stepIndex
+	stepAll
+)
+
+// ParseJSONPath разбирает выражение вида $.data.items[*].name
+func ParseJSONPath(s string) (JSONPath, error) {
+	rest := strings.TrimSpace(s)
+	if !strings.HasPrefix(rest, "$") && !strings.HasPrefix(rest, "@") {
+		return nil, fmt.Errorf("path %q must start with $ or @", s)
+	}
+	rest = rest[1:]
+
+	var path JSONPath
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".*"):
+			path = append(path, pathStep{kind: stepAll})
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "."):
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("path %q: empty key", s)
+			}
+			path = append(path, pathStep{key: key})
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %q: unclosed [", s)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				path = append(path, pathStep{kind: stepAll})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				path = append(path, pathStep{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("path %q: invalid index %q", s, inner)
+				}
+				path = append(path, pathStep{kind: stepIndex, index: index})
+			}
+		default:
+			return nil, fmt.Errorf("path %q: unexpected %q", s, rest)
+		}
+	}
+	return path, nil
+}
+
+// Eval возвращает все узлы документа, подходящие под путь
+func (p JSONPath) Eval(doc interface{}) []interface{} {
+	nodes := []interface{}{doc}
+	for _, step := range p {
+		var next []interface{}
+		for _, node := range nodes {
+			switch v := node.(type) {
+			case map[string]interface{}:
+				switch step.kind {
+				case stepKey:
+					if child, ok := v[step.key]; ok {
+						next = append(next, child)
+					}
+				case stepAll:
+					// Ключи по порядку, чтобы консоли не меняли места между проверками
+					keys := make([]string, 0, len(v))
+					for key := range v {
+						keys = append(keys, key)
+					}
+					sort.Strings(keys)
+					for _, key := range keys {
+						next = append(next, v[key])
+					}
+				}
+			case []interface{}:
+				switch step.kind {
+				case stepIndex:
+					index := step.index
+					if index < 0 {
+						index += len(v)
+					}
+					if index >= 0 && index < len(v) {
+						next = append(next, v[index])
+					}
+				case stepAll:
+					next = append(next, v...)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// ExtractConfig задаёт, где в ответе API искать консоли, их имена и статусы.
+// Пустые поля означают формат 4cloud.pro: [{"Name": "...", "Status": "..."}].
+type ExtractConfig struct {
+	Consoles string `yaml:"consoles" json:"consoles"` // Путь к элементам-консолям; по умолчанию $[*]
+	Name     string `yaml:"name" json:"name"`         // Путь к имени внутри элемента; по умолчанию @.Name
+	Status   string `yaml:"status" json:"status"`     // Путь к статусу внутри элемента; по умолчанию @.Status
+}
+
+// empty сообщает, что правила не заданы и ответ разбирается как есть
+func (c ExtractConfig) empty() bool {
+	return c.Consoles == "" && c.Name == "" && c.Status == ""
+}
+
+// Extractor — разобранные правила ExtractConfig
+type Extractor struct {
+	consoles, name, status JSONPath
+}
+
+// NewExtractor разбирает правила разбора; nil без ошибки — правила не заданы
+func NewExtractor(config ExtractConfig) (*Extractor, error) {
+	if config.empty() {
+		return nil, nil
+	}
+	e := &Extractor{}
+	var err error
+	if e.consoles, err = parseJSONPathOr(config.Consoles, "$[*]"); err != nil {
+		return nil, fmt.Errorf("extract.consoles: %w", err)
+	}
+	if e.name, err = parseJSONPathOr(config.Name, "@.Name"); err != nil {
+		return nil, fmt.Errorf("extract.name: %w", err)
+	}
+	if e.status, err = parseJSONPathOr(config.Status, "@.Status"); err != nil {
+		return nil, fmt.Errorf("extract.status: %w", err)
+	}
+	return e, nil
+}
+
+// parseJSONPathOr разбирает путь, подставляя fallback вместо пустого
+func parseJSONPathOr(s, fallback string) (JSONPath, error) {
+	if s == "" {
+		s = fallback
+	}
+	return ParseJSONPath(s)
+}
+
+// DecodeConsoles разбирает ответ в список консолей: по правилам Extractor
+// или, если он nil, как массив [{"Name": "...", "Status": "..."}]
+func DecodeConsoles(data []byte, e *Extractor) ([]ConsoleStatus, error) {
+	if e == nil {
+		var consoles []ConsoleStatus
+		if err := json.Unmarshal(data, &consoles); err != nil {
+			return nil, err
+		}
+		// Элементы целиком нужны числовым правилам (thresholds)
+		var raw []interface{}
+		if err := json.Unmarshal(data, &raw); err == nil && len(raw) == len(consoles) {
+			for i := range consoles {
+				consoles[i].Raw = raw[i]
+			}
+		}
+		return consoles, nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	nodes := e.consoles.Eval(doc)
+	consoles := make([]ConsoleStatus, 0, len(nodes))
+	for _, node := range nodes {
+		consoles = append(consoles, ConsoleStatus{Name: firstString(e.name.Eval(node)), Status: firstString(e.status.Eval(node)), Raw: node})
+	}
+	return consoles, nil
+}
+
+// firstString возвращает первый узел строкой: строки как есть, числа
+// и логические значения в JSON-записи; пустая строка, если узлов нет
+func firstString(nodes []interface{}) string {
+	if len(nodes) == 0 || nodes[0] == nil {
+		return ""
+	}
+	if s, ok := nodes[0].(string); ok {
+		return s
+	}
+	data, err := json.Marshal(nodes[0])
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
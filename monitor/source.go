@@ -0,0 +1,24 @@
+package monitor
+
+import (
+	"context"
+)
+
+// Source — источник статусов консолей одного отслеживаемого API.
+// Engine сравнивает результаты соседних вызовов Fetch и сообщает об изменениях,
+// поэтому новый вид проверок достаточно описать своей реализацией Source.
+type Source interface {
+	// Fetch возвращает текущие статусы консолей. Ошибка считается неудачной
+	// проверкой: к ней применяется backoff, а статусы не меняются.
+	Fetch(ctx context.Context) ([]ConsoleStatus, error)
+}
+
+// StreamSource — источник, который сам присылает изменения по открытому
+// соединению. Такие API не опрашиваются по интервалу: каждое событие сразу
+// сравнивается с предыдущим, а после обрыва соединение восстанавливается с backoff.
+type StreamSource interface {
+	Source
+	// Stream держит соединение до ошибки или отмены ctx и вызывает update
+	// с полным списком консолей после каждого события.
+	Stream(ctx context.Context, update func([]ConsoleStatus)) error
+}
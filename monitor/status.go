@@ -0,0 +1,88 @@
+// Package monitor следит за статусами консолей: опрашивает источники или
+// слушает их потоки, сравнивает ответы с предыдущими и сообщает об изменениях.
+// Engine не знает ни о Telegram, ни о хранилище — рассылку и сохранение
+// состояния выполняет встраивающая его программа через обработчики.
+package monitor
+
+import (
+	"encoding/json"
+)
+
+// ConsoleStatus — элемент массива консолей из ответа API
+type ConsoleStatus struct {
+	Name   string `json:"Name"`
+	Status string `json:"Status"`
+
+	Raw interface{} `json:"-"` // Элемент ответа целиком для числовых правил; nil, если источник его не даёт
+}
+
+// StatusChange описывает смену статуса одной консоли между двумя ответами API.
+// Пустой Old означает, что консоль появилась, пустой New — что она пропала.
+type StatusChange struct {
+	Name string
+	Old  string
+	New  string
+}
+
+// ParseStatuses разбирает нормализованный ответ API в список консолей
+func ParseStatuses(status string) ([]ConsoleStatus, error) {
+	var consoles []ConsoleStatus
+	if err := json.Unmarshal([]byte(status), &consoles); err != nil {
+		return nil, err
+	}
+	return consoles, nil
+}
+
+// EncodeStatuses переводит статусы в строку для сравнения и сохранения состояния
+func EncodeStatuses(consoles []ConsoleStatus) (string, error) {
+	if consoles == nil {
+		consoles = []ConsoleStatus{}
+	}
+	data, err := json.Marshal(consoles)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Diff возвращает консоли, чей статус изменился. Порядок следует
+// новому ответу, пропавшие консоли идут в конце в порядке старого ответа.
+func Diff(prev, curr []ConsoleStatus) []StatusChange {
+	old := make(map[string]string, len(prev))
+	for _, console := range prev {
+		old[console.Name] = console.Status
+	}
+
+	var changes []StatusChange
+	seen := make(map[string]bool, len(curr))
+	for _, console := range curr {
+		seen[console.Name] = true
+		if status, ok := old[console.Name]; !ok || status != console.Status {
+			changes = append(changes, StatusChange{Name: console.Name, Old: status, New: console.Status})
+		}
+	}
+	for _, console := range prev {
+		if !seen[console.Name] {
+			changes = append(changes, StatusChange{Name: console.Name, Old: console.Status})
+		}
+	}
+	return changes
+}
+
+// DiffPayloads сравнивает два нормализованных ответа API.
+// Пустой предыдущий ответ считается пустым списком консолей.
+func DiffPayloads(prev, curr string) ([]StatusChange, error) {
+	var prevConsoles []ConsoleStatus
+	if prev != "" {
+		var err error
+		if prevConsoles, err = ParseStatuses(prev); err != nil {
+			return nil, err
+		}
+	}
+
+	currConsoles, err := ParseStatuses(curr)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(prevConsoles, currConsoles), nil
+}
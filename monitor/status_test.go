@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffPayloads(t *testing.T) {
+	tests := []struct {
+		name    string
+		prev    string
+		curr    string
+		want    []StatusChange
+		wantErr bool
+	}{
+		{
+			name: "без изменений",
+			prev: `[{"Name":"a","Status":"Online"}]`,
+			curr: `[{"Name":"a","Status":"Online"}]`,
+		},
+		{
+			name: "первый ответ",
+			curr: `[{"Name":"a","Status":"Online"},{"Name":"b","Status":"Error"}]`,
+			want: []StatusChange{{Name: "a", New: "Online"}, {Name: "b", New: "Error"}},
+		},
+		{
+			name: "смена статуса",
+			prev: `[{"Name":"a","Status":"Online"},{"Name":"b","Status":"Online"}]`,
+			curr: `[{"Name":"a","Status":"Online"},{"Name":"b","Status":"Error"}]`,
+			want: []StatusChange{{Name: "b", Old: "Online", New: "Error"}},
+		},
+		{
+			name: "консоль появилась и пропала",
+			prev: `[{"Name":"a","Status":"Online"},{"Name":"b","Status":"Online"}]`,
+			curr: `[{"Name":"c","Status":"Online"},{"Name":"a","Status":"Online"}]`,
+			want: []StatusChange{{Name: "c", New: "Online"}, {Name: "b", Old: "Online"}},
+		},
+		{
+			name: "все консоли пропали",
+			prev: `[{"Name":"a","Status":"Online"}]`,
+			curr: `[]`,
+			want: []StatusChange{{Name: "a", Old: "Online"}},
+		},
+		{
+			name:    "неразобранный новый ответ",
+			prev:    `[]`,
+			curr:    `maintenance`,
+			wantErr: true,
+		},
+		{
+			name:    "неразобранный прежний ответ",
+			prev:    `{"error":1}`,
+			curr:    `[]`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DiffPayloads(tt.prev, tt.curr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DiffPayloads() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffPayloads() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"net/http"
+	"status-bot/monitor"
+	"sync"
+	"time"
+)
+
+// StatusEvent — изменения статуса одного API, которые рассылаются во все каналы уведомлений
+type StatusEvent struct {
+	Endpoint    Endpoint
+	Time        time.Time
+	Status      string                 // Ответ API целиком
+	Changes     []monitor.StatusChange // Изменения по консолям; nil, если ответ не удалось разобрать
+	Transitions map[string]transition  // Недоступность и восстановление по именам консолей
+	Severities  map[string]string      // Важность, назначенная правилами, по именам консолей
+	Test        bool                   // Пробное событие из /testalert
+
+	trace trace.SpanContext // Спан, в котором обнаружено изменение; родитель спанов доставки
+}
+
+// critical сообщает, есть ли в событии критичные изменения
+func (e StatusEvent) critical() bool {
+	return e.criticalChanges(e.Changes)
+}
+
+// criticalChanges сообщает, критично ли хотя бы одно из изменений: по правилам,
+// а для консолей без назначенной важности — если консоль стала недоступна
+func (e StatusEvent) criticalChanges(changes []monitor.StatusChange) bool {
+	for _, change := range changes {
+		if severity, ok := e.Severities[change.Name]; ok {
+			if severity == severityCritical {
+				return true
+			}
+			continue
+		}
+		if isDown(change.New) && !isDown(change.Old) {
+			return true
+		}
+	}
+	return false
+}
+
+// Notifier — канал уведомлений: чаты Telegram, Slack, Discord, почта или webhook
+type Notifier interface {
+	// Name возвращает имя канала для логов.
+	Name() string
+	// Notify доставляет событие. При ошибке доставка повторяется, поэтому
+	// канал не должен возвращать ошибку после того, как часть событий уже отправлена.
+	Notify(ctx context.Context, event StatusEvent) error
+}
+
+// NotifiersConfig перечисляет внешние каналы уведомлений и повторы доставки
+type NotifiersConfig struct {
+	Retries    int      `yaml:"retries" json:"retries"`         // Сколько раз повторять неудачную доставку в канал
+	RetryDelay Duration `yaml:"retry_delay" json:"retry_delay"` // Пауза перед первым повтором; удваивается с каждым следующим
+	QueueSize  int      `yaml:"queue_size" json:"queue_size"`   // Сколько событий ждёт доставки в каждый канал; лишние для внешних каналов отбрасываются
+
+	Slack    []SlackConfig           `yaml:"slack" json:"slack"`       // Каналы Slack
+	Discord  []DiscordConfig         `yaml:"discord" json:"discord"`   // Webhook каналов Discord
+	Email    []EmailConfig           `yaml:"email" json:"email"`       // Письма через SMTP
+	Webhooks []OutboundWebhookConfig `yaml:"webhooks" json:"webhooks"` // Произвольные адреса, получающие события в JSON
+}
+
+func (c NotifiersConfig) validate() error {
+	if c.Retries < 0 {
+		return fmt.Errorf("notifiers.retries must not be negative")
+	}
+	if c.Retries > 0 && c.RetryDelay.Duration <= 0 {
+		return fmt.Errorf("notifiers.retry_delay must be positive")
+	}
+	if c.QueueSize <= 0 {
+		return fmt.Errorf("notifiers.queue_size must be positive")
+	}
+	for i, slack := range c.Slack {
+		if err := slack.validate(i); err != nil {
+			return err
+		}
+	}
+	for i, discord := range c.Discord {
+		if err := discord.validate(i); err != nil {
+			return err
+		}
+	}
+	for i, email := range c.Email {
+		if err := email.validate(i); err != nil {
+			return err
+		}
+	}
+	for i, webhook := range c.Webhooks {
+		if err := webhook.validate(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// build создаёт каналы из конфигурации; чаты Telegram — всегда первый канал
+func (c NotifiersConfig) build() []Notifier {
+	result := []Notifier{telegramNotifier{}}
+	for _, slack := range c.Slack {
+		result = append(result, newSlackNotifier(slack))
+	}
+	for _, discord := range c.Discord {
+		result = append(result, newDiscordNotifier(discord))
+	}
+	for _, email := range c.Email {
+		result = append(result, newEmailNotifier(email))
+	}
+	for _, webhook := range c.Webhooks {
+		result = append(result, newOutboundNotifier(webhook))
+	}
+	if cfg().GRPC.Listen != "" {
+		result = append(result, grpcNotifier{})
+	}
+	return result
+}
+
+// notifyTimeout ограничивает одну попытку доставки события в один канал
+const notifyTimeout = 30 * time.Second
+
+// notifierClient — HTTP-клиент внешних каналов
+var notifierClient = &http.Client{Timeout: notifyTimeout, Transport: otelhttp.NewTransport(http.DefaultTransport)}
+
+// sink — канал уведомлений со своей очередью, чтобы медленный или недоступный
+// канал не задерживал остальные, а события в каждом канале шли по порядку
+type sink struct {
+	notifier Notifier
+	events   chan StatusEvent
+	required bool // События не отбрасываются, даже если очередь полна
+}
+
+var (
+	sinks         []sink
+	dispatcherWG  sync.WaitGroup
+	dispatcherCtx context.Context // Отменяется при остановке, прерывая паузы между повторами
+	stopRetries   context.CancelFunc
+)
+
+// startDispatcher запускает доставку событий во все каналы из конфигурации
+func startDispatcher(config NotifiersConfig) {
+	dispatcherCtx, stopRetries = context.WithCancel(context.Background())
+	for _, notifier := range config.build() {
+		_, telegram := notifier.(telegramNotifier)
+		if dryRun && !telegram {
+			notifier = dryRunNotifier{notifier}
+		}
+		s := sink{notifier: notifier, events: make(chan StatusEvent, config.QueueSize), required: telegram}
+		sinks = append(sinks, s)
+
+		dispatcherWG.Add(1)
+		go func() {
+			defer dispatcherWG.Done()
+			for event := range s.events {
+				deliverEvent(config, s.notifier, event)
+			}
+		}()
+	}
+}
+
+// stopDispatcher дожидается доставки событий из очередей; повторы после
+// остановки не выполняются
+func stopDispatcher() {
+	stopRetries()
+	for _, s := range sinks {
+		close(s.events)
+	}
+	dispatcherWG.Wait()
+}
+
+// dispatchEvent ставит событие в очередь каждого канала; спаны доставки
+// продолжают трассу из ctx. Последний статус к этому моменту уже сохранён, и
+// потерянное событие не повторится, поэтому в очередь чатов Telegram событие
+// ставится с ожиданием до отмены ctx. Внешние каналы необязательны: если их
+// очередь полна, событие для них отбрасывается.
+func dispatchEvent(ctx context.Context, event StatusEvent) {
+	event.trace = trace.SpanContextFromContext(ctx)
+	for _, s := range sinks {
+		if s.required {
+			// Сначала без ожидания: select с уже отменённым ctx выбирает
+			// ветку случайно и терял бы события при свободной очереди
+			select {
+			case s.events <- event:
+				continue
+			default:
+			}
+			select {
+			case s.events <- event:
+			case <-ctx.Done():
+				eventsDropped.WithLabelValues(s.notifier.Name()).Inc()
+				slog.Error("Dropping status event: stopped while waiting for the queue", "endpoint", event.Endpoint.Name, "notifier", s.notifier.Name())
+			}
+			continue
+		}
+		select {
+		case s.events <- event:
+		default:
+			eventsDropped.WithLabelValues(s.notifier.Name()).Inc()
+			slog.Warn("Dropping status event: queue is full", "endpoint", event.Endpoint.Name, "notifier", s.notifier.Name())
+		}
+	}
+}
+
+// deliverEvent доставляет событие в один канал, повторяя неудачные попытки
+func deliverEvent(config NotifiersConfig, notifier Notifier, event StatusEvent) {
+	defer recoverPanic("notifier", map[string]string{"notifier": notifier.Name(), "endpoint": event.Endpoint.Name})
+
+	parent := trace.ContextWithSpanContext(context.Background(), event.trace)
+	delay := config.RetryDelay.Duration
+	for attempt := 0; ; attempt++ {
+		ctx, span := tracer.Start(parent, "notify."+notifier.Name(), trace.WithAttributes(attribute.Int("attempt", attempt+1)))
+		ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+		err := notifier.Notify(ctx, event)
+		cancel()
+		endSpan(span, err)
+		if err == nil {
+			return
+		}
+		if attempt >= config.Retries || dispatcherCtx.Err() != nil {
+			slog.Error("Error notifying", "notifier", notifier.Name(), "error", err)
+			reportError("Error notifying "+notifier.Name(), err, map[string]string{"notifier": notifier.Name(), "endpoint": event.Endpoint.Name})
+			return
+		}
+		slog.Error("Error notifying", "notifier", notifier.Name(), "error", err, "retry_in", delay.String())
+		if !monitor.SleepContext(dispatcherCtx, delay) {
+			return
+		}
+		delay *= 2
+	}
+}
+
+// eventText формирует текст события без разметки Telegram
+func eventText(lang string, event StatusEvent) string {
+	var text string
+	if event.Changes == nil {
+		text = trMarkup(lang, "status.changed", escapeText(event.Endpoint.Name), formatStatuses(lang, event.Status))
+	} else {
+		text = renderChanges(lang, event.Endpoint, event.Changes, event.Transitions, event.Time)
+	}
+	if event.Test {
+		text += trMarkup(lang, "testalert.mark")
+	}
+	return plainText(text, telegramParseMode())
+}
+
+// notifierLang возвращает язык внешнего канала; по умолчанию — default_language
+func notifierLang(lang string) string {
+	if catalogs[lang] == nil {
+		return cfg().DefaultLanguage
+	}
+	return lang
+}
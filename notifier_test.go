@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestDispatchEventFullQueue(t *testing.T) {
+	telegram := sink{notifier: telegramNotifier{}, events: make(chan StatusEvent, 1), required: true}
+	external := sink{notifier: &outboundNotifier{config: OutboundWebhookConfig{Name: "hook"}}, events: make(chan StatusEvent, 1)}
+	sinks = []sink{telegram, external}
+	defer func() { sinks = nil }()
+
+	dispatchEvent(context.Background(), StatusEvent{})
+	dropped := testutil.ToFloat64(eventsDropped.WithLabelValues("hook"))
+
+	// Обе очереди полны: внешний канал теряет событие, Telegram ждёт места
+	done := make(chan struct{})
+	go func() {
+		dispatchEvent(context.Background(), StatusEvent{})
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("dispatchEvent dropped the event for the telegram sink")
+	case <-time.After(50 * time.Millisecond):
+	}
+	<-telegram.events
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("dispatchEvent did not resume after the telegram queue was drained")
+	}
+	if got := testutil.ToFloat64(eventsDropped.WithLabelValues("hook")); got != dropped+1 {
+		t.Errorf("dropped events for the external sink = %v, want %v", got, dropped+1)
+	}
+
+	// Отменённый ctx прерывает ожидание, и событие учитывается как потерянное
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	before := testutil.ToFloat64(eventsDropped.WithLabelValues("telegram"))
+	dispatchEvent(ctx, StatusEvent{})
+	if got := testutil.ToFloat64(eventsDropped.WithLabelValues("telegram")); got != before+1 {
+		t.Errorf("dropped events for the telegram sink = %v, want %v", got, before+1)
+	}
+}
+
+func TestDispatchEventCancelledWithRoom(t *testing.T) {
+	const events = 100
+	telegram := sink{notifier: telegramNotifier{}, events: make(chan StatusEvent, events), required: true}
+	sinks = []sink{telegram}
+	defer func() { sinks = nil }()
+
+	// Проверка, закончившаяся после остановки engine, всё равно доставляет
+	// событие, если в очереди есть место
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < events; i++ {
+		dispatchEvent(ctx, StatusEvent{})
+	}
+	if n := len(telegram.events); n != events {
+		t.Errorf("queued %d events with a cancelled ctx, want %d", n, events)
+	}
+}
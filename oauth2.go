@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// OAuth2Config — получение токена доступа по OAuth2 client credentials
+// для API за провайдером идентификации (Keycloak, Okta, Azure AD и т. п.)
+type OAuth2Config struct {
+	TokenURL        string            `yaml:"token_url" json:"token_url"`                 // Адрес выдачи токенов
+	ClientID        string            `yaml:"client_id" json:"client_id"`                 // Идентификатор клиента
+	ClientSecret    string            `yaml:"client_secret" json:"client_secret"`         // Секрет клиента; если пуст, читается из ClientSecretEnv
+	ClientSecretEnv string            `yaml:"client_secret_env" json:"client_secret_env"` // Переменная окружения с секретом клиента
+	Scopes          []string          `yaml:"scopes" json:"scopes"`                       // Запрашиваемые области доступа
+	Params          map[string]string `yaml:"params" json:"params"`                       // Дополнительные параметры запроса токена, например audience
+}
+
+func (c OAuth2Config) enabled() bool {
+	return c.TokenURL != ""
+}
+
+func (c OAuth2Config) validate() error {
+	if !strings.HasPrefix(c.TokenURL, "http://") && !strings.HasPrefix(c.TokenURL, "https://") {
+		return fmt.Errorf("auth.oauth2.token_url must be an http(s) URL")
+	}
+	if c.ClientID == "" {
+		return fmt.Errorf("auth.oauth2.client_id must be set")
+	}
+	return nil
+}
+
+// oauth2Expiry — за сколько до истечения токен запрашивается заново
+const oauth2Expiry = 30 * time.Second
+
+// newOAuth2TokenSource возвращает источник токенов, который хранит токен
+// до истечения и получает новый при необходимости
+func newOAuth2TokenSource(config OAuth2Config) oauth2.TokenSource {
+	return oauth2.ReuseTokenSourceWithExpiry(nil, clientCredentials{config}, oauth2Expiry)
+}
+
+// clientCredentials запрашивает новый токен; секрет клиента читается при
+// каждом запросе, чтобы применялись новые значения из *_FILE и Vault
+type clientCredentials struct {
+	config OAuth2Config
+}
+
+func (c clientCredentials) Token() (*oauth2.Token, error) {
+	secret := c.config.ClientSecret
+	if secret == "" {
+		secret = getenvSecret(c.config.ClientSecretEnv)
+	}
+	params := make(url.Values, len(c.config.Params))
+	for name, value := range c.config.Params {
+		params.Set(name, value)
+	}
+	credentials := clientcredentials.Config{
+		ClientID:       c.config.ClientID,
+		ClientSecret:   secret,
+		TokenURL:       c.config.TokenURL,
+		Scopes:         c.config.Scopes,
+		EndpointParams: params,
+	}
+
+	// Токен запрашивается через общий клиент API: с его прокси и таймаутами
+	ctx, cancel := context.WithTimeout(context.Background(), cfg().HTTPClient.ReadTimeout.Duration)
+	defer cancel()
+	token, err := credentials.Token(context.WithValue(ctx, oauth2.HTTPClient, apiClient))
+	if err != nil {
+		return nil, fmt.Errorf("oauth2: %w", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"status-bot/monitor"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// onCallOverrideKey — ключ состояния с временной заменой дежурного (/override)
+const onCallOverrideKey = "oncall_override"
+
+// OnCallUser — участник графика дежурств
+type OnCallUser struct {
+	ID   int64  `yaml:"id" json:"id"`     // Telegram ID; бот пишет дежурному в личные сообщения
+	Name string `yaml:"name" json:"name"` // Имя для упоминаний; "@username" упоминается и без разметки
+}
+
+// OnCallConfig — еженедельная ротация дежурных
+type OnCallConfig struct {
+	Users []OnCallUser `yaml:"users" json:"users"` // Порядок дежурств; пусто — дежурств нет
+	Start string       `yaml:"start" json:"start"` // Дата (2006-01-02), с которой дежурит первый из списка; смена — каждые 7 дней
+}
+
+func (c OnCallConfig) validate() error {
+	if len(c.Users) == 0 {
+		return nil
+	}
+	if _, err := time.Parse("2006-01-02", c.Start); err != nil {
+		return fmt.Errorf("oncall.start must be a date like 2006-01-02: %w", err)
+	}
+	for i, user := range c.Users {
+		if user.ID == 0 {
+			return fmt.Errorf("oncall.users[%d]: id must be set", i)
+		}
+	}
+	return nil
+}
+
+// onCallOverride — временная замена дежурного
+type onCallOverride struct {
+	User  OnCallUser `json:"user"`
+	Until time.Time  `json:"until"`
+}
+
+// scheduledOnCall возвращает дежурного по графику на момент t
+func scheduledOnCall(t time.Time) (OnCallUser, bool) {
+	users := cfg().OnCall.Users
+	if len(users) == 0 {
+		return OnCallUser{}, false
+	}
+	start, err := time.ParseInLocation("2006-01-02", cfg().OnCall.Start, t.Location())
+	if err != nil {
+		return OnCallUser{}, false
+	}
+	weeks := int(t.Sub(start) / (7 * 24 * time.Hour))
+	if t.Before(start) {
+		weeks--
+	}
+	n := len(users)
+	return users[((weeks%n)+n)%n], true
+}
+
+// currentOnCall возвращает дежурного с учётом действующей замены
+func currentOnCall(now time.Time) (OnCallUser, bool) {
+	if override, ok := loadOnCallOverride(now); ok {
+		return override.User, true
+	}
+	return scheduledOnCall(now)
+}
+
+func loadOnCallOverride(now time.Time) (onCallOverride, bool) {
+	value, err := store.State(onCallOverrideKey)
+	if err != nil {
+		slog.Error("Error loading on-call override", "error", err)
+		return onCallOverride{}, false
+	}
+	if value == "" {
+		return onCallOverride{}, false
+	}
+	var override onCallOverride
+	if err := json.Unmarshal([]byte(value), &override); err != nil || !override.Until.After(now) {
+		return onCallOverride{}, false
+	}
+	return override, true
+}
+
+// mention упоминает пользователя с учётом режима разметки
+func mention(user OnCallUser) string {
+	name := user.Name
+	if name == "" {
+		name = strconv.FormatInt(user.ID, 10)
+	}
+	switch cfg().ParseMode {
+	case parseModeHTML, parseModeMarkdownV2:
+		if strings.HasPrefix(name, "@") {
+			return escapeText(name)
+		}
+		link := "tg://user?id=" + strconv.FormatInt(user.ID, 10)
+		if cfg().ParseMode == parseModeHTML {
+			return `<a href="` + link + `">` + escapeText(name) + "</a>"
+		}
+		return "[" + escapeText(name) + "](" + link + ")"
+	}
+	return name
+}
+
+// notifyOnCall пишет дежурному в личные сообщения о недоступности консолей,
+// если он не получит уведомление как подписчик. Настройки чата не учитываются.
+func notifyOnCall(endpoint Endpoint, changes []monitor.StatusChange, transitions map[string]transition, subscribed map[int64]bool, now time.Time) {
+	user, ok := currentOnCall(now)
+	if !ok || subscribed[user.ID] {
+		return
+	}
+
+	var down []monitor.StatusChange
+	for _, change := range changes {
+		if transitions[change.Name].kind == changeDown {
+			down = append(down, change)
+		}
+	}
+	if len(down) == 0 {
+		return
+	}
+
+	lang := chatLang(user.ID)
+	msg := newMarkupMessage(user.ID, renderChanges(lang, endpoint, down, transitions, now))
+	if keyboard := ackKeyboard(lang, incidentsOf(down, transitions)); keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	enqueueMessage(user.ID, msg)
+}
+
+// onCallLine — строка с упоминанием дежурного для уведомлений о недоступности
+func onCallLine(lang string, now time.Time) string {
+	user, ok := currentOnCall(now)
+	if !ok {
+		return ""
+	}
+	return "\n" + trMarkup(lang, "oncall.mention", mention(user))
+}
+
+// handleOnCall обрабатывает /oncall — текущий и следующий дежурный
+func handleOnCall(chatID int64) {
+	now := time.Now()
+	user, ok := currentOnCall(now)
+	if !ok {
+		reply(chatID, "oncall.none")
+		return
+	}
+
+	lang := chatLang(chatID)
+	text := tr(lang, "oncall.current", onCallName(user))
+	if override, ok := loadOnCallOverride(now); ok {
+		text += "\n" + tr(lang, "oncall.override_until", override.Until.Local().Format("02.01 15:04"))
+	}
+	if next, ok := scheduledOnCall(now.Add(7 * 24 * time.Hour)); ok {
+		text += "\n" + tr(lang, "oncall.next", onCallName(next))
+	}
+	bot.Send(tgbotapi.NewMessage(chatID, text))
+}
+
+// onCallName — имя дежурного без разметки
+func onCallName(user OnCallUser) string {
+	if user.Name != "" {
+		return user.Name
+	}
+	return strconv.FormatInt(user.ID, 10)
+}
+
+// handleOverride обрабатывает /override <id|имя> [срок] и /override off
+func handleOverride(chatID int64, args string) {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		reply(chatID, "oncall.override_usage")
+		return
+	}
+	if fields[0] == "off" {
+		if err := store.SetState(onCallOverrideKey, ""); err != nil {
+			slog.Error("Error saving on-call override", "error", err)
+			reply(chatID, "error.save")
+			return
+		}
+		reply(chatID, "oncall.override_off")
+		return
+	}
+
+	user, ok := findOnCallUser(fields[0])
+	if !ok {
+		reply(chatID, "oncall.unknown_user", fields[0])
+		return
+	}
+	now := time.Now()
+	until := now.Add(24 * time.Hour)
+	if len(fields) > 1 {
+		t, err := parseUntil(strings.Join(fields[1:], " "), now)
+		if err != nil {
+			reply(chatID, "oncall.override_usage")
+			return
+		}
+		until = t
+	}
+
+	data, err := json.Marshal(onCallOverride{User: user, Until: until})
+	if err == nil {
+		err = store.SetState(onCallOverrideKey, string(data))
+	}
+	if err != nil {
+		slog.Error("Error saving on-call override", "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+	reply(chatID, "oncall.override_set", onCallName(user), until.Local().Format("02.01 15:04"))
+}
+
+// findOnCallUser ищет участника графика по Telegram ID или имени
+func findOnCallUser(s string) (OnCallUser, bool) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	for _, user := range cfg().OnCall.Users {
+		if (err == nil && user.ID == id) || strings.EqualFold(user.Name, s) || strings.EqualFold(strings.TrimPrefix(user.Name, "@"), s) {
+			return user, true
+		}
+	}
+	if err == nil {
+		return OnCallUser{ID: id}, true
+	}
+	return OnCallUser{}, false
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// signatureHeader — заголовок с HMAC-SHA256 тела запроса в виде "sha256=<hex>"
+const signatureHeader = "X-Status-Bot-Signature"
+
+// OutboundWebhookConfig описывает адрес, которому отправляются события в JSON
+type OutboundWebhookConfig struct {
+	Name      string `yaml:"name" json:"name"`             // Имя для логов; по умолчанию адрес
+	URL       string `yaml:"url" json:"url"`               // Адрес, на который отправляется POST
+	Secret    string `yaml:"secret" json:"secret"`         // Ключ подписи HMAC; если пуст, читается из SecretEnv
+	SecretEnv string `yaml:"secret_env" json:"secret_env"` // Переменная окружения с ключом подписи
+}
+
+func (c OutboundWebhookConfig) validate(i int) error {
+	if c.URL == "" {
+		return fmt.Errorf("notifiers.webhooks[%d]: url must be set", i)
+	}
+	return nil
+}
+
+// secret возвращает ключ подписи из конфигурации или из указанной переменной окружения
+func (c OutboundWebhookConfig) secret() string {
+	if c.Secret != "" {
+		return c.Secret
+	}
+	if c.SecretEnv != "" {
+		return getenvSecret(c.SecretEnv)
+	}
+	return ""
+}
+
+// WebhookEvent — тело запроса исходящего webhook
+type WebhookEvent struct {
+	Endpoint string          `json:"endpoint"`
+	Time     time.Time       `json:"time"`
+	Critical bool            `json:"critical"`
+	Test     bool            `json:"test,omitempty"` // Пробное событие из /testalert
+	Changes  []WebhookChange `json:"changes"`
+	Status   json.RawMessage `json:"status,omitempty"` // Ответ API целиком, если его не удалось разобрать на консоли
+}
+
+// WebhookChange — смена статуса одной консоли в WebhookEvent
+type WebhookChange struct {
+	Console   string     `json:"console"`
+	Old       string     `json:"old"`
+	New       string     `json:"new"`
+	Kind      string     `json:"kind"`                 // changed, down или recovered
+	Incident  int        `json:"incident,omitempty"`   // Номер инцидента для down и recovered
+	DownSince *time.Time `json:"down_since,omitempty"` // Для down — начало недоступности
+	Downtime  int64      `json:"downtime,omitempty"`   // Для recovered — длительность недоступности в секундах
+}
+
+// newWebhookEvent переводит событие в тело исходящего webhook
+func newWebhookEvent(event StatusEvent) WebhookEvent {
+	result := WebhookEvent{
+		Endpoint: event.Endpoint.Name,
+		Time:     event.Time,
+		Critical: event.critical(),
+		Test:     event.Test,
+		Changes:  make([]WebhookChange, 0, len(event.Changes)),
+	}
+	if event.Changes == nil && json.Valid([]byte(event.Status)) {
+		result.Status = json.RawMessage(event.Status)
+	}
+	for _, change := range event.Changes {
+		t := event.Transitions[change.Name]
+		item := WebhookChange{
+			Console:  change.Name,
+			Old:      change.Old,
+			New:      change.New,
+			Kind:     t.kind,
+			Incident: t.incident.ID,
+		}
+		switch t.kind {
+		case changeDown:
+			since := t.since
+			item.DownSince = &since
+		case changeRecovered:
+			item.Downtime = int64(t.downtime / time.Second)
+		}
+		result.Changes = append(result.Changes, item)
+	}
+	return result
+}
+
+// outboundNotifier отправляет события в JSON на произвольный адрес
+type outboundNotifier struct {
+	config OutboundWebhookConfig
+}
+
+func newOutboundNotifier(config OutboundWebhookConfig) *outboundNotifier {
+	return &outboundNotifier{config: config}
+}
+
+func (n *outboundNotifier) Name() string {
+	if n.config.Name != "" {
+		return n.config.Name
+	}
+	return n.config.URL
+}
+
+func (n *outboundNotifier) Notify(ctx context.Context, event StatusEvent) error {
+	body, err := json.Marshal(newWebhookEvent(event))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if secret := n.config.secret(); secret != "" {
+		req.Header.Set(signatureHeader, "sha256="+signPayload(secret, body))
+	}
+
+	resp, err := notifierClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		data, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// signPayload возвращает HMAC-SHA256 тела запроса в hex
+func signPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+)
+
+// validatePprofListen разрешает профилирование только на loopback-адресе:
+// профили раскрывают внутреннее устройство процесса и нагружают его
+func validatePprofListen(listen string) error {
+	if listen == "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		return fmt.Errorf("pprof_listen: %w", err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("pprof_listen must be a loopback address such as 127.0.0.1:6060, got %q", listen)
+	}
+	return nil
+}
+
+// startPprofServer запускает отдельный HTTP-сервер с net/http/pprof, если
+// задан pprof_listen. Возвращает nil, если сервер выключен.
+func startPprofServer(listen string) *http.Server {
+	if listen == "" {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	srv := &http.Server{Addr: listen, Handler: mux}
+	go func() {
+		slog.Info("Serving pprof", "addr", listen)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("pprof server stopped", "error", err)
+		}
+	}()
+	return srv
+}
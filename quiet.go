@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// quietHoursSetting — ключ настройки чата с тихими часами в виде "23:00-08:00"
+const quietHoursSetting = "quiet_hours"
+
+// quietHours — ежедневный интервал в минутах от полуночи; может переходить через полночь
+type quietHours struct {
+	start, end int
+}
+
+// parseQuietHours разбирает интервал вида "23:00-08:00"
+func parseQuietHours(s string) (quietHours, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) != 2 {
+		return quietHours{}, fmt.Errorf("expected HH:MM-HH:MM, got %q", s)
+	}
+
+	var q quietHours
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return quietHours{}, fmt.Errorf("invalid time %q", part)
+		}
+		minutes := t.Hour()*60 + t.Minute()
+		if i == 0 {
+			q.start = minutes
+		} else {
+			q.end = minutes
+		}
+	}
+	if q.start == q.end {
+		return quietHours{}, fmt.Errorf("quiet hours must not be empty")
+	}
+	return q, nil
+}
+
+// contains сообщает, попадает ли момент t в тихие часы
+func (q quietHours) contains(t time.Time) bool {
+	minutes := t.Hour()*60 + t.Minute()
+	if q.start < q.end {
+		return minutes >= q.start && minutes < q.end
+	}
+	return minutes >= q.start || minutes < q.end
+}
+
+func (q quietHours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.start/60, q.start%60, q.end/60, q.end%60)
+}
+
+// inQuietHours сообщает, действуют ли сейчас тихие часы чата
+func inQuietHours(settings map[string]string, now time.Time) bool {
+	value := settings[quietHoursSetting]
+	if value == "" {
+		return false
+	}
+	q, err := parseQuietHours(value)
+	if err != nil {
+		return false
+	}
+	return q.contains(now)
+}
+
+// handleQuiet обрабатывает /quiet 23:00-08:00, /quiet off и /quiet без аргументов
+func handleQuiet(chatID int64, args string) {
+	args = strings.TrimSpace(args)
+
+	switch args {
+	case "":
+		settings, err := store.ChatSettings(chatID)
+		if err != nil {
+			slog.Error("Error loading chat settings", "chat_id", chatID, "error", err)
+		}
+		if value := settings[quietHoursSetting]; value != "" {
+			reply(chatID, "quiet.current", value)
+			return
+		}
+		reply(chatID, "quiet.none")
+		return
+	case "off":
+		if err := store.SetChatSetting(chatID, quietHoursSetting, ""); err != nil {
+			slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+			reply(chatID, "error.save")
+			return
+		}
+		reply(chatID, "quiet.off")
+		return
+	}
+
+	q, err := parseQuietHours(args)
+	if err != nil {
+		reply(chatID, "quiet.usage")
+		return
+	}
+	if err := store.SetChatSetting(chatID, quietHoursSetting, q.String()); err != nil {
+		slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+	reply(chatID, "quiet.set", q)
+}
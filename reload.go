@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"status-bot/monitor"
+)
+
+// configFile — путь к файлу конфигурации, из которого бот запущен и который перечитывается по SIGHUP и /reload
+var configFile string
+
+var (
+	stopEngine context.CancelFunc // Останавливает проверки текущего engine
+	engineDone chan struct{}      // Закрывается, когда проверки остановлены
+)
+
+// startEngine запускает проверки API в фоне до отмены ctx или stopEngineAndWait
+func startEngine(ctx context.Context) {
+	ctx, stopEngine = context.WithCancel(ctx)
+	done := make(chan struct{})
+	engineDone = done
+	engine := statusEngine()
+	go func() {
+		defer close(done)
+		engine.Run(ctx)
+	}()
+}
+
+// stopEngineAndWait останавливает проверки и дожидается, пока текущие
+// проверки сохранят состояние
+func stopEngineAndWait() {
+	stopEngine()
+	<-engineDone
+}
+
+// reloadConfig перечитывает файл конфигурации без перезапуска бота и потока
+// обновлений Telegram. Применяются API, шаблоны, правила, пороги,
+// администраторы и настройки оповещений; подключения, серверы, хранилище и
+// фоновые подсистемы (список ниже) меняются только перезапуском.
+// Вызывается из основного цикла, поэтому обновления на время замены не
+// обрабатываются.
+func reloadConfig(ctx context.Context) error {
+	if configFile == "" {
+		return fmt.Errorf("bot was started without -config")
+	}
+	next, err := loadConfig(configFile)
+	if err != nil {
+		return err
+	}
+
+	// Настройки, которые требуют перезапуска
+	next.Token, next.TokenEnv, next.Telegram = cfg().Token, cfg().TokenEnv, cfg().Telegram
+	next.StorageDir, next.Storage, next.History = cfg().StorageDir, cfg().Storage, cfg().History
+	next.Mode, next.Webhook = cfg().Mode, cfg().Webhook
+	next.HTTPListen, next.PprofListen, next.HTTPClient = cfg().HTTPListen, cfg().PprofListen, cfg().HTTPClient
+	next.Sender, next.Notifiers, next.Schedules = cfg().Sender, cfg().Notifiers, cfg().Schedules
+	next.Log, next.ErrorReporting, next.Tracing, next.Vault = cfg().Log, cfg().ErrorReporting, cfg().Tracing, cfg().Vault
+	next.UserMonitors, next.GRPC, next.WebApp = cfg().UserMonitors, cfg().GRPC, cfg().WebApp
+
+	// Всё собирается заранее, чтобы ошибка не оставила бота в промежуточном
+	// состоянии, и подменяется одним снимком
+	state, err := buildRuntime(next)
+	if err != nil {
+		return err
+	}
+
+	stopEngineAndWait()
+	previous := snapshot()
+	carryLastStatuses(previous.engine, state.engine, next.endpoints())
+	setRuntime(state)
+	startEngine(ctx)
+	registerBotCommands(previous.config.Admins)
+
+	slog.Info("Config reloaded", "path", configFile, "endpoints", len(cfg().endpoints()))
+	return nil
+}
+
+// carryLastStatuses переносит последние статусы оставшихся API в новый engine,
+// чтобы перечитывание конфигурации не считалось изменением статусов
+func carryLastStatuses(from, to *monitor.Engine, endpoints []Endpoint) {
+	for _, endpoint := range endpoints {
+		if status := from.Last(endpoint.Name); status != "" {
+			to.SetLast(endpoint.Name, status)
+		}
+	}
+}
+
+// handleReload перечитывает конфигурацию по команде администратора
+func handleReload(ctx context.Context, chatID int64) {
+	if err := reloadConfig(ctx); err != nil {
+		slog.Error("Error reloading config", "error", err)
+		reply(chatID, "reload.failed", err.Error())
+		return
+	}
+	reply(chatID, "reload.done", len(cfg().endpoints()))
+}
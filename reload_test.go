@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"status-bot/monitor"
+)
+
+// stalledSource отвечает только после отмены проверки, как запрос, который
+// успел получить ответ в момент остановки engine
+type stalledSource struct {
+	started  chan struct{}
+	consoles []monitor.ConsoleStatus
+}
+
+func (s *stalledSource) Fetch(ctx context.Context) ([]monitor.ConsoleStatus, error) {
+	close(s.started)
+	<-ctx.Done()
+	return s.consoles, nil
+}
+
+func TestReloadDeliversInFlightCheck(t *testing.T) {
+	fake := setupTestBot(t)
+	const chatID = 400
+	if err := store.AddChat(chatID); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"Name":"a","Status":"Error"}]`)
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := fmt.Sprintf("endpoints:\n  - name: api\n    url: %s\n    poll_interval: 1h\n", server.URL)
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configFile = path
+	defer func() { configFile = "" }()
+
+	config, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.StorageDir = cfg().StorageDir
+	state, err := buildRuntime(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := &stalledSource{started: make(chan struct{}), consoles: []monitor.ConsoleStatus{{Name: "a", Status: "Error"}}}
+	state.sources["api"] = source
+	state.engine = newEngine(config, state.sources)
+	state.engine.SetLast("api", `[{"Name":"a","Status":"Online"}]`)
+	setRuntime(state)
+
+	startSender(cfg().Sender)
+	startDispatcher(cfg().Notifiers)
+	ctx, cancel := context.WithCancel(context.Background())
+	startEngine(ctx)
+
+	// Перечитывание останавливает engine посреди проверки: её результат
+	// приходит уже с отменённым контекстом и всё равно должен дойти до чата
+	<-source.started
+	if err := reloadConfig(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	stopEngineAndWait()
+	stopDispatcher()
+	sinks = nil
+	stopSender()
+
+	sent := fake.sentTo(chatID)
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1: %v", len(sent), sent)
+	}
+	if !strings.Contains(sent[0].Text, "Консоль a недоступна") {
+		t.Errorf("alert text %q does not report the outage", sent[0].Text)
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"log/slog"
+	"status-bot/monitor"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// digestSetting — ключ настройки чата с расписанием сводки (/digest)
+const digestSetting = "digest"
+
+// weekdays — дни недели, которые понимает /digest weekly
+var weekdays = map[string]time.Weekday{
+	"mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday,
+	"fri": time.Friday, "sat": time.Saturday, "sun": time.Sunday,
+}
+
+// digestSchedule — расписание регулярной сводки: каждый день, раз в неделю
+// или произвольное cron-выражение
+type digestSchedule struct {
+	weekly  bool
+	weekday time.Weekday
+	hour    int
+	minute  int
+	spec    string // cron-выражение для расписания вида "cron 0 9 * * 1-5"
+}
+
+// minDigestInterval — самый частый интервал регулярной сводки чата. Сводки
+// идут через общий лимит рассылки, и частое расписание задерживало бы
+// уведомления других чатов.
+const minDigestInterval = time.Hour
+
+// errDigestTooOften — расписание сводки чаще minDigestInterval
+var errDigestTooOften = errors.New("digest schedule is too frequent")
+
+// chatCronParser разбирает расписания, которые задают сами чаты: только
+// пятипольные выражения, без @every и других дескрипторов
+var chatCronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// parseDigestSchedule разбирает расписание вида "daily 09:00", "weekly mon 09:00"
+// или "cron 0 9 * * 1-5"
+func parseDigestSchedule(s string) (digestSchedule, error) {
+	fields := strings.Fields(strings.ToLower(s))
+	var d digestSchedule
+	switch {
+	case len(fields) > 1 && fields[0] == "cron":
+		d.spec = strings.Join(fields[1:], " ")
+		schedule, err := chatCronParser.Parse(d.spec)
+		if err != nil {
+			return d, err
+		}
+		if tooFrequent(schedule, time.Now()) {
+			return d, errDigestTooOften
+		}
+		return d, nil
+	case len(fields) == 2 && fields[0] == "daily":
+	case len(fields) == 3 && fields[0] == "weekly":
+		weekday, ok := weekdays[fields[1]]
+		if !ok {
+			return d, fmt.Errorf("unknown weekday %q", fields[1])
+		}
+		d.weekly, d.weekday = true, weekday
+	default:
+		return d, fmt.Errorf("expected daily HH:MM, weekly DAY HH:MM or cron EXPR")
+	}
+
+	clock, err := time.Parse("15:04", fields[len(fields)-1])
+	if err != nil {
+		return d, err
+	}
+	d.hour, d.minute = clock.Hour(), clock.Minute()
+	return d, nil
+}
+
+// tooFrequent сообщает, что между соседними из ближайших запусков меньше
+// minDigestInterval. Проверяется несколько запусков подряд: в выражениях
+// вроде "0,59 9,10 * * *" короткий промежуток бывает не первым.
+func tooFrequent(schedule cron.Schedule, now time.Time) bool {
+	prev := schedule.Next(now)
+	for i := 0; i < 24; i++ {
+		next := schedule.Next(prev)
+		if next.IsZero() {
+			return false
+		}
+		if next.Sub(prev) < minDigestInterval {
+			return true
+		}
+		prev = next
+	}
+	return false
+}
+
+func (d digestSchedule) String() string {
+	if d.spec != "" {
+		return "cron " + d.spec
+	}
+	if d.weekly {
+		return fmt.Sprintf("weekly %s %02d:%02d", strings.ToLower(d.weekday.String()[:3]), d.hour, d.minute)
+	}
+	return fmt.Sprintf("daily %02d:%02d", d.hour, d.minute)
+}
+
+// cronSpec возвращает расписание в виде cron-выражения
+func (d digestSchedule) cronSpec() string {
+	if d.spec != "" {
+		return d.spec
+	}
+	if d.weekly {
+		return fmt.Sprintf("%d %d * * %d", d.minute, d.hour, d.weekday)
+	}
+	return fmt.Sprintf("%d %d * * *", d.minute, d.hour)
+}
+
+// period — за какой срок сводка подводит итоги. Для cron-расписания это время
+// с предыдущей сводки (last), а для первой — сутки.
+func (d digestSchedule) period(now, last time.Time) time.Duration {
+	switch {
+	case d.weekly:
+		return 7 * 24 * time.Hour
+	case d.spec == "" || last.IsZero():
+		return 24 * time.Hour
+	}
+	return now.Sub(last)
+}
+
+// digestSentKey — ключ состояния со временем последней сводки чата
+func digestSentKey(chatID int64) string {
+	return "digest_sent:" + strconv.FormatInt(chatID, 10)
+}
+
+// sendChatDigest отправляет регулярную сводку чату, если он ещё подписан, в
+// тему форума чата. Пока чат отключил уведомления (/snooze), сводка не
+// приходит, а в тихие часы приходит без звука: время сводки чат выбрал сам,
+// поэтому она не откладывается, как некритичные уведомления.
+func sendChatDigest(chatID int64, schedule digestSchedule) {
+	chat, ok, err := store.Chat(chatID)
+	if err != nil {
+		slog.Error("Error loading chat", "chat_id", chatID, "error", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if snoozed(chat.Settings, now) {
+		// Следующая сводка с cron-расписанием охватит и пропущенный период
+		return
+	}
+	key := digestSentKey(chatID)
+	var last time.Time
+	if value, err := store.State(key); err == nil && value != "" {
+		last, _ = time.Parse(time.RFC3339, value)
+	}
+	if err := store.SetState(key, now.Format(time.RFC3339)); err != nil {
+		slog.Error("Error saving digest time", "chat_id", chatID, "error", err)
+	}
+
+	lang := settingsLang(chat.Settings)
+	msg := newMarkupMessage(chatID, buildDigest(lang, chat, schedule.period(now, last), now))
+	msg.DisableNotification = inQuietHours(chat.Settings, now)
+	enqueueTopicMessage(context.Background(), chatID, chatTopic(chat.Settings, ""), nil, msg)
+}
+
+// buildDigest подводит итоги за период: доступность и простой консолей со
+// сбоями, число консолей без сбоев и консоли, недоступные сейчас.
+// Результат размечен для newMarkupMessage.
+func buildDigest(lang string, chat Chat, period time.Duration, now time.Time) string {
+	from := now.Add(-period)
+	sections := []string{trMarkup(lang, "report.header", escapeText(formatDuration(lang, period)))}
+
+	for _, endpoint := range cfg().endpoints() {
+		consoles, err := monitor.ParseStatuses(statusEngine().Last(endpoint.Name))
+		if err != nil {
+			continue
+		}
+
+		var lines, down []string
+		healthy := 0
+		for _, console := range consoles {
+			if len(chat.Consoles) > 0 && !containsString(chat.Consoles, console.Name) {
+				continue
+			}
+			if isDown(console.Status) {
+				down = append(down, withStatusEmoji(console.Status, bold(consoleName(lang, console.Name))))
+			}
+
+			entries, err := store.History(endpoint.Name, console.Name, from)
+			if err != nil {
+				slog.Error("Error loading history", "endpoint", endpoint.Name, "console", console.Name, "error", err)
+				continue
+			}
+			a := computeAvailability(entries, console.Status, from, now)
+			if a.incidents == 0 && a.downtime == 0 {
+				healthy++
+				continue
+			}
+			// Моноширинный спарклайн в начале строки выравнивается по консолям
+			lines = append(lines, code(sparkline(entries, console.Status, from, now))+" "+trMarkup(lang, "report.console", bold(consoleName(lang, console.Name)),
+				escapeText(fmt.Sprintf("%.2f", a.percent)), escapeText(formatDuration(lang, a.downtime)), a.incidents))
+		}
+
+		if healthy > 0 {
+			lines = append(lines, trMarkup(lang, "report.healthy", healthy))
+		}
+		if len(down) > 0 {
+			lines = append(lines, trMarkup(lang, "report.down_now", strings.Join(down, ", ")))
+		} else {
+			lines = append(lines, trMarkup(lang, "report.all_up"))
+		}
+		sections = append(sections, trMarkup(lang, "history.endpoint", escapeText(endpoint.Name), strings.Join(lines, "\n")))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// handleDigest обрабатывает /digest daily 09:00, /digest weekly mon 09:00,
+// /digest cron <выражение>, /digest off и /digest без аргументов
+func handleDigest(chatID int64, args string) {
+	args = strings.TrimSpace(args)
+
+	switch args {
+	case "":
+		settings, err := store.ChatSettings(chatID)
+		if err != nil {
+			slog.Error("Error loading chat settings", "chat_id", chatID, "error", err)
+		}
+		if value := settings[digestSetting]; value != "" {
+			reply(chatID, "report.current", value)
+			return
+		}
+		reply(chatID, "report.none")
+		return
+	case "off":
+		if err := store.SetChatSetting(chatID, digestSetting, ""); err != nil {
+			slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+			reply(chatID, "error.save")
+			return
+		}
+		scheduleChatDigest(chatID, "")
+		reply(chatID, "report.off")
+		return
+	}
+
+	schedule, err := parseDigestSchedule(args)
+	if errors.Is(err, errDigestTooOften) {
+		reply(chatID, "report.too_often", formatDuration(chatLang(chatID), minDigestInterval))
+		return
+	}
+	if err != nil {
+		reply(chatID, "report.usage")
+		return
+	}
+	if err := store.SetChatSetting(chatID, digestSetting, schedule.String()); err != nil {
+		slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+	scheduleChatDigest(chatID, schedule.String())
+	reply(chatID, "report.set", schedule)
+}
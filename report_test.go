@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDigestSchedule(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{in: "daily 09:00", want: "daily 09:00"},
+		{in: "weekly mon 9:30", want: "weekly mon 09:30"},
+		{in: "cron 0 9 * * 1-5", want: "cron 0 9 * * 1-5"},
+		{in: "cron 0 */2 * * *", want: "cron 0 */2 * * *"},
+		{in: "cron * * * * *", err: errDigestTooOften},
+		{in: "cron */30 * * * *", err: errDigestTooOften},
+		{in: "cron 0,59 9,10 * * *", err: errDigestTooOften},
+	}
+	for _, tt := range tests {
+		got, err := parseDigestSchedule(tt.in)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("parseDigestSchedule(%q) error = %v, want %v", tt.in, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf("parseDigestSchedule(%q) = %s, %v; want %s", tt.in, got, err, tt.want)
+		}
+	}
+
+	// Дескрипторы вроде @every доступны только расписаниям из конфигурации
+	for _, in := range []string{"cron @every 1s", "cron @hourly"} {
+		if _, err := parseDigestSchedule(in); err == nil {
+			t.Errorf("parseDigestSchedule(%q) accepted a descriptor", in)
+		}
+	}
+}
+
+func TestSendChatDigest(t *testing.T) {
+	now := time.Now()
+	quiet := now.Add(-time.Hour).Format("15:04") + "-" + now.Add(time.Hour).Format("15:04")
+
+	tests := []struct {
+		name     string
+		settings map[string]string
+		want     int    // Сообщений через sendMessage без темы
+		thread   string // Тема, в которую ушла сводка
+		silent   bool
+	}{
+		{name: "обычная", want: 1},
+		{name: "snooze", settings: map[string]string{snoozeSetting: now.Add(time.Hour).Format(time.RFC3339)}},
+		{name: "тихие часы", settings: map[string]string{quietHoursSetting: quiet}, want: 1, silent: true},
+		{name: "тема форума", settings: map[string]string{topicSetting: "7"}, thread: "7"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := setupTestBot(t)
+			chatID := int64(600 + i)
+			if err := store.AddChat(chatID); err != nil {
+				t.Fatal(err)
+			}
+			for key, value := range tt.settings {
+				if err := store.SetChatSetting(chatID, key, value); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			startSender(cfg().Sender)
+			sendChatDigest(chatID, digestSchedule{hour: 9})
+			stopSender()
+
+			sent := fake.sentTo(chatID)
+			if len(sent) != tt.want {
+				t.Fatalf("sent %d messages, want %d", len(sent), tt.want)
+			}
+			for _, m := range sent {
+				if m.DisableNotification != tt.silent {
+					t.Errorf("DisableNotification = %v, want %v", m.DisableNotification, tt.silent)
+				}
+			}
+			var threads []string
+			for _, params := range fake.requests {
+				threads = append(threads, params["message_thread_id"])
+			}
+			if tt.thread != "" && (len(threads) != 1 || threads[0] != tt.thread) {
+				t.Errorf("sent to topics %v, want %s", threads, tt.thread)
+			}
+			if tt.thread == "" && len(threads) != 0 {
+				t.Errorf("sent to topics %v, want none", threads)
+			}
+		})
+	}
+}
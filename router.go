@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"strings"
+)
+
+// CommandHandler обрабатывает команду бота или обычное сообщение
+type CommandHandler func(ctx context.Context, message *tgbotapi.Message)
+
+// CallbackHandler обрабатывает нажатие inline-кнопки. payload — часть данных
+// кнопки после первого двоеточия ("ack:42" → "42").
+type CallbackHandler func(ctx context.Context, query *tgbotapi.CallbackQuery, payload string)
+
+// ChatMemberHandler обрабатывает изменение статуса бота в чате
+type ChatMemberHandler func(ctx context.Context, update *tgbotapi.ChatMemberUpdated)
+
+// InlineQueryHandler обрабатывает inline-запрос @бот в любом чате
+type InlineQueryHandler func(ctx context.Context, query *tgbotapi.InlineQuery)
+
+type commandRoute struct {
+	handler   CommandHandler
+	adminOnly bool
+}
+
+// Router раскладывает обновления Telegram по зарегистрированным обработчикам
+type Router struct {
+	commands     map[string]commandRoute
+	callbacks    map[string]CallbackHandler
+	interceptors []func(ctx context.Context, message *tgbotapi.Message) bool
+	edited       []CommandHandler
+	myChatMember []ChatMemberHandler
+	inlineQuery  InlineQueryHandler
+}
+
+func newRouter() *Router {
+	return &Router{
+		commands:  make(map[string]commandRoute),
+		callbacks: make(map[string]CallbackHandler),
+	}
+}
+
+// Command регистрирует обработчик команды /name
+func (r *Router) Command(name string, handler CommandHandler) {
+	r.commands[name] = commandRoute{handler: handler}
+}
+
+// AdminCommand регистрирует команду, доступную только администраторам
+func (r *Router) AdminCommand(name string, handler CommandHandler) {
+	r.commands[name] = commandRoute{handler: handler, adminOnly: true}
+}
+
+// Callback регистрирует обработчик кнопок, данные которых равны prefix
+// или начинаются с "prefix:"
+func (r *Router) Callback(prefix string, handler CallbackHandler) {
+	r.callbacks[prefix] = handler
+}
+
+// Intercept регистрирует обработчик, который видит каждое новое сообщение
+// раньше команд и может забрать его себе, вернув true
+func (r *Router) Intercept(handler func(ctx context.Context, message *tgbotapi.Message) bool) {
+	r.interceptors = append(r.interceptors, handler)
+}
+
+// EditedMessage регистрирует обработчик отредактированных сообщений
+func (r *Router) EditedMessage(handler CommandHandler) {
+	r.edited = append(r.edited, handler)
+}
+
+// MyChatMember регистрирует обработчик изменений статуса бота в чатах
+func (r *Router) MyChatMember(handler ChatMemberHandler) {
+	r.myChatMember = append(r.myChatMember, handler)
+}
+
+// InlineQuery регистрирует обработчик inline-запросов
+func (r *Router) InlineQuery(handler InlineQueryHandler) {
+	r.inlineQuery = handler
+}
+
+// Dispatch передаёт обновление подходящим обработчикам
+func (r *Router) Dispatch(ctx context.Context, update tgbotapi.Update) {
+	var tags map[string]string
+	if chat := update.FromChat(); chat != nil {
+		tags = chatTags(chat.ID)
+	}
+	defer recoverPanic("update", tags)
+
+	switch {
+	case update.Message != nil:
+		for _, intercept := range r.interceptors {
+			if intercept(ctx, update.Message) {
+				return
+			}
+		}
+		r.DispatchCommand(ctx, update.Message)
+	case update.EditedMessage != nil:
+		for _, handler := range r.edited {
+			handler(ctx, update.EditedMessage)
+		}
+	case update.CallbackQuery != nil:
+		r.dispatchCallback(ctx, update.CallbackQuery)
+	case update.MyChatMember != nil:
+		for _, handler := range r.myChatMember {
+			handler(ctx, update.MyChatMember)
+		}
+	case update.InlineQuery != nil:
+		if r.inlineQuery != nil {
+			r.inlineQuery(ctx, update.InlineQuery)
+		}
+	}
+}
+
+// DispatchCommand вызывает обработчик команды из сообщения, если он есть
+func (r *Router) DispatchCommand(ctx context.Context, message *tgbotapi.Message) {
+	route, ok := r.commands[message.Command()]
+	if !ok {
+		return
+	}
+	if route.adminOnly && !requireAdmin(message) {
+		return
+	}
+	route.handler(ctx, message)
+}
+
+func (r *Router) dispatchCallback(ctx context.Context, query *tgbotapi.CallbackQuery) {
+	prefix, payload := query.Data, ""
+	if i := strings.IndexByte(query.Data, ':'); i >= 0 {
+		prefix, payload = query.Data[:i], query.Data[i+1:]
+	}
+
+	handler, ok := r.callbacks[prefix]
+	if !ok {
+		// Убираем «часики» на кнопке, даже если обработчика нет
+		bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	handler(ctx, query, payload)
+}
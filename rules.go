@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+	"log/slog"
+	"status-bot/monitor"
+)
+
+// RuleConfig — правило оповещения на языке выражений expr
+// (https://expr-lang.org): первое правило, у которого when истинно, решает,
+// уходит ли изменение консоли в уведомления и насколько оно важно.
+// Изменения, не подошедшие ни под одно правило, рассылаются как обычно.
+type RuleConfig struct {
+	When     string `yaml:"when" json:"when"`         // Условие, например console.Status == "Error" && console.Name matches "^prod-"
+	Notify   *bool  `yaml:"notify" json:"notify"`     // false — не уведомлять об изменении; по умолчанию true
+	Severity string `yaml:"severity" json:"severity"` // critical или info; пусто — по статусу (недоступность критична)
+}
+
+// RuleConsole — переменная console в выражениях правил
+type RuleConsole struct {
+	Name     string // Имя консоли
+	Status   string // Новый статус; то же, что New
+	Old      string // Предыдущий статус; пусто — консоль появилась
+	New      string // Новый статус; пусто — консоль пропала
+	Kind     string // changed, down или recovered
+	Endpoint string // Имя API
+}
+
+// ruleEnv — переменные, доступные в выражениях правил
+type ruleEnv struct {
+	Console  RuleConsole `expr:"console"`
+	Endpoint string      `expr:"endpoint"`
+}
+
+// rule — правило с разобранным выражением
+type rule struct {
+	config  RuleConfig
+	program *vm.Program
+}
+
+func (c RuleConfig) compile() (rule, error) {
+	if c.When == "" {
+		return rule{}, fmt.Errorf("when must be set")
+	}
+	switch c.Severity {
+	case "", severityInfo, severityCritical:
+	default:
+		return rule{}, fmt.Errorf("unknown severity %q, expected info or critical", c.Severity)
+	}
+	program, err := expr.Compile(c.When, expr.Env(ruleEnv{}), expr.AsBool())
+	if err != nil {
+		return rule{}, err
+	}
+	return rule{config: c, program: program}, nil
+}
+
+// compileRules разбирает правила из конфигурации
+func compileRules(configs []RuleConfig) ([]rule, error) {
+	result := make([]rule, 0, len(configs))
+	for i, config := range configs {
+		r, err := config.compile()
+		if err != nil {
+			return nil, fmt.Errorf("rules[%d]: %w", i, err)
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// applyRules убирает изменения, о которых правила велят не уведомлять, и
+// возвращает важность, назначенную правилами, по именам консолей
+func applyRules(endpoint string, changes []monitor.StatusChange, transitions map[string]transition) ([]monitor.StatusChange, map[string]string) {
+	rules := snapshot().rules
+	if len(rules) == 0 {
+		return changes, nil
+	}
+
+	severities := make(map[string]string)
+	var result []monitor.StatusChange
+	for _, change := range changes {
+		env := ruleEnv{
+			Console: RuleConsole{
+				Name:     change.Name,
+				Status:   change.New,
+				Old:      change.Old,
+				New:      change.New,
+				Kind:     transitions[change.Name].kind,
+				Endpoint: endpoint,
+			},
+			Endpoint: endpoint,
+		}
+
+		notify := true
+		for _, r := range rules {
+			matched, err := expr.Run(r.program, env)
+			if err != nil {
+				slog.Error("Error evaluating rule", "rule", r.config.When, "error", err)
+				continue
+			}
+			if ok, _ := matched.(bool); !ok {
+				continue
+			}
+			if r.config.Notify != nil {
+				notify = *r.config.Notify
+			}
+			if r.config.Severity != "" {
+				severities[change.Name] = r.config.Severity
+			}
+			break
+		}
+		if notify {
+			result = append(result, change)
+		}
+	}
+	return result, severities
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"status-bot/monitor"
+	"sync/atomic"
+)
+
+// runtimeState — конфигурация и всё, что из неё собрано. Перечитывание
+// конфигурации заменяет снимок целиком одной записью, поэтому горутины видят
+// либо прежние настройки, либо новые, но не их смесь.
+type runtimeState struct {
+	config     Config
+	sources    map[string]monitor.Source // Источники API по их именам
+	templates  notificationTemplates
+	rules      []rule
+	thresholds []threshold
+	engine     *monitor.Engine // Проверки API и последние полученные статусы
+}
+
+var current atomic.Pointer[runtimeState]
+
+func init() {
+	current.Store(&runtimeState{config: defaultConfig()})
+}
+
+// snapshot возвращает текущий снимок настроек. Значения снимка не меняются:
+// функции, которым нужны согласованные настройки, берут снимок один раз.
+func snapshot() *runtimeState {
+	return current.Load()
+}
+
+// setRuntime атомарно заменяет снимок настроек
+func setRuntime(r *runtimeState) {
+	current.Store(r)
+}
+
+// setConfig заменяет конфигурацию, оставляя собранное из прежней
+func setConfig(config Config) {
+	next := *snapshot()
+	next.config = config
+	setRuntime(&next)
+}
+
+// buildRuntime собирает из конфигурации источники, шаблоны, правила, пороги
+// и проверки API. Проверки не запускаются.
+func buildRuntime(config Config) (*runtimeState, error) {
+	next := &runtimeState{config: config}
+	var err error
+	if next.sources, err = newSources(config.endpoints()); err != nil {
+		return nil, err
+	}
+	if next.templates, err = config.Templates.compile(); err != nil {
+		return nil, err
+	}
+	if next.rules, err = compileRules(config.Rules); err != nil {
+		return nil, err
+	}
+	if next.thresholds, err = compileThresholds(config.Thresholds); err != nil {
+		return nil, err
+	}
+	next.engine = newEngine(config, next.sources)
+	return next, nil
+}
+
+// cfg — текущая конфигурация
+func cfg() *Config {
+	return &snapshot().config
+}
+
+// statusEngine — проверки API текущей конфигурации
+func statusEngine() *monitor.Engine {
+	return snapshot().engine
+}
+
+// endpointSource — источник API с этим именем; nil, если такого API нет
+func endpointSource(name string) monitor.Source {
+	return snapshot().sources[name]
+}
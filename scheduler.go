@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"github.com/robfig/cron/v3"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Действия регулярных задач из конфигурации (schedules)
+const (
+	actionHealthSummary = "health_summary" // Сводка о состоянии проверок API
+	actionReminder      = "reminder"       // Произвольный текст, например напоминание о работах
+)
+
+// ScheduleConfig — регулярная задача из конфигурации
+type ScheduleConfig struct {
+	Name   string  `yaml:"name" json:"name"`     // Имя для журнала
+	Cron   string  `yaml:"cron" json:"cron"`     // Расписание: "0 9 * * 1-5" или "@daily"
+	Action string  `yaml:"action" json:"action"` // health_summary или reminder
+	Text   string  `yaml:"text" json:"text"`     // Текст напоминания для reminder
+	Chats  []int64 `yaml:"chats" json:"chats"`   // Получатели; по умолчанию администраторы (health_summary) или все подписчики (reminder)
+}
+
+// cronParser понимает стандартные пятипольные выражения и @daily, @every 1h и т. п.
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+func (c ScheduleConfig) validate() error {
+	if _, err := cronParser.Parse(c.Cron); err != nil {
+		return fmt.Errorf("schedule %q: invalid cron %q: %w", c.Name, c.Cron, err)
+	}
+	switch c.Action {
+	case actionHealthSummary:
+	case actionReminder:
+		if c.Text == "" {
+			return fmt.Errorf("schedule %q: text must be set for reminder", c.Name)
+		}
+	default:
+		return fmt.Errorf("schedule %q: unknown action %q, expected health_summary or reminder", c.Name, c.Action)
+	}
+	return nil
+}
+
+var (
+	scheduler *cron.Cron
+
+	chatJobsMutex = &sync.Mutex{}
+	chatJobs      = make(map[int64]cron.EntryID) // Задача регулярной сводки каждого чата
+)
+
+// recoverJob не даёт панике в задаче остановить планировщик и сообщает о ней
+func recoverJob(job cron.Job) cron.Job {
+	return cron.FuncJob(func() {
+		defer recoverPanic("scheduled job", nil)
+		job.Run()
+	})
+}
+
+// startScheduler запускает планировщик: отложенные уведомления, задачи из
+// конфигурации и регулярные сводки чатов
+func startScheduler() {
+	scheduler = cron.New(cron.WithParser(cronParser), cron.WithChain(recoverJob))
+
+	// Отложенные уведомления (тихие часы, /frequency) проверяются каждую минуту
+	scheduler.AddFunc("@every 1m", flushDigests)
+	scheduler.AddFunc("@every 1m", closeMaintenance)
+	scheduler.AddFunc("@every 1m", expireMutes)
+	if cfg().Escalation.Chat != 0 {
+		scheduler.AddFunc("@every 1m", escalateUnacked)
+	}
+	if cfg().Ack.RemindEvery.Duration > 0 {
+		scheduler.AddFunc("@every 1m", remindUnacked)
+	}
+	if cfg().Flapping.Transitions > 0 {
+		scheduler.AddFunc("@every 1m", resolveFlapping)
+	}
+
+	for _, schedule := range cfg().Schedules {
+		schedule := schedule
+		if _, err := scheduler.AddFunc(schedule.Cron, func() { runSchedule(schedule) }); err != nil {
+			slog.Error("Error scheduling", "job", schedule.Name, "error", err)
+		}
+	}
+
+	chats, err := store.Chats()
+	if err != nil {
+		slog.Error("Error loading chats", "error", err)
+	}
+	for _, chat := range chats {
+		scheduleChatDigest(chat.ID, chat.Settings[digestSetting])
+	}
+
+	scheduler.Start()
+}
+
+// stopScheduler останавливает планировщик и ждёт завершения запущенных задач
+func stopScheduler() {
+	<-scheduler.Stop().Done()
+}
+
+// scheduleChatDigest заменяет задачу регулярной сводки чата; пустое или
+// неверное расписание только удаляет прежнюю задачу
+func scheduleChatDigest(chatID int64, setting string) {
+	chatJobsMutex.Lock()
+	defer chatJobsMutex.Unlock()
+
+	if id, ok := chatJobs[chatID]; ok {
+		scheduler.Remove(id)
+		delete(chatJobs, chatID)
+	}
+	if setting == "" {
+		return
+	}
+
+	schedule, err := parseDigestSchedule(setting)
+	if err != nil {
+		slog.Warn("Invalid digest schedule", "schedule", setting, "chat_id", chatID, "error", err)
+		return
+	}
+	id, err := scheduler.AddFunc(schedule.cronSpec(), func() { sendChatDigest(chatID, schedule) })
+	if err != nil {
+		slog.Error("Error scheduling digest", "chat_id", chatID, "error", err)
+		return
+	}
+	chatJobs[chatID] = id
+}
+
+// runSchedule выполняет задачу из конфигурации
+func runSchedule(schedule ScheduleConfig) {
+	slog.Info("Running scheduled job", "job", schedule.Name)
+
+	recipients := schedule.Chats
+	if len(recipients) == 0 {
+		if schedule.Action == actionHealthSummary {
+			recipients = cfg().Admins
+		} else {
+			chats, err := store.Chats()
+			if err != nil {
+				slog.Error("Error loading chats", "error", err)
+				return
+			}
+			for _, chat := range chats {
+				recipients = append(recipients, chat.ID)
+			}
+		}
+	}
+
+	for _, chatID := range recipients {
+		lang := chatLang(chatID)
+		var text string
+		switch schedule.Action {
+		case actionHealthSummary:
+			text = healthSummary(lang)
+		case actionReminder:
+			text = escapeText(schedule.Text)
+		}
+		enqueueMessage(chatID, newMarkupMessage(chatID, text))
+	}
+}
+
+// healthSummary описывает состояние последних проверок каждого API.
+// Результат размечен для newMarkupMessage.
+func healthSummary(lang string) string {
+	report := buildHealthReport(true)
+	lines := []string{trMarkup(lang, "health.header")}
+	for _, endpoint := range cfg().endpoints() {
+		r := report.Endpoints[endpoint.Name]
+		name := escapeText(endpoint.Name)
+		switch {
+		case r.OK:
+			lines = append(lines, trMarkup(lang, "health.ok", name))
+		case r.Failed == 0:
+			lines = append(lines, trMarkup(lang, "health.pending", name))
+		default:
+			lines = append(lines, trMarkup(lang, "health.failing", name, r.Failed, r.Recent, code(r.LastError)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"io/ioutil"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// getenvSecret возвращает значение переменной окружения name, а если она
+// не задана — содержимое файла из переменной name_FILE или секрет из Vault
+// (vault.secrets). Так читаются секреты Docker и Kubernetes, смонтированные
+// файлами; завершающий перевод строки отбрасывается.
+func getenvSecret(name string) string {
+	if name == "" {
+		return ""
+	}
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		value, _ := vaultSecret(name)
+		return value
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		slog.Error("Error reading secret file", "env", name+"_FILE", "error", err)
+		return ""
+	}
+	return strings.TrimRight(string(data), "\r\n")
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+	"log/slog"
+	"net/http"
+	"status-bot/monitor"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SenderConfig ограничивает скорость рассылки, чтобы не упираться в лимиты Telegram
+// (около 30 сообщений в секунду на бота)
+type SenderConfig struct {
+	Rate      float64 `yaml:"rate" json:"rate"`             // Сообщений в секунду
+	Burst     int     `yaml:"burst" json:"burst"`           // Сколько сообщений можно отправить подряд без ожидания
+	QueueSize int     `yaml:"queue_size" json:"queue_size"` // Размер очереди; при переполнении рассылка ждёт
+}
+
+func (c SenderConfig) validate() error {
+	if c.Rate <= 0 {
+		return fmt.Errorf("sender.rate must be positive")
+	}
+	if c.Burst <= 0 {
+		return fmt.Errorf("sender.burst must be positive")
+	}
+	if c.QueueSize <= 0 {
+		return fmt.Errorf("sender.queue_size must be positive")
+	}
+	return nil
+}
+
+// outgoingMessage — сообщение в очереди рассылки
+type outgoingMessage struct {
+	chatID    int64
+	msg       tgbotapi.Chattable
+	thread    int               // Тема форума; 0 — без темы
+	incidents []int             // Инциденты, о которых сообщает уведомление (см. recordAlert)
+	trace     trace.SpanContext // Спан, из которого поставлено сообщение
+}
+
+// maxSendRetries — сколько раз повторить запрос, на который Telegram ответил
+// 429 Too Many Requests
+const maxSendRetries = 3
+
+var (
+	sendQueue chan outgoingMessage
+	senderWG  sync.WaitGroup
+	// sendLimiter общий для рассылки и редактирования уведомлений
+	sendLimiter *rate.Limiter
+)
+
+// startSender запускает обработчик очереди рассылки
+func startSender(config SenderConfig) {
+	sendQueue = make(chan outgoingMessage, config.QueueSize)
+	limiter := rate.NewLimiter(rate.Limit(config.Rate), config.Burst)
+	sendLimiter = limiter
+
+	senderWG.Add(1)
+	go func() {
+		defer senderWG.Done()
+		for out := range sendQueue {
+			ctx, span := tracer.Start(trace.ContextWithSpanContext(context.Background(), out.trace), "telegram.send",
+				trace.WithAttributes(attribute.Int64("chat_id", out.chatID)))
+			// Очередь дочитывается и при остановке, поэтому контекст не отменяется;
+			// ожидание лимита — отдельный спан, чтобы задержки рассылки были видны
+			_, wait := tracer.Start(ctx, "telegram.rate_limit")
+			limiter.Wait(context.Background())
+			wait.End()
+			deliver(ctx, out)
+			span.End()
+		}
+	}()
+}
+
+// stopSender закрывает очередь и ждёт, пока уйдут оставшиеся сообщения
+func stopSender() {
+	close(sendQueue)
+	senderWG.Wait()
+}
+
+// enqueueMessage ставит уведомление в очередь рассылки; слишком длинное
+// сообщение ставится по частям
+func enqueueMessage(chatID int64, msg tgbotapi.Chattable) {
+	enqueueMessageContext(context.Background(), chatID, msg)
+}
+
+// enqueueMessageContext — enqueueMessage, продолжающий трассу из ctx
+func enqueueMessageContext(ctx context.Context, chatID int64, msg tgbotapi.Chattable) {
+	enqueueTopicMessage(ctx, chatID, 0, nil, msg)
+}
+
+// enqueueTopicMessage — enqueueMessageContext в тему форума thread. Уведомление
+// об открытых инцидентах запоминается после отправки, чтобы дописывать в него
+// продолжения; у длинного — последняя часть, под которой кнопки.
+func enqueueTopicMessage(ctx context.Context, chatID int64, thread int, incidents []int, msg tgbotapi.Chattable) {
+	span := trace.SpanContextFromContext(ctx)
+	parts := splitOversized(msg)
+	for i, part := range parts {
+		out := outgoingMessage{chatID: chatID, msg: part, thread: thread, trace: span}
+		if i == len(parts)-1 {
+			out.incidents = incidents
+		}
+		sendQueue <- out
+	}
+}
+
+func deliver(ctx context.Context, out outgoingMessage) {
+	defer recoverPanic("sender", chatTags(out.chatID))
+
+	key := fmt.Sprintf("send:%d", out.chatID)
+	var sent tgbotapi.Message
+	err := withRetry(ctx, func() (err error) {
+		sent, err = sendToTopic(out.msg, out.thread)
+		return err
+	})
+	if err != nil {
+		if out.thread != 0 && isTopicMissing(err) {
+			// Тему удалили или закрыли — лучше доставить без темы, чем потерять
+			slog.Warn("Forum topic is unavailable, sending without topic", "chat_id", out.chatID, "thread_id", out.thread, "error", err)
+			deliver(ctx, outgoingMessage{chatID: out.chatID, msg: out.msg, incidents: out.incidents, trace: out.trace})
+			return
+		}
+		if newID := migratedChatID(err); newID != 0 {
+			// Группа стала супергруппой — переносим подписку и отправляем повторно.
+			// Тем в новой супергруппе ещё нет.
+			migrateChat(out.chatID, newID)
+			if msg, ok := withChatID(out.msg, newID); ok {
+				deliver(ctx, outgoingMessage{chatID: newID, msg: msg, incidents: out.incidents, trace: out.trace})
+				return
+			}
+		}
+
+		sendErrors.Inc()
+		span := trace.SpanFromContext(ctx)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if isChatUnreachable(err) {
+			unsubscribeUnreachable(out.chatID, err)
+			return
+		}
+		slog.Error("Error sending message", "chat_id", out.chatID, "error", err)
+		reportRepeated(key, "Error sending message", err, chatTags(out.chatID))
+		return
+	}
+	repeatedErrors.record(key, nil)
+	if len(out.incidents) > 0 {
+		recordAlert(out.chatID, sent, out.msg, out.incidents)
+	}
+	notificationsSent.Inc()
+	atomic.AddInt64(&notificationsTotal, 1)
+}
+
+// editMessage редактирует отправленное уведомление. Запрос проходит через тот
+// же лимит скорости и повторы, что и рассылка, а недоступный чат отписывается.
+func editMessage(ctx context.Context, chatID int64, edit tgbotapi.Chattable) error {
+	if sendLimiter != nil {
+		if err := sendLimiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	err := withRetry(ctx, func() error {
+		_, err := bot.Request(edit)
+		return err
+	})
+	if isChatUnreachable(err) {
+		unsubscribeUnreachable(chatID, err)
+	}
+	return err
+}
+
+// withRetry выполняет запрос к Bot API и повторяет его, пока Telegram отвечает
+// 429 Too Many Requests, выждав указанные в ответе retry_after секунд
+func withRetry(ctx context.Context, request func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := request()
+		delay := retryAfter(err)
+		if delay == 0 || attempt == maxSendRetries {
+			return err
+		}
+		slog.Warn("Telegram flood control, retrying", "error", err, "retry_in", delay.String())
+		if !monitor.SleepContext(ctx, delay) {
+			return err
+		}
+	}
+}
+
+// retryAfter возвращает паузу, которую Telegram просит выждать перед
+// повтором, или 0, если ошибка не про превышение лимита
+func retryAfter(err error) time.Duration {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) || tgErr.Code != http.StatusTooManyRequests {
+		return 0
+	}
+	return time.Duration(max(tgErr.RetryAfter, 1)) * time.Second
+}
+
+// unsubscribeUnreachable отписывает чат, который больше не примет сообщения:
+// повторять бессмысленно
+func unsubscribeUnreachable(chatID int64, err error) {
+	slog.Warn("Chat is unreachable, unsubscribing", "chat_id", chatID, "error", err)
+	if err := store.RemoveChat(chatID); err != nil {
+		slog.Error("Error removing chat", "chat_id", chatID, "error", err)
+	}
+}
+
+// isChatUnreachable сообщает, что Telegram больше не примет сообщения для чата:
+// бот заблокирован пользователем, удалён из группы или чат не существует
+func isChatUnreachable(err error) bool {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return false
+	}
+	if tgErr.Code == http.StatusForbidden {
+		return true
+	}
+	return tgErr.Code == http.StatusBadRequest && strings.Contains(strings.ToLower(tgErr.Message), "chat not found")
+}
+
+// migratedChatID возвращает новый ID чата, если Telegram сообщил о переходе
+// группы в супергруппу, иначе 0
+func migratedChatID(err error) int64 {
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) {
+		return tgErr.MigrateToChatID
+	}
+	return 0
+}
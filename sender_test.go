@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// flakyTelegram отвечает на запросы заданными ошибками, а затем успехом
+type flakyTelegram struct {
+	*fakeTelegram
+
+	errors   []error
+	requests int
+}
+
+func (b *flakyTelegram) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	b.requests++
+	if len(b.errors) > 0 {
+		err := b.errors[0]
+		b.errors = b.errors[1:]
+		return nil, err
+	}
+	return b.fakeTelegram.Request(c)
+}
+
+func TestEditMessage(t *testing.T) {
+	flood := &tgbotapi.Error{Code: http.StatusTooManyRequests, Message: "Too Many Requests: retry after 1",
+		ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 1}}
+	blocked := &tgbotapi.Error{Code: http.StatusForbidden, Message: "Forbidden: bot was blocked by the user"}
+
+	tests := []struct {
+		name       string
+		errors     []error
+		requests   int
+		err        bool
+		subscribed bool
+	}{
+		{name: "успешно", requests: 1, subscribed: true},
+		{name: "повтор после 429", errors: []error{flood}, requests: 2, subscribed: true},
+		{name: "бот заблокирован", errors: []error{blocked}, requests: 1, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &flakyTelegram{fakeTelegram: setupTestBot(t), errors: tt.errors}
+			bot = fake
+			const chatID = 300
+			if err := store.AddChat(chatID); err != nil {
+				t.Fatal(err)
+			}
+
+			err := editMessage(context.Background(), chatID, tgbotapi.NewEditMessageText(chatID, 1, "x"))
+			if (err != nil) != tt.err {
+				t.Errorf("editMessage() error = %v, want error %v", err, tt.err)
+			}
+			if fake.requests != tt.requests {
+				t.Errorf("made %d requests, want %d", fake.requests, tt.requests)
+			}
+			chats, err := store.Chats()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if subscribed := len(chats) == 1; subscribed != tt.subscribed {
+				t.Errorf("chat subscribed = %v, want %v", subscribed, tt.subscribed)
+			}
+		})
+	}
+}
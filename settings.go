@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"sort"
+	"status-bot/monitor"
+	"strings"
+)
+
+// verbositySetting — настройка чата: о каких изменениях уведомлять
+const verbositySetting = "verbosity"
+
+// Уровни подробности уведомлений
+const (
+	verbosityAll      = "all"      // Обо всех изменениях статуса (по умолчанию)
+	verbosityOutages  = "outages"  // Только когда консоль становится недоступна или восстанавливается
+	verbosityCritical = "critical" // Только о критичных изменениях
+)
+
+var verbosities = []string{verbosityAll, verbosityOutages, verbosityCritical}
+
+// callbackSettings — кнопки меню /settings: "settings:<раздел>[:<значение>]"
+const callbackSettings = "settings"
+
+// Готовые варианты в меню; свои значения задаются командами /quiet и /digest
+var (
+	quietPresets  = []string{"22:00-08:00", "23:00-07:00", "00:00-09:00"}
+	digestPresets = []string{"daily 09:00", "daily 18:00", "weekly mon 09:00"}
+)
+
+// filterVerbosity оставляет изменения, о которых чат просил уведомлять
+func filterVerbosity(changes []monitor.StatusChange, settings map[string]string, event StatusEvent) []monitor.StatusChange {
+	verbosity := settings[verbositySetting]
+	if verbosity == "" || verbosity == verbosityAll {
+		return changes
+	}
+	kept := changes[:0:0]
+	for _, change := range changes {
+		switch verbosity {
+		case verbosityOutages:
+			if isDown(change.Old) != isDown(change.New) {
+				kept = append(kept, change)
+			}
+		case verbosityCritical:
+			if event.criticalChanges([]monitor.StatusChange{change}) {
+				kept = append(kept, change)
+			}
+		}
+	}
+	return kept
+}
+
+// handleVerbosity обрабатывает /verbosity all|outages|critical и /verbosity без аргументов
+func handleVerbosity(chatID int64, args string) {
+	lang := chatLang(chatID)
+	value := strings.ToLower(strings.TrimSpace(args))
+	if value == "" {
+		settings, err := store.ChatSettings(chatID)
+		if err != nil {
+			slog.Error("Error loading chat settings", "chat_id", chatID, "error", err)
+		}
+		reply(chatID, "verbosity.current", verbosityName(lang, settings[verbositySetting]))
+		return
+	}
+	if !containsString(verbosities, value) {
+		reply(chatID, "verbosity.usage")
+		return
+	}
+	if err := saveVerbosity(chatID, value); err != nil {
+		slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+	reply(chatID, "verbosity.set", verbosityName(lang, value))
+}
+
+func saveVerbosity(chatID int64, value string) error {
+	if value == verbosityAll {
+		value = ""
+	}
+	return store.SetChatSetting(chatID, verbositySetting, value)
+}
+
+func verbosityName(lang, value string) string {
+	if value == "" {
+		value = verbosityAll
+	}
+	return tr(lang, "verbosity."+value)
+}
+
+// handleSettings открывает меню настроек чата
+func handleSettings(chatID int64) {
+	text, keyboard := settingsMenu(chatID, "")
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+	bot.Send(msg)
+}
+
+// handleSettingsButton переключает разделы меню и сохраняет выбранные
+// значения, редактируя то же сообщение
+func handleSettingsButton(ctx context.Context, query *tgbotapi.CallbackQuery, payload string) {
+	if query.Message == nil {
+		bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		return
+	}
+	chatID, messageID := query.Message.Chat.ID, query.Message.MessageID
+	section, value, hasValue := strings.Cut(payload, ":")
+
+	if section == "close" {
+		bot.Request(tgbotapi.NewCallback(query.ID, ""))
+		bot.Request(tgbotapi.NewDeleteMessage(chatID, messageID))
+		return
+	}
+
+	notice := ""
+	if hasValue {
+		if err := applySetting(chatID, section, value); err != nil {
+			slog.Error("Error saving chat settings", "chat_id", chatID, "setting", section, "error", err)
+			bot.Request(tgbotapi.NewCallback(query.ID, tr(chatLang(chatID), "error.save")))
+			return
+		}
+		notice = tr(chatLang(chatID), "settings.saved")
+		// После выбора возвращаемся в главное меню; консоли отмечают по одной
+		if section != "consoles" {
+			section = ""
+		}
+	}
+	bot.Request(tgbotapi.NewCallback(query.ID, notice))
+
+	text, keyboard := settingsMenu(chatID, section)
+	bot.Request(tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard))
+}
+
+// applySetting сохраняет значение, выбранное в разделе меню
+func applySetting(chatID int64, section, value string) error {
+	switch section {
+	case "language":
+		if catalogs[value] == nil {
+			return nil
+		}
+		return store.SetChatSetting(chatID, languageSetting, value)
+	case "quiet":
+		if value != "" {
+			q, err := parseQuietHours(value)
+			if err != nil {
+				return err
+			}
+			value = q.String()
+		}
+		return store.SetChatSetting(chatID, quietHoursSetting, value)
+	case "digest":
+		if value != "" {
+			schedule, err := parseDigestSchedule(value)
+			if err != nil {
+				return err
+			}
+			value = schedule.String()
+		}
+		if err := store.SetChatSetting(chatID, digestSetting, value); err != nil {
+			return err
+		}
+		scheduleChatDigest(chatID, value)
+		return nil
+	case "verbosity":
+		if !containsString(verbosities, value) {
+			return nil
+		}
+		return saveVerbosity(chatID, value)
+	case "consoles":
+		return toggleConsole(chatID, value)
+	}
+	return nil
+}
+
+// toggleConsole подписывает чат на консоль или отменяет подписку; пустое имя —
+// снова все консоли
+func toggleConsole(chatID int64, console string) error {
+	chat, _, err := store.Chat(chatID)
+	if err != nil {
+		return err
+	}
+	if console != "" && !containsString(chat.Consoles, console) {
+		return store.AddChatConsole(chatID, console)
+	}
+	for _, c := range chat.Consoles {
+		if console == "" || c == console {
+			if err := store.RemoveChatConsole(chatID, c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// settingsMenu строит текст и кнопки раздела меню; пустой раздел — главное меню
+func settingsMenu(chatID int64, section string) (string, tgbotapi.InlineKeyboardMarkup) {
+	settings, err := store.ChatSettings(chatID)
+	if err != nil {
+		slog.Error("Error loading chat settings", "chat_id", chatID, "error", err)
+	}
+	chat, _, err := store.Chat(chatID)
+	if err != nil {
+		slog.Error("Error loading chat", "chat_id", chatID, "error", err)
+	}
+	lang := settingsLang(settings)
+	off := tr(lang, "settings.off")
+	orOff := func(s string) string {
+		if s == "" {
+			return off
+		}
+		return s
+	}
+	button := func(text, data string) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(text, callbackSettings+":"+data)
+	}
+	// choice отмечает текущее значение
+	choice := func(text, data string, selected bool) tgbotapi.InlineKeyboardButton {
+		if selected {
+			text = "✅ " + text
+		}
+		return button(text, data)
+	}
+	back := tgbotapi.NewInlineKeyboardRow(button(tr(lang, "settings.back"), ""))
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	switch section {
+	case "language":
+		codes := make([]string, 0, len(catalogs))
+		for code := range catalogs {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(choice(tr(code, "language.name"), "language:"+code, code == lang)))
+		}
+		return tr(lang, "settings.choose_language"), tgbotapi.NewInlineKeyboardMarkup(append(rows, back)...)
+
+	case "quiet":
+		for _, preset := range quietPresets {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(choice(preset, "quiet:"+preset, settings[quietHoursSetting] == preset)))
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(choice(off, "quiet:", settings[quietHoursSetting] == "")))
+		return tr(lang, "settings.choose_quiet"), tgbotapi.NewInlineKeyboardMarkup(append(rows, back)...)
+
+	case "digest":
+		for _, preset := range digestPresets {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(choice(preset, "digest:"+preset, settings[digestSetting] == preset)))
+		}
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(choice(off, "digest:", settings[digestSetting] == "")))
+		return tr(lang, "settings.choose_digest"), tgbotapi.NewInlineKeyboardMarkup(append(rows, back)...)
+
+	case "verbosity":
+		current := settings[verbositySetting]
+		if current == "" {
+			current = verbosityAll
+		}
+		for _, v := range verbosities {
+			rows = append(rows, tgbotapi.NewInlineKeyboardRow(choice(verbosityName(lang, v), "verbosity:"+v, v == current)))
+		}
+		return tr(lang, "settings.choose_verbosity"), tgbotapi.NewInlineKeyboardMarkup(append(rows, back)...)
+
+	case "consoles":
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(choice(tr(lang, "settings.all_consoles"), "consoles:", len(chat.Consoles) == 0)))
+		for _, name := range knownConsoles(chat.Consoles) {
+			// Данные кнопки ограничены 64 байтами
+			if len(callbackSettings)+len(":consoles:")+len(name) <= 64 {
+				rows = append(rows, tgbotapi.NewInlineKeyboardRow(choice(consoleName(lang, name), "consoles:"+name, containsString(chat.Consoles, name))))
+			}
+		}
+		return tr(lang, "settings.choose_consoles"), tgbotapi.NewInlineKeyboardMarkup(append(rows, back)...)
+	}
+
+	consoles := tr(lang, "settings.all_consoles")
+	if len(chat.Consoles) > 0 {
+		consoles = consoleNames(lang, chat.Consoles)
+	}
+	rows = [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(button(tr(lang, "settings.language", tr(lang, "language.name")), "language")),
+		tgbotapi.NewInlineKeyboardRow(button(tr(lang, "settings.quiet", orOff(settings[quietHoursSetting])), "quiet")),
+		tgbotapi.NewInlineKeyboardRow(button(tr(lang, "settings.consoles", consoles), "consoles")),
+		tgbotapi.NewInlineKeyboardRow(button(tr(lang, "settings.verbosity", verbosityName(lang, settings[verbositySetting])), "verbosity")),
+		tgbotapi.NewInlineKeyboardRow(button(tr(lang, "settings.digest", orOff(settings[digestSetting])), "digest")),
+		tgbotapi.NewInlineKeyboardRow(button(tr(lang, "settings.close"), "close")),
+	}
+	return tr(lang, "settings.title"), tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// knownConsoles — консоли из последних ответов всех API и те, на которые чат
+// уже подписан, по алфавиту
+func knownConsoles(subscribed []string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, endpoint := range cfg().endpoints() {
+		consoles, _ := monitor.ParseStatuses(statusEngine().Last(endpoint.Name))
+		for _, console := range consoles {
+			add(console.Name)
+		}
+	}
+	for _, name := range subscribed {
+		add(name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// slackPostMessageURL — метод Web API для отправки сообщений
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// SlackConfig описывает канал Slack: входящий webhook или бот с токеном Web API
+type SlackConfig struct {
+	Name       string `yaml:"name" json:"name"`               // Имя для логов; по умолчанию канал или «slack»
+	WebhookURL string `yaml:"webhook_url" json:"webhook_url"` // Адрес входящего webhook
+	Token      string `yaml:"token" json:"token"`             // Токен бота для Web API (xoxb-...); если пуст, читается из TokenEnv
+	TokenEnv   string `yaml:"token_env" json:"token_env"`     // Переменная окружения с токеном
+	Channel    string `yaml:"channel" json:"channel"`         // ID или имя канала для Web API
+	Language   string `yaml:"language" json:"language"`       // Язык уведомлений; по умолчанию default_language
+}
+
+func (c SlackConfig) validate(i int) error {
+	if c.WebhookURL == "" && c.Token == "" && c.TokenEnv == "" {
+		return fmt.Errorf("notifiers.slack[%d]: either webhook_url or token must be set", i)
+	}
+	if c.WebhookURL == "" && c.Channel == "" {
+		return fmt.Errorf("notifiers.slack[%d]: channel must be set for Web API", i)
+	}
+	if c.Language != "" && catalogs[c.Language] == nil {
+		return fmt.Errorf("notifiers.slack[%d]: unknown language %q, available: %s", i, c.Language, languages())
+	}
+	return nil
+}
+
+// token возвращает токен из конфигурации или из указанной переменной окружения
+func (c SlackConfig) token() string {
+	if c.Token != "" {
+		return c.Token
+	}
+	if c.TokenEnv != "" {
+		return getenvSecret(c.TokenEnv)
+	}
+	return ""
+}
+
+// slackNotifier отправляет события в Slack
+type slackNotifier struct {
+	config SlackConfig
+	name   string
+}
+
+func newSlackNotifier(config SlackConfig) *slackNotifier {
+	name := config.Name
+	if name == "" {
+		name = "slack"
+		if config.Channel != "" {
+			name += " " + config.Channel
+		}
+	}
+	return &slackNotifier{config: config, name: name}
+}
+
+func (n *slackNotifier) Name() string {
+	return n.name
+}
+
+// slackEscaper экранирует символы, которые Slack считает разметкой ссылок и упоминаний
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// slackMessage — тело запроса к webhook и chat.postMessage
+type slackMessage struct {
+	Channel string `json:"channel,omitempty"`
+	Text    string `json:"text"`
+}
+
+func (n *slackNotifier) Notify(ctx context.Context, event StatusEvent) error {
+	text := slackEscaper.Replace(eventText(notifierLang(n.config.Language), event))
+	if event.critical() {
+		text = "<!channel> " + text
+	}
+
+	if n.config.WebhookURL != "" {
+		return n.post(ctx, n.config.WebhookURL, "", slackMessage{Text: text})
+	}
+	return n.post(ctx, slackPostMessageURL, n.config.token(), slackMessage{Channel: n.config.Channel, Text: text})
+}
+
+// post отправляет сообщение и проверяет ответ: webhook отвечает статусом,
+// Web API — полем ok в JSON
+func (n *slackNotifier) post(ctx context.Context, url, token string, message slackMessage) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := notifierClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	if token == "" {
+		return nil
+	}
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	if !result.OK {
+		return fmt.Errorf("slack API error: %s", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"status-bot/monitor"
+	"strings"
+	"time"
+)
+
+const (
+	snoozeSetting     = "snooze_until" // Ключ настройки чата: до какого момента уведомления выключены (/snooze)
+	muteSettingPrefix = "mute:"        // Префикс настроек отключённых консолей (/mute); значение — срок или "forever"
+	muteForever       = "forever"
+)
+
+// parseUntil разбирает срок: длительность ("2h", "1d"), "until tomorrow"
+// (завтра в 09:00) или "until HH:MM" (ближайшее такое время)
+func parseUntil(s string, now time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "until")))
+	switch s {
+	case "tomorrow", "завтра":
+		tomorrow := now.AddDate(0, 0, 1)
+		return time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 9, 0, 0, 0, now.Location()), nil
+	}
+	if clock, err := time.Parse("15:04", s); err == nil {
+		t := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	d, err := parseDuration(s)
+	if err != nil || d <= 0 {
+		return time.Time{}, fmt.Errorf("expected duration, tomorrow or HH:MM, got %q", s)
+	}
+	return now.Add(d), nil
+}
+
+// settingUntil разбирает срок из настройки; второй результат false, если срок
+// не задан или уже прошёл
+func settingUntil(value string, now time.Time) (time.Time, bool) {
+	if value == muteForever {
+		return time.Time{}, true
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	if err != nil || !until.After(now) {
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+// snoozed сообщает, выключил ли чат уведомления через /snooze
+func snoozed(settings map[string]string, now time.Time) bool {
+	_, ok := settingUntil(settings[snoozeSetting], now)
+	return ok
+}
+
+// consoleMuted сообщает, отключил ли чат уведомления о консоли через /mute
+func consoleMuted(settings map[string]string, console string, now time.Time) bool {
+	value := settings[muteSettingPrefix+console]
+	if value == "" {
+		return false
+	}
+	_, ok := settingUntil(value, now)
+	return ok
+}
+
+// filterMuted убирает изменения консолей, отключённых в чате
+func filterMuted(changes []monitor.StatusChange, settings map[string]string, now time.Time) []monitor.StatusChange {
+	filtered := changes[:0:0]
+	for _, change := range changes {
+		if !consoleMuted(settings, change.Name, now) {
+			filtered = append(filtered, change)
+		}
+	}
+	return filtered
+}
+
+// expireMutes снимает истёкшие /snooze и /mute и напоминает чатам, что
+// уведомления снова включены
+func expireMutes() {
+	chats, err := store.Chats()
+	if err != nil {
+		slog.Error("Error loading chats", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, chat := range chats {
+		lang := settingsLang(chat.Settings)
+		for key, value := range chat.Settings {
+			if key != snoozeSetting && !strings.HasPrefix(key, muteSettingPrefix) {
+				continue
+			}
+			if _, active := settingUntil(value, now); active {
+				continue
+			}
+			if err := store.SetChatSetting(chat.ID, key, ""); err != nil {
+				slog.Error("Error saving chat settings", "chat_id", chat.ID, "error", err)
+				continue
+			}
+			text := tr(lang, "snooze.expired")
+			if key != snoozeSetting {
+				text = tr(lang, "mute.expired", strings.TrimPrefix(key, muteSettingPrefix))
+			}
+			enqueueMessage(chat.ID, newMarkupMessage(chat.ID, escapeText(text)))
+		}
+	}
+}
+
+// formatUntil выводит срок из настройки для ответов на /snooze и /mute
+func formatUntil(lang, value string) string {
+	if value == muteForever {
+		return tr(lang, "mute.forever")
+	}
+	until, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return value
+	}
+	return tr(lang, "snooze.until", until.Local().Format("02.01 15:04"))
+}
+
+// handleSnooze обрабатывает /snooze 2h, /snooze until tomorrow, /snooze off
+// и /snooze без аргументов
+func handleSnooze(chatID int64, args string) {
+	args = strings.TrimSpace(args)
+	lang := chatLang(chatID)
+
+	switch args {
+	case "":
+		settings, err := store.ChatSettings(chatID)
+		if err != nil {
+			slog.Error("Error loading chat settings", "chat_id", chatID, "error", err)
+		}
+		if snoozed(settings, time.Now()) {
+			reply(chatID, "snooze.current", formatUntil(lang, settings[snoozeSetting]))
+			return
+		}
+		reply(chatID, "snooze.none")
+		return
+	case "off":
+		if err := store.SetChatSetting(chatID, snoozeSetting, ""); err != nil {
+			slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+			reply(chatID, "error.save")
+			return
+		}
+		reply(chatID, "snooze.off")
+		return
+	}
+
+	until, err := parseUntil(args, time.Now())
+	if err != nil {
+		reply(chatID, "snooze.usage")
+		return
+	}
+	value := until.Format(time.RFC3339)
+	if err := store.SetChatSetting(chatID, snoozeSetting, value); err != nil {
+		slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+	reply(chatID, "snooze.set", formatUntil(lang, value))
+}
+
+// handleMute обрабатывает /mute <консоль> [срок] и /mute без аргументов (список)
+func handleMute(chatID int64, args string) {
+	fields := consoleFields(args)
+	lang := chatLang(chatID)
+
+	if len(fields) == 0 {
+		settings, err := store.ChatSettings(chatID)
+		if err != nil {
+			slog.Error("Error loading chat settings", "chat_id", chatID, "error", err)
+		}
+		now := time.Now()
+		var lines []string
+		for key, value := range settings {
+			if console := strings.TrimPrefix(key, muteSettingPrefix); console != key && consoleMuted(settings, console, now) {
+				lines = append(lines, consoleName(lang, console)+" — "+formatUntil(lang, value))
+			}
+		}
+		if len(lines) == 0 {
+			reply(chatID, "mute.none")
+			return
+		}
+		sort.Strings(lines)
+		reply(chatID, "mute.list", strings.Join(lines, "\n"))
+		return
+	}
+
+	value := muteForever
+	if len(fields) > 1 {
+		until, err := parseUntil(strings.Join(fields[1:], " "), time.Now())
+		if err != nil {
+			reply(chatID, "mute.usage")
+			return
+		}
+		value = until.Format(time.RFC3339)
+	}
+	if err := store.SetChatSetting(chatID, muteSettingPrefix+fields[0], value); err != nil {
+		slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+	reply(chatID, "mute.set", consoleName(lang, fields[0]), formatUntil(lang, value))
+}
+
+// handleUnmute обрабатывает /unmute <консоль>
+func handleUnmute(chatID int64, args string) {
+	console := resolveConsole(args)
+	if console == "" {
+		reply(chatID, "mute.usage")
+		return
+	}
+	if err := store.SetChatSetting(chatID, muteSettingPrefix+console, ""); err != nil {
+		slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+	reply(chatID, "mute.off", consoleName(chatLang(chatID), console))
+}
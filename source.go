@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"status-bot/monitor"
+	"strings"
+	"sync"
+)
+
+// Типы источников статусов (type в описании endpoint)
+const (
+	sourceStatusAPI = "status_api" // API статусов консолей 4cloud.pro и совместимые
+)
+
+// sourceTypes — все типы источников для сообщений об ошибках
+var sourceTypes = strings.Join([]string{sourceStatusAPI, sourceExec, sourceProbe, sourceTLS, sourceHTTP, sourcePrometheus, sourceSSE, sourceWebSocket, sourceMQTT}, ", ")
+
+// newSource создаёт источник, описанный в endpoint
+func newSource(endpoint Endpoint) (monitor.Source, error) {
+	switch endpoint.Type {
+	case "", sourceStatusAPI:
+		if endpoint.URL == "" {
+			return nil, fmt.Errorf("url must not be empty")
+		}
+		extract, err := monitor.NewExtractor(endpoint.Extract)
+		if err != nil {
+			return nil, err
+		}
+		request, err := newEndpointRequest(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		return &statusAPISource{url: endpoint.URL, request: request, errorResponse: endpoint.ErrorResponse, extract: extract}, nil
+	case sourceExec:
+		extract, err := monitor.NewExtractor(endpoint.Extract)
+		if err != nil {
+			return nil, err
+		}
+		return newExecSource(endpoint.Exec, extract)
+	case sourceProbe:
+		return newProbeSource(endpoint.Probe)
+	case sourceTLS:
+		return newTLSSource(endpoint.TLS)
+	case sourceHTTP:
+		return newHTTPSource(endpoint.HTTP)
+	case sourcePrometheus:
+		return newPrometheusSource(endpoint.Prometheus)
+	case sourceSSE, sourceWebSocket:
+		if endpoint.URL == "" {
+			return nil, fmt.Errorf("url must not be empty")
+		}
+		request, err := newEndpointRequest(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if endpoint.Type == sourceSSE {
+			return &sseSource{url: endpoint.URL, request: request}, nil
+		}
+		return &websocketSource{url: endpoint.URL, request: request}, nil
+	case sourceMQTT:
+		return newMQTTSource(endpoint.Name, endpoint.MQTT)
+	}
+	return nil, fmt.Errorf("unknown type %q, expected one of: %s", endpoint.Type, sourceTypes)
+}
+
+// newSources создаёт источники API по их именам
+func newSources(endpoints []Endpoint) (map[string]monitor.Source, error) {
+	loaded := make(map[string]monitor.Source, len(endpoints))
+	for _, endpoint := range endpoints {
+		source, err := newSource(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", endpoint.Name, err)
+		}
+		loaded[endpoint.Name] = source
+	}
+	return loaded, nil
+}
+
+// defaultDownStatus возвращает статус, которым источники-проверки отмечают
+// недоступную консоль: первый из down_statuses
+func defaultDownStatus() string {
+	if len(cfg().DownStatuses) > 0 {
+		return cfg().DownStatuses[0]
+	}
+	return "Error"
+}
+
+// statusAPISource запрашивает API, которое отвечает массивом консолей
+// [{"Name": "...", "Status": "..."}] или JSON, разбираемым правилами extract
+type statusAPISource struct {
+	url           string
+	request       *endpointRequest   // Заголовки и авторизация из настроек endpoint
+	errorResponse string             // Нормализованный ответ, который считается ошибкой API
+	extract       *monitor.Extractor // Правила разбора ответа; nil — формат 4cloud.pro
+
+	mutex    sync.Mutex
+	cached   validators              // ETag и Last-Modified последнего разобранного ответа
+	consoles []monitor.ConsoleStatus // Консоли из этого ответа для 304 Not Modified
+}
+
+func (s *statusAPISource) Fetch(ctx context.Context) ([]monitor.ConsoleStatus, error) {
+	s.mutex.Lock()
+	cached, consoles := s.cached, s.consoles
+	s.mutex.Unlock()
+
+	header, err := s.request.headers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status, next, err := getAPIStatus(ctx, s.request.httpClient(), s.url, header, cached)
+	if errors.Is(err, errNotModified) {
+		// Ответ не изменился: разбирать нечего, engine не увидит изменений
+		return append([]monitor.ConsoleStatus(nil), consoles...), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status == s.errorResponse {
+		return nil, fmt.Errorf("API returned error response")
+	}
+	consoles, err = monitor.DecodeConsoles([]byte(status), s.extract)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	s.cached, s.consoles = next, consoles
+	s.mutex.Unlock()
+	return append([]monitor.ConsoleStatus(nil), consoles...), nil
+}
+
+// validators — заголовки для условного запроса к API
+type validators struct {
+	etag         string
+	lastModified string
+}
+
+// errNotModified — API ответило 304: данные те же, что в прошлый раз
+var errNotModified = errors.New("not modified")
+
+// getAPIStatus запрашивает API и возвращает нормализованный JSON
+// (ключи объектов отсортированы), чтобы ответы можно было сравнивать как строки.
+// header — дополнительные заголовки запроса. Если известны ETag или Last-Modified прошлого ответа, запрос отправляется
+// с If-None-Match и If-Modified-Since, и на 304 возвращается errNotModified.
+func getAPIStatus(ctx context.Context, client *http.Client, url string, header http.Header, cached validators) (string, validators, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", validators{}, err
+	}
+	setHeader(req, header)
+	if cached.etag != "" {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+	if cached.lastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.lastModified)
+	}
+	// Сжатие запрашивается явно, поэтому ответ распаковывает readBody
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", validators{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return "", cached, errNotModified
+	}
+	next := validators{etag: resp.Header.Get("ETag"), lastModified: resp.Header.Get("Last-Modified")}
+
+	body, err := readBody(resp)
+	if err != nil {
+		return "", validators{}, err
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", validators{}, err
+	}
+
+	normalized, err := json.Marshal(data)
+	if err != nil {
+		return "", validators{}, err
+	}
+
+	return string(normalized), next, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"status-bot/monitor"
+	"strings"
+	"time"
+)
+
+// sourceExec — статусы выводит внешняя команда
+const sourceExec = "exec"
+
+// ExecSourceConfig описывает команду, которая выводит статусы консолей в stdout
+// в том же формате, что и API: [{"Name": "...", "Status": "..."}] или JSON,
+// разбираемый правилами extract
+type ExecSourceConfig struct {
+	Command []string `yaml:"command" json:"command"` // Программа и аргументы; оболочка не используется
+	Timeout Duration `yaml:"timeout" json:"timeout"` // Сколько ждать завершения; по умолчанию 10s
+	Dir     string   `yaml:"dir" json:"dir"`         // Рабочий каталог команды
+}
+
+// execTimeout — время на выполнение команды по умолчанию
+const execTimeout = 10 * time.Second
+
+// execOutputLimit — сколько байт stderr попадает в текст ошибки
+const execOutputLimit = 512
+
+// execSource запускает команду при каждой проверке
+type execSource struct {
+	config  ExecSourceConfig
+	extract *monitor.Extractor // Правила разбора вывода; nil — формат 4cloud.pro
+}
+
+func newExecSource(config ExecSourceConfig, extract *monitor.Extractor) (*execSource, error) {
+	if len(config.Command) == 0 || config.Command[0] == "" {
+		return nil, fmt.Errorf("exec.command must not be empty")
+	}
+	if config.Timeout.Duration < 0 {
+		return nil, fmt.Errorf("exec.timeout must not be negative")
+	}
+	if config.Timeout.Duration == 0 {
+		config.Timeout = Duration{execTimeout}
+	}
+	return &execSource{config: config, extract: extract}, nil
+}
+
+func (s *execSource) Fetch(ctx context.Context) ([]monitor.ConsoleStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout.Duration)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, s.config.Command[0], s.config.Command[1:]...)
+	cmd.Dir = s.config.Dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("command timed out after %s", s.config.Timeout.Duration)
+		}
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			if len(output) > execOutputLimit {
+				output = output[:execOutputLimit] + "…"
+			}
+			return nil, fmt.Errorf("%w: %s", err, output)
+		}
+		return nil, err
+	}
+
+	consoles, err := monitor.DecodeConsoles(stdout.Bytes(), s.extract)
+	if err != nil {
+		return nil, fmt.Errorf("parse command output: %w", err)
+	}
+	return consoles, nil
+}
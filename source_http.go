@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"status-bot/monitor"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sourceHTTP — проверки произвольных HTTP-адресов
+const sourceHTTP = "http"
+
+// httpCheckBodyLimit — сколько байт ответа читается для поиска подстроки
+const httpCheckBodyLimit = 1 << 20
+
+// HTTPSourceConfig описывает HTTP-проверки, выполняемые при каждом опросе.
+// Каждая проверка — отдельная консоль: статус OK, если все условия выполнены,
+// и первый из down_statuses, если нет.
+type HTTPSourceConfig struct {
+	Checks []HTTPCheck `yaml:"checks" json:"checks"` // Проверяемые адреса
+}
+
+// HTTPCheck — одна HTTP-проверка и её условия
+type HTTPCheck struct {
+	Name       string   `yaml:"name" json:"name"`               // Имя консоли; по умолчанию url
+	URL        string   `yaml:"url" json:"url"`                 // Проверяемый адрес
+	Method     string   `yaml:"method" json:"method"`           // Метод запроса; по умолчанию GET
+	Status     int      `yaml:"status" json:"status"`           // Ожидаемый код ответа; по умолчанию любой 2xx
+	Contains   string   `yaml:"contains" json:"contains"`       // Подстрока, которая должна быть в теле ответа
+	MaxLatency Duration `yaml:"max_latency" json:"max_latency"` // Максимальное время ответа; 0 — не ограничено
+}
+
+func (c HTTPCheck) name() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.URL
+}
+
+// httpSource выполняет HTTP-проверки параллельно
+type httpSource struct {
+	config HTTPSourceConfig
+}
+
+func newHTTPSource(config HTTPSourceConfig) (*httpSource, error) {
+	if len(config.Checks) == 0 {
+		return nil, fmt.Errorf("http.checks must not be empty")
+	}
+	names := make(map[string]bool, len(config.Checks))
+	for i, check := range config.Checks {
+		if check.URL == "" {
+			return nil, fmt.Errorf("http.checks[%d]: url must be set", i)
+		}
+		if check.Status != 0 && (check.Status < 100 || check.Status > 599) {
+			return nil, fmt.Errorf("http.checks[%d]: invalid status %d", i, check.Status)
+		}
+		if check.MaxLatency.Duration < 0 {
+			return nil, fmt.Errorf("http.checks[%d]: max_latency must not be negative", i)
+		}
+		if names[check.name()] {
+			return nil, fmt.Errorf("http.checks[%d]: duplicate name %q", i, check.name())
+		}
+		names[check.name()] = true
+	}
+	return &httpSource{config: config}, nil
+}
+
+func (s *httpSource) Fetch(ctx context.Context) ([]monitor.ConsoleStatus, error) {
+	consoles := make([]monitor.ConsoleStatus, len(s.config.Checks))
+	var wg sync.WaitGroup
+	for i, check := range s.config.Checks {
+		wg.Add(1)
+		go func(i int, check HTTPCheck) {
+			defer wg.Done()
+			status := "OK"
+			if err := runHTTPCheck(ctx, check); err != nil {
+				status = defaultDownStatus()
+			}
+			consoles[i] = monitor.ConsoleStatus{Name: check.name(), Status: status}
+		}(i, check)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return consoles, nil
+}
+
+// runHTTPCheck выполняет запрос и проверяет код ответа, подстроку и время ответа
+func runHTTPCheck(ctx context.Context, check HTTPCheck) error {
+	method := check.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), check.URL, nil)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	resp, err := apiClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if check.Status != 0 && resp.StatusCode != check.Status {
+		return fmt.Errorf("status %d, expected %d", resp.StatusCode, check.Status)
+	}
+	if check.Status == 0 && resp.StatusCode/100 != 2 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	if check.Contains != "" {
+		body, err := ioutil.ReadAll(io.LimitReader(resp.Body, httpCheckBodyLimit))
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(body), check.Contains) {
+			return fmt.Errorf("response does not contain %q", check.Contains)
+		}
+	}
+	if latency := time.Since(start); check.MaxLatency.Duration > 0 && latency > check.MaxLatency.Duration {
+		return fmt.Errorf("latency %s exceeds %s", latency, check.MaxLatency.Duration)
+	}
+	return nil
+}
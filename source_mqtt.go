@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"sort"
+	"status-bot/monitor"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sourceMQTT — сообщения из топиков MQTT
+const sourceMQTT = "mqtt"
+
+// MQTTSourceConfig описывает брокер и топики. Каждый топик — отдельная консоль:
+// статус берётся из сообщения, а если сообщений нет дольше stale_after,
+// консоль получает stale_status.
+type MQTTSourceConfig struct {
+	Broker      string      `yaml:"broker" json:"broker"`             // Адрес брокера: tcp://host:1883, ssl://host:8883 или ws://host/mqtt
+	ClientID    string      `yaml:"client_id" json:"client_id"`       // ID клиента; по умолчанию status-bot-<имя API>
+	Username    string      `yaml:"username" json:"username"`         // Логин
+	Password    string      `yaml:"password" json:"password"`         // Пароль; если пуст, читается из PasswordEnv
+	PasswordEnv string      `yaml:"password_env" json:"password_env"` // Переменная окружения с паролем
+	QoS         byte        `yaml:"qos" json:"qos"`                   // Уровень QoS подписки: 0, 1 или 2
+	Topics      []MQTTTopic `yaml:"topics" json:"topics"`             // Топики
+	StaleStatus string      `yaml:"stale_status" json:"stale_status"` // Статус замолчавшего топика; по умолчанию первый из down_statuses
+}
+
+// MQTTTopic — подписка на топик
+type MQTTTopic struct {
+	Topic      string   `yaml:"topic" json:"topic"`             // Топик; + и # подписывают на несколько, и каждый конкретный топик становится консолью
+	Name       string   `yaml:"name" json:"name"`               // Имя консоли для топика без подстановок; по умолчанию сам топик
+	StaleAfter Duration `yaml:"stale_after" json:"stale_after"` // Сколько ждать следующего сообщения; 0 — не следить
+}
+
+// wildcard сообщает, подписывает ли топик на несколько топиков сразу
+func (t MQTTTopic) wildcard() bool {
+	return strings.ContainsAny(t.Topic, "+#")
+}
+
+// mqttConsole — последнее сообщение одного конкретного топика
+type mqttConsole struct {
+	topic    MQTTTopic // Подписка, которой пришло сообщение
+	status   string
+	lastSeen time.Time
+}
+
+// mqttSource подписывается на топики и собирает статусы из сообщений
+type mqttSource struct {
+	config   MQTTSourceConfig
+	clientID string
+
+	mutex    sync.Mutex
+	consoles map[string]*mqttConsole // По имени консоли
+	started  time.Time               // Начало текущего подключения; отсчёт для ещё не приходивших топиков
+}
+
+func newMQTTSource(name string, config MQTTSourceConfig) (*mqttSource, error) {
+	if config.Broker == "" {
+		return nil, fmt.Errorf("mqtt.broker must be set")
+	}
+	if len(config.Topics) == 0 {
+		return nil, fmt.Errorf("mqtt.topics must not be empty")
+	}
+	if config.QoS > 2 {
+		return nil, fmt.Errorf("mqtt.qos must be 0, 1 or 2")
+	}
+	for i, topic := range config.Topics {
+		if topic.Topic == "" {
+			return nil, fmt.Errorf("mqtt.topics[%d]: topic must be set", i)
+		}
+		if topic.Name != "" && topic.wildcard() {
+			return nil, fmt.Errorf("mqtt.topics[%d]: name cannot be set for a wildcard topic", i)
+		}
+		if topic.StaleAfter.Duration < 0 {
+			return nil, fmt.Errorf("mqtt.topics[%d]: stale_after must not be negative", i)
+		}
+	}
+
+	clientID := config.ClientID
+	if clientID == "" {
+		clientID = "status-bot-" + name
+	}
+	return &mqttSource{config: config, clientID: clientID, consoles: make(map[string]*mqttConsole)}, nil
+}
+
+func (s *mqttSource) password() string {
+	if s.config.Password != "" {
+		return s.config.Password
+	}
+	if s.config.PasswordEnv != "" {
+		return getenvSecret(s.config.PasswordEnv)
+	}
+	return ""
+}
+
+func (s *mqttSource) Stream(ctx context.Context, update func([]monitor.ConsoleStatus)) error {
+	messages := make(chan mqtt.Message, 100)
+	lost := make(chan error, 1)
+
+	options := mqtt.NewClientOptions().
+		AddBroker(s.config.Broker).
+		SetClientID(s.clientID).
+		SetUsername(s.config.Username).
+		SetPassword(s.password()).
+		SetConnectTimeout(cfg().HTTPClient.ConnectTimeout.Duration).
+		SetAutoReconnect(false).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			lost <- err
+		})
+	client := mqtt.NewClient(options)
+	if err := waitToken(ctx, client.Connect()); err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer client.Disconnect(250)
+
+	s.mutex.Lock()
+	s.started = time.Now()
+	s.mutex.Unlock()
+
+	for _, topic := range s.config.Topics {
+		topic := topic
+		handler := func(_ mqtt.Client, message mqtt.Message) {
+			s.receive(topic, message)
+			// Рассылка идёт из цикла Stream, чтобы события обрабатывались по одному
+			select {
+			case messages <- message:
+			default:
+			}
+		}
+		if err := waitToken(ctx, client.Subscribe(topic.Topic, s.config.QoS, handler)); err != nil {
+			return fmt.Errorf("subscribe %s: %w", topic.Topic, err)
+		}
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-lost:
+			return fmt.Errorf("connection lost: %w", err)
+		case <-messages:
+			update(s.snapshot(time.Now()))
+		case <-ticker.C:
+			// Замолчавшие топики меняют статус без новых сообщений;
+			// Engine сам отбрасывает неизменившиеся списки
+			update(s.snapshot(time.Now()))
+		}
+	}
+}
+
+// receive запоминает статус из сообщения
+func (s *mqttSource) receive(topic MQTTTopic, message mqtt.Message) {
+	name := message.Topic()
+	if topic.Name != "" {
+		name = topic.Name
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.consoles[name] = &mqttConsole{topic: topic, status: mqttStatus(message.Payload()), lastSeen: time.Now()}
+}
+
+// mqttStatus переводит сообщение в статус: поле Status из JSON-объекта
+// или сам текст сообщения
+func mqttStatus(payload []byte) string {
+	var object struct {
+		Status *string `json:"Status"`
+	}
+	if err := json.Unmarshal(payload, &object); err == nil && object.Status != nil {
+		return *object.Status
+	}
+	return strings.TrimSpace(string(payload))
+}
+
+// snapshot возвращает статусы консолей с учётом замолчавших топиков
+func (s *mqttSource) snapshot(now time.Time) []monitor.ConsoleStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stale := s.config.StaleStatus
+	if stale == "" {
+		stale = defaultDownStatus()
+	}
+
+	consoles := make([]monitor.ConsoleStatus, 0, len(s.consoles)+len(s.config.Topics))
+	for name, console := range s.consoles {
+		status := console.status
+		if after := console.topic.StaleAfter.Duration; after > 0 && now.Sub(console.lastSeen) > after {
+			status = stale
+		}
+		consoles = append(consoles, monitor.ConsoleStatus{Name: name, Status: status})
+	}
+	// Топик без подстановок, не приславший ни одного сообщения, тоже замолчал
+	for _, topic := range s.config.Topics {
+		name := topic.Topic
+		if topic.Name != "" {
+			name = topic.Name
+		}
+		if topic.wildcard() || s.consoles[name] != nil || topic.StaleAfter.Duration == 0 {
+			continue
+		}
+		if now.Sub(s.started) > topic.StaleAfter.Duration {
+			consoles = append(consoles, monitor.ConsoleStatus{Name: name, Status: stale})
+		}
+	}
+	sort.Slice(consoles, func(i, j int) bool { return consoles[i].Name < consoles[j].Name })
+	return consoles
+}
+
+func (s *mqttSource) Fetch(ctx context.Context) ([]monitor.ConsoleStatus, error) {
+	s.mutex.Lock()
+	started := !s.started.IsZero()
+	s.mutex.Unlock()
+	if !started {
+		return nil, fmt.Errorf("not connected to %s yet", s.config.Broker)
+	}
+	return s.snapshot(time.Now()), nil
+}
+
+// waitToken ждёт завершения операции MQTT или отмены контекста
+func waitToken(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+	"net"
+	"os"
+	"status-bot/monitor"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sourceProbe — доступность хостов по TCP или ICMP
+const sourceProbe = "probe"
+
+// probeTimeout — время на одну проверку по умолчанию
+const probeTimeout = 5 * time.Second
+
+// ProbeSourceConfig описывает хосты, доступность которых проверяется при каждом опросе.
+// Каждая цель — отдельная консоль: доступная получает статус up_status,
+// недоступная — down_status.
+type ProbeSourceConfig struct {
+	Targets    []ProbeTarget `yaml:"targets" json:"targets"`         // Проверяемые хосты
+	Timeout    Duration      `yaml:"timeout" json:"timeout"`         // Сколько ждать ответа; по умолчанию 5s
+	UpStatus   string        `yaml:"up_status" json:"up_status"`     // Статус доступной цели; по умолчанию OK
+	DownStatus string        `yaml:"down_status" json:"down_status"` // Статус недоступной цели; по умолчанию первый из down_statuses
+	Privileged bool          `yaml:"privileged" json:"privileged"`   // ICMP через raw-сокет (нужны права root или CAP_NET_RAW)
+}
+
+// ProbeTarget — один проверяемый хост
+type ProbeTarget struct {
+	Name string `yaml:"name" json:"name"` // Имя консоли; по умолчанию host или host:port
+	Host string `yaml:"host" json:"host"` // Имя или адрес хоста
+	Port int    `yaml:"port" json:"port"` // Порт для TCP connect; 0 — ping по ICMP
+}
+
+// name возвращает имя консоли для цели
+func (t ProbeTarget) name() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	if t.Port != 0 {
+		return net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+	}
+	return t.Host
+}
+
+// probeSource проверяет доступность целей параллельно
+type probeSource struct {
+	config ProbeSourceConfig
+}
+
+func newProbeSource(config ProbeSourceConfig) (*probeSource, error) {
+	if len(config.Targets) == 0 {
+		return nil, fmt.Errorf("probe.targets must not be empty")
+	}
+	names := make(map[string]bool, len(config.Targets))
+	for i, target := range config.Targets {
+		if target.Host == "" {
+			return nil, fmt.Errorf("probe.targets[%d]: host must be set", i)
+		}
+		if target.Port < 0 || target.Port > 65535 {
+			return nil, fmt.Errorf("probe.targets[%d]: invalid port %d", i, target.Port)
+		}
+		if names[target.name()] {
+			return nil, fmt.Errorf("probe.targets[%d]: duplicate name %q", i, target.name())
+		}
+		names[target.name()] = true
+	}
+	if config.Timeout.Duration < 0 {
+		return nil, fmt.Errorf("probe.timeout must not be negative")
+	}
+	if config.Timeout.Duration == 0 {
+		config.Timeout = Duration{probeTimeout}
+	}
+	if config.UpStatus == "" {
+		config.UpStatus = "OK"
+	}
+	return &probeSource{config: config}, nil
+}
+
+// downStatus возвращает статус недоступной цели
+func (s *probeSource) downStatus() string {
+	if s.config.DownStatus != "" {
+		return s.config.DownStatus
+	}
+	return defaultDownStatus()
+}
+
+func (s *probeSource) Fetch(ctx context.Context) ([]monitor.ConsoleStatus, error) {
+	consoles := make([]monitor.ConsoleStatus, len(s.config.Targets))
+	var wg sync.WaitGroup
+	for i, target := range s.config.Targets {
+		wg.Add(1)
+		go func(i int, target ProbeTarget) {
+			defer wg.Done()
+			status := s.config.UpStatus
+			if err := s.probe(ctx, target); err != nil {
+				status = s.downStatus()
+			}
+			consoles[i] = monitor.ConsoleStatus{Name: target.name(), Status: status}
+		}(i, target)
+	}
+	wg.Wait()
+
+	// Отмена проверки не должна выглядеть как недоступность всех целей
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return consoles, nil
+}
+
+// probe проверяет одну цель: TCP connect, если задан порт, иначе ping
+func (s *probeSource) probe(ctx context.Context, target ProbeTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout.Duration)
+	defer cancel()
+
+	if target.Port != 0 {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(target.Host, strconv.Itoa(target.Port)))
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+	return ping(ctx, target.Host, s.config.Privileged)
+}
+
+// pingSeq — порядковый номер эхо-запроса, чтобы отличать ответы параллельных проверок
+var (
+	pingSeq   int
+	pingMutex = &sync.Mutex{}
+)
+
+func nextPingSeq() int {
+	pingMutex.Lock()
+	defer pingMutex.Unlock()
+	pingSeq = (pingSeq + 1) & 0xffff
+	return pingSeq
+}
+
+// ping отправляет один эхо-запрос ICMP и ждёт ответа до отмены контекста.
+// Без privileged используются ICMP-сокеты без прав root (на Linux их
+// разрешает sysctl net.ipv4.ping_group_range).
+func ping(ctx context.Context, host string, privileged bool) error {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("no addresses for %s", host)
+	}
+	ip := addrs[0].IP
+
+	network, listen, protocol := "udp4", "0.0.0.0", 1
+	var request icmp.Type = ipv4.ICMPTypeEcho
+	var reply icmp.Type = ipv4.ICMPTypeEchoReply
+	if ip.To4() == nil {
+		network, listen, protocol = "udp6", "::", 58
+		request, reply = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+	if privileged {
+		network = map[string]string{"udp4": "ip4:icmp", "udp6": "ip6:ipv6-icmp"}[network]
+	}
+
+	conn, err := icmp.ListenPacket(network, listen)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var peer net.Addr = &net.UDPAddr{IP: ip}
+	if privileged {
+		peer = &net.IPAddr{IP: ip}
+	}
+	seq := nextPingSeq()
+	message := icmp.Message{
+		Type: request,
+		Body: &icmp.Echo{ID: os.Getpid() & 0xffff, Seq: seq, Data: []byte("status-bot")},
+	}
+	data, err := message.Marshal(nil)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.WriteTo(data, peer); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+		parsed, err := icmp.ParseMessage(protocol, buf[:n])
+		if err != nil || parsed.Type != reply {
+			continue
+		}
+		// ID ответа на сокете без прав подменяет ядро, поэтому сверяется только номер
+		if echo, ok := parsed.Body.(*icmp.Echo); ok && echo.Seq == seq && sameHost(from, ip) {
+			return nil
+		}
+	}
+}
+
+// sameHost сообщает, пришёл ли пакет с адреса ip
+func sameHost(addr net.Addr, ip net.IP) bool {
+	switch a := addr.(type) {
+	case *net.UDPAddr:
+		return a.IP.Equal(ip)
+	case *net.IPAddr:
+		return a.IP.Equal(ip)
+	}
+	return false
+}
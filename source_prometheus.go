@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"status-bot/monitor"
+	"strconv"
+	"strings"
+)
+
+// sourcePrometheus — результат запроса PromQL
+const sourcePrometheus = "prometheus"
+
+// PrometheusSourceConfig описывает запрос к Prometheus. Каждый ряд результата —
+// отдельная консоль, а его значение переводится в статус по порогам.
+type PrometheusSourceConfig struct {
+	URL        string                `yaml:"url" json:"url"`               // Адрес Prometheus, например http://prometheus:9090
+	Query      string                `yaml:"query" json:"query"`           // Запрос PromQL
+	NameLabel  string                `yaml:"name_label" json:"name_label"` // Метка с именем консоли; по умолчанию все метки ряда
+	Thresholds []PrometheusThreshold `yaml:"thresholds" json:"thresholds"` // Пороги по порядку; срабатывает первый подходящий
+	OKStatus   string                `yaml:"ok_status" json:"ok_status"`   // Статус, если ни один порог не сработал; по умолчанию OK
+}
+
+// PrometheusThreshold задаёт статус для значений выше above и/или ниже below
+type PrometheusThreshold struct {
+	Status string   `yaml:"status" json:"status"` // Статус консоли; пусто — первый из down_statuses
+	Above  *float64 `yaml:"above" json:"above"`   // Срабатывает, если значение больше
+	Below  *float64 `yaml:"below" json:"below"`   // Срабатывает, если значение меньше
+}
+
+// matches сообщает, попадает ли значение под порог
+func (t PrometheusThreshold) matches(value float64) bool {
+	if t.Above != nil && value <= *t.Above {
+		return false
+	}
+	if t.Below != nil && value >= *t.Below {
+		return false
+	}
+	return true
+}
+
+// prometheusSource выполняет мгновенный запрос /api/v1/query
+type prometheusSource struct {
+	config PrometheusSourceConfig
+}
+
+func newPrometheusSource(config PrometheusSourceConfig) (*prometheusSource, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("prometheus.url must be set")
+	}
+	if config.Query == "" {
+		return nil, fmt.Errorf("prometheus.query must be set")
+	}
+	if len(config.Thresholds) == 0 {
+		return nil, fmt.Errorf("prometheus.thresholds must not be empty")
+	}
+	for i, threshold := range config.Thresholds {
+		if threshold.Above == nil && threshold.Below == nil {
+			return nil, fmt.Errorf("prometheus.thresholds[%d]: either above or below must be set", i)
+		}
+	}
+	if config.OKStatus == "" {
+		config.OKStatus = "OK"
+	}
+	return &prometheusSource{config: config}, nil
+}
+
+// prometheusResponse — ответ /api/v1/query
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// prometheusSample — ряд вектора: метки и значение [время, "число"]
+type prometheusSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  [2]interface{}    `json:"value"`
+}
+
+func (s *prometheusSource) Fetch(ctx context.Context) ([]monitor.ConsoleStatus, error) {
+	query := url.Values{"query": {s.config.Query}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(s.config.URL, "/")+"/api/v1/query?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := apiClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var result prometheusResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("parse Prometheus response (%s): %w", resp.Status, err)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("Prometheus query failed: %s", result.Error)
+	}
+
+	var samples []prometheusSample
+	switch result.Data.ResultType {
+	case "vector":
+		if err := json.Unmarshal(result.Data.Result, &samples); err != nil {
+			return nil, err
+		}
+	case "scalar":
+		var sample prometheusSample
+		if err := json.Unmarshal(result.Data.Result, &sample.Value); err != nil {
+			return nil, err
+		}
+		samples = append(samples, sample)
+	default:
+		return nil, fmt.Errorf("unsupported Prometheus result type %q, expected vector or scalar", result.Data.ResultType)
+	}
+
+	consoles := make([]monitor.ConsoleStatus, 0, len(samples))
+	for _, sample := range samples {
+		value, err := sampleValue(sample)
+		if err != nil {
+			return nil, err
+		}
+		consoles = append(consoles, monitor.ConsoleStatus{Name: s.seriesName(sample.Metric), Status: s.status(value)})
+	}
+	return consoles, nil
+}
+
+// status переводит значение в статус по первому сработавшему порогу
+func (s *prometheusSource) status(value float64) string {
+	for _, threshold := range s.config.Thresholds {
+		if threshold.matches(value) {
+			if threshold.Status == "" {
+				return defaultDownStatus()
+			}
+			return threshold.Status
+		}
+	}
+	return s.config.OKStatus
+}
+
+// seriesName возвращает имя консоли для ряда: значение name_label или
+// все метки в виде {job="api",instance="..."}
+func (s *prometheusSource) seriesName(metric map[string]string) string {
+	if s.config.NameLabel != "" {
+		if name := metric[s.config.NameLabel]; name != "" {
+			return name
+		}
+	}
+	if len(metric) == 0 {
+		return s.config.Query
+	}
+
+	keys := make([]string, 0, len(metric))
+	for key := range metric {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", key, metric[key]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// sampleValue разбирает значение ряда; Prometheus передаёт его строкой
+func sampleValue(sample prometheusSample) (float64, error) {
+	text, ok := sample.Value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected Prometheus sample value %v", sample.Value[1])
+	}
+	return strconv.ParseFloat(text, 64)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"log/slog"
+	"net/http"
+	"status-bot/monitor"
+	"strings"
+	"sync"
+)
+
+// Типы потоковых источников
+const (
+	sourceSSE       = "sse"       // Server-Sent Events
+	sourceWebSocket = "websocket" // WebSocket
+)
+
+// streamState — последние статусы, собранные из событий потока. Событие
+// с массивом заменяет список целиком, событие с одной консолью обновляет её.
+type streamState struct {
+	mutex    sync.Mutex
+	consoles []monitor.ConsoleStatus
+	received bool
+}
+
+// apply разбирает событие и возвращает обновлённый список консолей
+func (s *streamState) apply(data []byte) ([]monitor.ConsoleStatus, error) {
+	data = bytes.TrimSpace(data)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if bytes.HasPrefix(data, []byte("[")) {
+		var consoles []monitor.ConsoleStatus
+		if err := json.Unmarshal(data, &consoles); err != nil {
+			return nil, err
+		}
+		s.consoles = consoles
+	} else {
+		var console monitor.ConsoleStatus
+		if err := json.Unmarshal(data, &console); err != nil {
+			return nil, err
+		}
+		updated := false
+		for i := range s.consoles {
+			if s.consoles[i].Name == console.Name {
+				s.consoles[i] = console
+				updated = true
+			}
+		}
+		if !updated {
+			s.consoles = append(s.consoles, console)
+		}
+	}
+	s.received = true
+	return append([]monitor.ConsoleStatus(nil), s.consoles...), nil
+}
+
+// Fetch возвращает последние полученные статусы, например для /status
+func (s *streamState) Fetch(ctx context.Context) ([]monitor.ConsoleStatus, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.received {
+		return nil, fmt.Errorf("no events received yet")
+	}
+	return append([]monitor.ConsoleStatus(nil), s.consoles...), nil
+}
+
+// sseSource читает поток text/event-stream; данные каждого события — JSON
+// со списком консолей или одной консолью
+type sseSource struct {
+	streamState
+	url     string
+	request *endpointRequest // Заголовки и авторизация из настроек endpoint
+}
+
+func (s *sseSource) Stream(ctx context.Context, update func([]monitor.ConsoleStatus)) error {
+	header, err := s.request.headers(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+	setHeader(req, header)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+
+	resp, err := s.request.streamClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("stream returned %s", resp.Status)
+	}
+
+	var data bytes.Buffer
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// Пустая строка завершает событие
+			if data.Len() == 0 {
+				continue
+			}
+			consoles, err := s.apply(data.Bytes())
+			data.Reset()
+			if err != nil {
+				slog.Error("Error parsing event", "url", s.url, "error", err)
+				continue
+			}
+			update(consoles)
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// Остальные поля (event, id, retry) и комментарии не используются
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("stream closed")
+}
+
+// websocketSource читает сообщения WebSocket; каждое сообщение — JSON
+// со списком консолей или одной консолью
+type websocketSource struct {
+	streamState
+	url     string
+	request *endpointRequest // Заголовки и авторизация из настроек endpoint
+}
+
+func (s *websocketSource) Stream(ctx context.Context, update func([]monitor.ConsoleStatus)) error {
+	header, err := s.request.headers(ctx)
+	if err != nil {
+		return err
+	}
+	proxy, _ := proxyFunc(cfg().HTTPClient.Proxy)
+	dialer := websocket.Dialer{
+		Proxy:            proxy,
+		HandshakeTimeout: cfg().HTTPClient.ConnectTimeout.Duration,
+		TLSClientConfig:  s.request.tls,
+	}
+	conn, _, err := dialer.DialContext(ctx, s.url, header)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// ReadMessage не принимает контекст, поэтому соединение закрывается при отмене
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		consoles, err := s.apply(message)
+		if err != nil {
+			slog.Error("Error parsing message", "url", s.url, "error", err)
+			continue
+		}
+		update(consoles)
+	}
+}
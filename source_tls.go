@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sort"
+	"status-bot/monitor"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sourceTLS — срок действия TLS-сертификатов
+const sourceTLS = "tls"
+
+// defaultTLSThresholds — пороги предупреждений об истечении сертификата по умолчанию
+var defaultTLSThresholds = []Duration{{30 * 24 * time.Hour}, {7 * 24 * time.Hour}, {24 * time.Hour}}
+
+// TLSSourceConfig описывает хосты, сертификаты которых проверяются при каждом опросе.
+// Каждый хост — отдельная консоль: статус OK, пока до истечения больше всех
+// порогов, затем «Expires within 30d» и т. д. по ближайшему пройденному порогу.
+// Истёкший сертификат и ошибка подключения дают первый из down_statuses.
+type TLSSourceConfig struct {
+	Hosts      []TLSHost  `yaml:"hosts" json:"hosts"`           // Проверяемые хосты
+	Thresholds []Duration `yaml:"thresholds" json:"thresholds"` // Пороги предупреждений; по умолчанию 30d, 7d и 1d
+	Timeout    Duration   `yaml:"timeout" json:"timeout"`       // Время на подключение; по умолчанию 5s
+}
+
+// TLSHost — один проверяемый хост
+type TLSHost struct {
+	Name       string `yaml:"name" json:"name"`               // Имя консоли; по умолчанию host
+	Host       string `yaml:"host" json:"host"`               // Имя хоста, оно же SNI
+	Port       int    `yaml:"port" json:"port"`               // Порт; по умолчанию 443
+	ServerName string `yaml:"server_name" json:"server_name"` // SNI, если отличается от host
+}
+
+func (h TLSHost) name() string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return h.Host
+}
+
+// tlsSource проверяет сертификаты хостов параллельно
+type tlsSource struct {
+	config TLSSourceConfig
+}
+
+func newTLSSource(config TLSSourceConfig) (*tlsSource, error) {
+	if len(config.Hosts) == 0 {
+		return nil, fmt.Errorf("tls.hosts must not be empty")
+	}
+	names := make(map[string]bool, len(config.Hosts))
+	for i, host := range config.Hosts {
+		if host.Host == "" {
+			return nil, fmt.Errorf("tls.hosts[%d]: host must be set", i)
+		}
+		if host.Port < 0 || host.Port > 65535 {
+			return nil, fmt.Errorf("tls.hosts[%d]: invalid port %d", i, host.Port)
+		}
+		if names[host.name()] {
+			return nil, fmt.Errorf("tls.hosts[%d]: duplicate name %q", i, host.name())
+		}
+		names[host.name()] = true
+	}
+	for _, threshold := range config.Thresholds {
+		if threshold.Duration <= 0 {
+			return nil, fmt.Errorf("tls.thresholds must be positive")
+		}
+	}
+	if len(config.Thresholds) == 0 {
+		config.Thresholds = defaultTLSThresholds
+	}
+	// От большего порога к меньшему, чтобы находить ближайший пройденный
+	thresholds := append([]Duration(nil), config.Thresholds...)
+	sort.Slice(thresholds, func(i, j int) bool { return thresholds[i].Duration > thresholds[j].Duration })
+	config.Thresholds = thresholds
+
+	if config.Timeout.Duration < 0 {
+		return nil, fmt.Errorf("tls.timeout must not be negative")
+	}
+	if config.Timeout.Duration == 0 {
+		config.Timeout = Duration{probeTimeout}
+	}
+	return &tlsSource{config: config}, nil
+}
+
+func (s *tlsSource) Fetch(ctx context.Context) ([]monitor.ConsoleStatus, error) {
+	now := time.Now()
+	consoles := make([]monitor.ConsoleStatus, len(s.config.Hosts))
+	var wg sync.WaitGroup
+	for i, host := range s.config.Hosts {
+		wg.Add(1)
+		go func(i int, host TLSHost) {
+			defer wg.Done()
+			status := defaultDownStatus()
+			if expires, err := s.expiry(ctx, host); err == nil {
+				status = s.status(expires.Sub(now))
+			}
+			consoles[i] = monitor.ConsoleStatus{Name: host.name(), Status: status}
+		}(i, host)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return consoles, nil
+}
+
+// status переводит оставшийся срок сертификата в статус консоли
+func (s *tlsSource) status(left time.Duration) string {
+	if left <= 0 {
+		return defaultDownStatus()
+	}
+	status := "OK"
+	for _, threshold := range s.config.Thresholds {
+		if left <= threshold.Duration {
+			status = "Expires within " + formatThreshold(threshold.Duration)
+		}
+	}
+	return status
+}
+
+// expiry подключается к хосту и возвращает момент, когда истечёт первый
+// сертификат цепочки. Цепочка не проверяется, чтобы истёкший или
+// самоподписанный сертификат тоже можно было прочитать.
+func (s *tlsSource) expiry(ctx context.Context, host TLSHost) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout.Duration)
+	defer cancel()
+
+	port := host.Port
+	if port == 0 {
+		port = 443
+	}
+	serverName := host.ServerName
+	if serverName == "" {
+		serverName = host.Host
+	}
+	dialer := tls.Dialer{Config: &tls.Config{ServerName: serverName, InsecureSkipVerify: true}}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host.Host, strconv.Itoa(port)))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+
+	certificates := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return time.Time{}, fmt.Errorf("no certificates")
+	}
+	expires := certificates[0].NotAfter
+	for _, certificate := range certificates[1:] {
+		if certificate.NotAfter.Before(expires) {
+			expires = certificate.NotAfter
+		}
+	}
+	return expires, nil
+}
+
+// formatThreshold выводит порог в днях, если он кратен суткам: "30d"
+func formatThreshold(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return strconv.Itoa(int(d/(24*time.Hour))) + "d"
+	}
+	return d.String()
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"html"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxMessageLength — предел длины текста сообщения в Telegram
+const maxMessageLength = 4096
+
+// splitOversized разбивает слишком длинное текстовое сообщение на несколько по
+// границам строк. Если отдельная строка не помещается в сообщение (например,
+// неразобранный ответ API), весь текст отправляется файлом с короткой подписью.
+func splitOversized(msg tgbotapi.Chattable) []tgbotapi.Chattable {
+	m, ok := msg.(tgbotapi.MessageConfig)
+	if !ok || utf8.RuneCountInString(m.Text) <= maxMessageLength {
+		return []tgbotapi.Chattable{msg}
+	}
+
+	chunks, ok := splitLines(m.Text, maxMessageLength)
+	if !ok {
+		return []tgbotapi.Chattable{asDocument(m)}
+	}
+
+	parts := make([]tgbotapi.Chattable, 0, len(chunks))
+	for i, chunk := range chunks {
+		part := m
+		part.Text = chunk
+		if i < len(chunks)-1 {
+			// Клавиатура остаётся только под последней частью
+			part.ReplyMarkup = nil
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// splitLines собирает строки текста в куски не длиннее limit символов.
+// Возвращает false, если какая-то строка сама длиннее limit.
+func splitLines(text string, limit int) ([]string, bool) {
+	var chunks []string
+	var b strings.Builder
+	size := 0
+	for _, line := range strings.Split(text, "\n") {
+		n := utf8.RuneCountInString(line)
+		if n > limit {
+			return nil, false
+		}
+		if size > 0 && size+1+n > limit {
+			chunks = append(chunks, b.String())
+			b.Reset()
+			size = 0
+		}
+		if size > 0 {
+			b.WriteByte('\n')
+			size++
+		}
+		b.WriteString(line)
+		size += n
+	}
+	if size > 0 {
+		chunks = append(chunks, b.String())
+	}
+	return chunks, true
+}
+
+// asDocument превращает сообщение в текстовый файл с подписью
+func asDocument(m tgbotapi.MessageConfig) tgbotapi.DocumentConfig {
+	doc := tgbotapi.NewDocument(m.ChatID, tgbotapi.FileBytes{
+		Name:  "status.txt",
+		Bytes: []byte(plainText(m.Text, m.ParseMode)),
+	})
+	doc.Caption = tr(chatLang(m.ChatID), "message.too_long")
+	doc.DisableNotification = m.DisableNotification
+	doc.ReplyMarkup = m.ReplyMarkup
+	return doc
+}
+
+var (
+	htmlTagRe          = regexp.MustCompile(`</?(b|code)>`)
+	markdownV2MarkupRe = regexp.MustCompile("\\\\.|[*`]")
+)
+
+// plainText убирает разметку, которую добавляют bold, code и escapeText
+func plainText(text, parseMode string) string {
+	switch parseMode {
+	case tgbotapi.ModeHTML:
+		return html.UnescapeString(htmlTagRe.ReplaceAllString(text, ""))
+	case tgbotapi.ModeMarkdownV2:
+		return markdownV2MarkupRe.ReplaceAllStringFunc(text, func(match string) string {
+			// Экранированный символ остаётся, сами символы разметки убираются
+			if strings.HasPrefix(match, `\`) {
+				return match[1:]
+			}
+			return ""
+		})
+	}
+	return text
+}
+
+// sendNow отправляет сообщение сразу, минуя очередь, при необходимости по частям
+func sendNow(chatID int64, msg tgbotapi.Chattable) {
+	for _, part := range splitOversized(msg) {
+		if _, err := bot.Send(part); err != nil {
+			slog.Error("Error sending message", "chat_id", chatID, "error", err)
+			return
+		}
+	}
+}
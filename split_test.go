@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+		ok    bool
+	}{
+		{name: "помещается", text: "a\nb", limit: 10, want: []string{"a\nb"}, ok: true},
+		{name: "по границам строк", text: "aaa\nbbb\nccc", limit: 7, want: []string{"aaa\nbbb", "ccc"}, ok: true},
+		{name: "строка ровно в предел", text: "aaaa\nbbbb", limit: 4, want: []string{"aaaa", "bbbb"}, ok: true},
+		{name: "символы, а не байты", text: "ыыы\nжжж", limit: 7, want: []string{"ыыы\nжжж"}, ok: true},
+		{name: "строка длиннее предела", text: "a\nbbbbbb", limit: 5, ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := splitLines(tt.text, tt.limit)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitLines() = %q, %v; want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSplitOversized(t *testing.T) {
+	setupTestBot(t)
+	line := strings.Repeat("x", 100)
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = line
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("ok", "ok")))
+
+	msg := tgbotapi.NewMessage(1, strings.Join(lines, "\n"))
+	msg.ReplyMarkup = keyboard
+	parts := splitOversized(msg)
+	if len(parts) != 3 {
+		t.Fatalf("splitOversized() returned %d parts, want 3", len(parts))
+	}
+	var joined []string
+	for i, part := range parts {
+		m, ok := part.(tgbotapi.MessageConfig)
+		if !ok {
+			t.Fatalf("part %d is %T, want MessageConfig", i, part)
+		}
+		if n := utf8.RuneCountInString(m.Text); n > maxMessageLength {
+			t.Errorf("part %d has %d characters", i, n)
+		}
+		if last := i == len(parts)-1; (m.ReplyMarkup != nil) != last {
+			t.Errorf("part %d keyboard = %v, want only under the last part", i, m.ReplyMarkup)
+		}
+		joined = append(joined, m.Text)
+	}
+	if strings.Join(joined, "\n") != msg.Text {
+		t.Error("parts do not add up to the original text")
+	}
+
+	short := tgbotapi.NewMessage(1, "ok")
+	if parts := splitOversized(short); len(parts) != 1 || !reflect.DeepEqual(parts[0], tgbotapi.Chattable(short)) {
+		t.Errorf("short message was changed: %v", parts)
+	}
+
+	huge := tgbotapi.NewMessage(1, strings.Repeat("x", maxMessageLength+1))
+	if parts := splitOversized(huge); len(parts) != 1 {
+		t.Errorf("oversized line returned %d parts, want one document", len(parts))
+	} else if _, ok := parts[0].(tgbotapi.DocumentConfig); !ok {
+		t.Errorf("oversized line sent as %T, want DocumentConfig", parts[0])
+	}
+}
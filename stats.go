@@ -0,0 +1,72 @@
+package main
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// notificationsTotal — число доставленных уведомлений с момента запуска
+var notificationsTotal int64
+
+// handleStats отправляет статистику бота и подписок
+func handleStats(chatID int64) {
+	lang := chatLang(chatID)
+	lines := make([]string, 0, 3+len(cfg().endpoints()))
+
+	chats, err := store.Chats()
+	if err != nil {
+		slog.Error("Error loading chats", "error", err)
+		lines = append(lines, tr(lang, "stats.chats_unknown"))
+	} else {
+		lines = append(lines, tr(lang, "stats.chats", len(chats)))
+	}
+	lines = append(lines,
+		tr(lang, "stats.uptime", formatDuration(lang, time.Since(startTime))),
+		tr(lang, "stats.sent", atomic.LoadInt64(&notificationsTotal)))
+
+	healthMutex.Lock()
+	for _, endpoint := range cfg().endpoints() {
+		last := tr(lang, "stats.never")
+		if h := health[endpoint.Name]; h != nil && !h.LastSuccessAt.IsZero() {
+			last = tr(lang, "stats.ago", h.LastSuccessAt.Local().Format("02.01.2006 15:04:05"), formatDuration(lang, time.Since(h.LastSuccessAt)))
+		}
+		lines = append(lines, tr(lang, "stats.last_check", endpoint.Name, last))
+	}
+	healthMutex.Unlock()
+
+	bot.Send(tgbotapi.NewMessage(chatID, strings.Join(lines, "\n")))
+}
+
+// formatDuration выводит длительность в виде «2д 3ч 15м» с точностью до секунд
+// для коротких интервалов
+func formatDuration(lang string, d time.Duration) string {
+	if d < time.Minute {
+		return tr(lang, "duration.seconds", int(d.Seconds()))
+	}
+
+	d = d.Round(time.Second)
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	seconds := (d - minutes*time.Minute) / time.Second
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, tr(lang, "duration.days", int(days)))
+	}
+	if hours > 0 {
+		parts = append(parts, tr(lang, "duration.hours", int(hours)))
+	}
+	if minutes > 0 {
+		parts = append(parts, tr(lang, "duration.minutes", int(minutes)))
+	}
+	if days == 0 && hours == 0 && seconds > 0 {
+		parts = append(parts, tr(lang, "duration.seconds", int(seconds)))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"status-bot/monitor"
+	"strings"
+)
+
+// StatusConfig — значок и важность одного статуса консоли
+type StatusConfig struct {
+	Status   string `yaml:"status" json:"status"`     // Статус из API, без учёта регистра
+	Emoji    string `yaml:"emoji" json:"emoji"`       // Значок перед статусом в уведомлениях, сводках и /status
+	Severity string `yaml:"severity" json:"severity"` // critical или info для перехода в этот статус, если правила не назначили важность
+}
+
+func validateStatuses(statuses []StatusConfig) error {
+	seen := make(map[string]bool)
+	for i, s := range statuses {
+		if s.Status == "" {
+			return fmt.Errorf("statuses[%d]: status must be set", i)
+		}
+		key := strings.ToLower(s.Status)
+		if seen[key] {
+			return fmt.Errorf("statuses[%d]: duplicate status %q", i, s.Status)
+		}
+		seen[key] = true
+		switch s.Severity {
+		case "", severityInfo, severityCritical:
+		default:
+			return fmt.Errorf("statuses[%d]: unknown severity %q, expected info or critical", i, s.Severity)
+		}
+	}
+	return nil
+}
+
+// statusConfig находит оформление статуса; false, если статус не описан
+func statusConfig(status string) (StatusConfig, bool) {
+	for _, s := range cfg().Statuses {
+		if strings.EqualFold(s.Status, status) {
+			return s, true
+		}
+	}
+	return StatusConfig{}, false
+}
+
+// statusEmoji возвращает значок статуса из statuses; пусто, если его нет
+func statusEmoji(status string) string {
+	s, _ := statusConfig(status)
+	return s.Emoji
+}
+
+// formatStatus выводит статус моноширинным шрифтом со значком из statuses.
+// Результат размечен для newMarkupMessage.
+func formatStatus(status string) string {
+	if emoji := statusEmoji(status); emoji != "" {
+		return escapeText(emoji) + " " + code(status)
+	}
+	return code(status)
+}
+
+// withStatusEmoji ставит значок статуса перед уже размеченным текстом
+func withStatusEmoji(status, text string) string {
+	if emoji := statusEmoji(status); emoji != "" {
+		return escapeText(emoji) + " " + text
+	}
+	return text
+}
+
+// applyStatusSeverities назначает важность по новому статусу изменениям,
+// которым её не назначили правила
+func applyStatusSeverities(changes []monitor.StatusChange, severities map[string]string) map[string]string {
+	for _, change := range changes {
+		if _, ok := severities[change.Name]; ok {
+			continue
+		}
+		if s, ok := statusConfig(change.New); ok && s.Severity != "" {
+			if severities == nil {
+				severities = make(map[string]string)
+			}
+			severities[change.Name] = s.Severity
+		}
+	}
+	return severities
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	configFileName   = "chat_ids.json"      // Файл для сохранения chat IDs
+	stateFileName    = "status_state.json"  // Файл для сохранения последнего статуса
+	consolesFileName = "chat_consoles.json" // Файл для сохранения подписок на отдельные консоли
+	settingsFileName = "chat_settings.json" // Файл для сохранения настроек чатов
+	historyFileName  = "history.json"       // Файл для сохранения истории смен статуса
+)
+
+// jsonStorage хранит данные в JSON-файлах и переписывает файл целиком при
+// каждом изменении. Подходит для небольшого числа подписчиков.
+type jsonStorage struct {
+	dir      string
+	mu       sync.Mutex
+	chats    map[int64]bool
+	consoles map[int64]map[string]bool
+	settings map[int64]map[string]string
+	state    map[string]string
+	history  []HistoryEntry
+}
+
+func openJSONStorage(dir string) (*jsonStorage, error) {
+	s := &jsonStorage{
+		dir:      dir,
+		chats:    make(map[int64]bool),
+		consoles: make(map[int64]map[string]bool),
+		settings: make(map[int64]map[string]string),
+		state:    make(map[string]string),
+	}
+
+	for name, target := range map[string]interface{}{
+		configFileName:   &s.chats,
+		consolesFileName: &s.consoles,
+		settingsFileName: &s.settings,
+		stateFileName:    &s.state,
+		historyFileName:  &s.history,
+	} {
+		if err := s.load(name, target); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *jsonStorage) load(name string, target interface{}) error {
+	data, err := ioutil.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // Файл ещё не создан
+		}
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// save записывает файл через временный, чтобы сбой не оставил его обрезанным
+func (s *jsonStorage) save(name string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.dir, name)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *jsonStorage) AddChat(chatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.chats[chatID] {
+		return nil
+	}
+	s.chats[chatID] = true
+	return s.save(configFileName, s.chats)
+}
+
+func (s *jsonStorage) RemoveChat(chatID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.chats, chatID)
+	delete(s.consoles, chatID)
+	if err := s.save(configFileName, s.chats); err != nil {
+		return err
+	}
+	return s.save(consolesFileName, s.consoles)
+}
+
+func (s *jsonStorage) Chat(chatID int64) (Chat, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.chats[chatID] {
+		return Chat{}, false, nil
+	}
+	return s.chat(chatID), true, nil
+}
+
+func (s *jsonStorage) Chats() ([]Chat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	chats := make([]Chat, 0, len(s.chats))
+	for id, subscribed := range s.chats {
+		if subscribed {
+			chats = append(chats, s.chat(id))
+		}
+	}
+	sort.Slice(chats, func(i, j int) bool { return chats[i].ID < chats[j].ID })
+	return chats, nil
+}
+
+// chat собирает копию данных чата; вызывается под s.mu
+func (s *jsonStorage) chat(chatID int64) Chat {
+	chat := Chat{ID: chatID, Settings: make(map[string]string)}
+	for console := range s.consoles[chatID] {
+		chat.Consoles = append(chat.Consoles, console)
+	}
+	sort.Strings(chat.Consoles)
+	for key, value := range s.settings[chatID] {
+		chat.Settings[key] = value
+	}
+	return chat
+}
+
+func (s *jsonStorage) AddChatConsole(chatID int64, console string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.chats[chatID] = true
+	if s.consoles[chatID] == nil {
+		s.consoles[chatID] = make(map[string]bool)
+	}
+	s.consoles[chatID][console] = true
+	if err := s.save(configFileName, s.chats); err != nil {
+		return err
+	}
+	return s.save(consolesFileName, s.consoles)
+}
+
+func (s *jsonStorage) RemoveChatConsole(chatID int64, console string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.consoles[chatID], console)
+	if len(s.consoles[chatID]) == 0 {
+		delete(s.consoles, chatID)
+	}
+	return s.save(consolesFileName, s.consoles)
+}
+
+func (s *jsonStorage) ChatSettings(chatID int64) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings := make(map[string]string, len(s.settings[chatID]))
+	for key, value := range s.settings[chatID] {
+		settings[key] = value
+	}
+	return settings, nil
+}
+
+func (s *jsonStorage) SetChatSetting(chatID int64, key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if value == "" {
+		delete(s.settings[chatID], key)
+		if len(s.settings[chatID]) == 0 {
+			delete(s.settings, chatID)
+		}
+	} else {
+		if s.settings[chatID] == nil {
+			s.settings[chatID] = make(map[string]string)
+		}
+		s.settings[chatID][key] = value
+	}
+	return s.save(settingsFileName, s.settings)
+}
+
+func (s *jsonStorage) State(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state[key], nil
+}
+
+func (s *jsonStorage) SetState(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if value == "" {
+		delete(s.state, key)
+	} else {
+		s.state[key] = value
+	}
+	return s.save(stateFileName, s.state)
+}
+
+func (s *jsonStorage) AddHistory(entry HistoryEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.history = append(s.history, entry)
+	return s.save(historyFileName, s.history)
+}
+
+func (s *jsonStorage) History(endpoint, console string, since time.Time) ([]HistoryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []HistoryEntry
+	for _, entry := range s.history {
+		if entry.Endpoint == endpoint && entry.Console == console && !entry.At.Before(since) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (s *jsonStorage) PruneHistory(before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Записи добавляются по времени, поэтому устаревшие всегда в начале
+	i := sort.Search(len(s.history), func(i int) bool { return !s.history[i].At.Before(before) })
+	if i == 0 {
+		return nil
+	}
+	s.history = append([]HistoryEntry(nil), s.history[i:]...)
+	return s.save(historyFileName, s.history)
+}
+
+func (s *jsonStorage) Close() error {
+	return nil
+}
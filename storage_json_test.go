@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestJSONStorageReopen(t *testing.T) {
+	dir := t.TempDir()
+	state := map[string]string{
+		lastStatusKey("api"): `[{"Name":"a","Status":"Online"}]`,
+		maintenanceStateKey:  `{"until":"2026-01-01T00:00:00Z"}`,
+		incidentSeqKey:       "7",
+		openIncidentKey:      "[7]",
+		onCallOverrideKey:    `{"user":"ops"}`,
+		userMonitorsStateKey: "[]",
+		incidentKey(7):       `{"id":7}`,
+		"flapping:api:a":     "1",
+	}
+
+	s, err := openJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range state {
+		if err := s.SetState(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// После перезапуска состояние читается по тем же ключам
+	s, err = openJSONStorage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	for key, want := range state {
+		got, err := s.State(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("State(%q) = %q after reopen, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redisStorage хранит данные в Redis, чтобы подписки переживали пересоздание
+// контейнера и были общими для нескольких реплик бота.
+//
+// Ключи (prefix по умолчанию "status-bot:"):
+//
+//	<prefix>chats                   hash: chat ID → время подписки (unix)
+//	<prefix>chat:<id>:consoles      set: консоли, на которые подписан чат
+//	<prefix>chat:<id>:settings      hash: настройки чата
+//	<prefix>state                   hash: состояние бота
+//	<prefix>history:<api>:<консоль> sorted set: смены статуса (JSON) по времени в мс
+//	<prefix>history                 set: ключи всех history:<api>:<консоль>
+//
+// В именах API и консолей в ключах истории «%» и «:» заменяются на %25 и %3A,
+// чтобы, например, API "a:b" с консолью "c" и API "a" с консолью "b:c" не
+// попадали в один ключ.
+type redisStorage struct {
+	client *redis.Client
+	prefix string
+}
+
+func openRedisStorage(rawURL, prefix string) (*redisStorage, error) {
+	options, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse storage.url: %w", err)
+	}
+
+	client := redis.NewClient(options)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("connect to redis: %w", err)
+	}
+	return &redisStorage{client: client, prefix: prefix}, nil
+}
+
+func (s *redisStorage) chatsKey() string {
+	return s.prefix + "chats"
+}
+
+func (s *redisStorage) consolesKey(chatID int64) string {
+	return fmt.Sprintf("%schat:%d:consoles", s.prefix, chatID)
+}
+
+func (s *redisStorage) settingsKey(chatID int64) string {
+	return fmt.Sprintf("%schat:%d:settings", s.prefix, chatID)
+}
+
+func (s *redisStorage) stateKey() string {
+	return s.prefix + "state"
+}
+
+// historyKeyEscaper экранирует разделитель в частях ключа истории. Имена без
+// «%» и «:» не меняются, поэтому их прежние ключи остаются в силе.
+var historyKeyEscaper = strings.NewReplacer("%", "%25", ":", "%3A")
+
+func (s *redisStorage) historyKey(endpoint, console string) string {
+	return s.prefix + "history:" + historyKeyEscaper.Replace(endpoint) + ":" + historyKeyEscaper.Replace(console)
+}
+
+func (s *redisStorage) historyIndexKey() string {
+	return s.prefix + "history"
+}
+
+func (s *redisStorage) AddChat(chatID int64) error {
+	return s.client.HSetNX(context.Background(), s.chatsKey(), strconv.FormatInt(chatID, 10), time.Now().Unix()).Err()
+}
+
+func (s *redisStorage) RemoveChat(chatID int64) error {
+	ctx := context.Background()
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, s.chatsKey(), strconv.FormatInt(chatID, 10))
+		pipe.Del(ctx, s.consolesKey(chatID))
+		return nil
+	})
+	return err
+}
+
+func (s *redisStorage) Chat(chatID int64) (Chat, bool, error) {
+	ctx := context.Background()
+	subscribedAt, err := s.client.HGet(ctx, s.chatsKey(), strconv.FormatInt(chatID, 10)).Int64()
+	if err == redis.Nil {
+		return Chat{}, false, nil
+	}
+	if err != nil {
+		return Chat{}, false, err
+	}
+
+	chat, err := s.chat(ctx, chatID, subscribedAt)
+	return chat, err == nil, err
+}
+
+func (s *redisStorage) Chats() ([]Chat, error) {
+	ctx := context.Background()
+	entries, err := s.client.HGetAll(ctx, s.chatsKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	chats := make([]Chat, 0, len(entries))
+	for field, value := range entries {
+		chatID, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			continue // Чужой или повреждённый ключ
+		}
+		subscribedAt, _ := strconv.ParseInt(value, 10, 64)
+		chat, err := s.chat(ctx, chatID, subscribedAt)
+		if err != nil {
+			return nil, err
+		}
+		chats = append(chats, chat)
+	}
+	sort.Slice(chats, func(i, j int) bool { return chats[i].ID < chats[j].ID })
+	return chats, nil
+}
+
+// chat загружает консоли и настройки чата
+func (s *redisStorage) chat(ctx context.Context, chatID, subscribedAt int64) (Chat, error) {
+	var consoles *redis.StringSliceCmd
+	var settings *redis.MapStringStringCmd
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		consoles = pipe.SMembers(ctx, s.consolesKey(chatID))
+		settings = pipe.HGetAll(ctx, s.settingsKey(chatID))
+		return nil
+	})
+	if err != nil {
+		return Chat{}, err
+	}
+
+	chat := Chat{
+		ID:           chatID,
+		SubscribedAt: time.Unix(subscribedAt, 0).UTC(),
+		Consoles:     consoles.Val(),
+		Settings:     settings.Val(),
+	}
+	sort.Strings(chat.Consoles)
+	return chat, nil
+}
+
+func (s *redisStorage) AddChatConsole(chatID int64, console string) error {
+	ctx := context.Background()
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSetNX(ctx, s.chatsKey(), strconv.FormatInt(chatID, 10), time.Now().Unix())
+		pipe.SAdd(ctx, s.consolesKey(chatID), console)
+		return nil
+	})
+	return err
+}
+
+func (s *redisStorage) RemoveChatConsole(chatID int64, console string) error {
+	return s.client.SRem(context.Background(), s.consolesKey(chatID), console).Err()
+}
+
+func (s *redisStorage) ChatSettings(chatID int64) (map[string]string, error) {
+	return s.client.HGetAll(context.Background(), s.settingsKey(chatID)).Result()
+}
+
+func (s *redisStorage) SetChatSetting(chatID int64, key, value string) error {
+	ctx := context.Background()
+	if value == "" {
+		return s.client.HDel(ctx, s.settingsKey(chatID), key).Err()
+	}
+	return s.client.HSet(ctx, s.settingsKey(chatID), key, value).Err()
+}
+
+func (s *redisStorage) State(key string) (string, error) {
+	value, err := s.client.HGet(context.Background(), s.stateKey(), key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return value, err
+}
+
+func (s *redisStorage) SetState(key, value string) error {
+	ctx := context.Background()
+	if value == "" {
+		return s.client.HDel(ctx, s.stateKey(), key).Err()
+	}
+	return s.client.HSet(ctx, s.stateKey(), key, value).Err()
+}
+
+func (s *redisStorage) AddHistory(entry HistoryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	key := s.historyKey(entry.Endpoint, entry.Console)
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, key, redis.Z{Score: float64(entry.At.UnixMilli()), Member: data})
+		pipe.SAdd(ctx, s.historyIndexKey(), key)
+		return nil
+	})
+	return err
+}
+
+func (s *redisStorage) History(endpoint, console string, since time.Time) ([]HistoryEntry, error) {
+	values, err := s.client.ZRangeByScore(context.Background(), s.historyKey(endpoint, console), &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]HistoryEntry, 0, len(values))
+	for _, value := range values {
+		var entry HistoryEntry
+		if err := json.Unmarshal([]byte(value), &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (s *redisStorage) PruneHistory(before time.Time) error {
+	ctx := context.Background()
+	keys, err := s.client.SMembers(ctx, s.historyIndexKey()).Result()
+	if err != nil {
+		return err
+	}
+
+	// Верхняя граница исключается: запись ровно в before остаётся
+	max := "(" + strconv.FormatInt(before.UnixMilli(), 10)
+	for _, key := range keys {
+		if err := s.client.ZRemRangeByScore(ctx, key, "-inf", max).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *redisStorage) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,16 @@
+package main
+
+import "testing"
+
+func TestRedisHistoryKey(t *testing.T) {
+	s := &redisStorage{prefix: "status-bot:"}
+	if got := s.historyKey("api", "Консоль 1"); got != "status-bot:history:api:Консоль 1" {
+		t.Errorf("historyKey() = %q, want the unescaped key", got)
+	}
+	if a, b := s.historyKey("a:b", "c"), s.historyKey("a", "b:c"); a == b {
+		t.Errorf("API \"a:b\" with console \"c\" and API \"a\" with console \"b:c\" share the key %q", a)
+	}
+	if a, b := s.historyKey("a%3Ab", "c"), s.historyKey("a:b", "c"); a == b {
+		t.Errorf("escaped and unescaped names share the key %q", a)
+	}
+}
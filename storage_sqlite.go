@@ -0,0 +1,270 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	_ "modernc.org/sqlite"
+	"time"
+)
+
+// sqliteSchema создаёт таблицы при первом запуске
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS chats (
+	id            INTEGER PRIMARY KEY,
+	subscribed_at TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS chat_consoles (
+	chat_id INTEGER NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+	console TEXT NOT NULL,
+	PRIMARY KEY (chat_id, console)
+);
+CREATE TABLE IF NOT EXISTS chat_settings (
+	chat_id INTEGER NOT NULL,
+	key     TEXT NOT NULL,
+	value   TEXT NOT NULL,
+	PRIMARY KEY (chat_id, key)
+);
+CREATE TABLE IF NOT EXISTS state (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS history (
+	endpoint TEXT NOT NULL,
+	console  TEXT NOT NULL,
+	old      TEXT NOT NULL,
+	new      TEXT NOT NULL,
+	at       TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS history_console ON history (endpoint, console, at);
+CREATE INDEX IF NOT EXISTS history_at ON history (at);
+`
+
+// sqliteStorage хранит данные в базе SQLite; каждое изменение —
+// отдельная транзакция, поэтому сбой не оставляет данные наполовину записанными.
+type sqliteStorage struct {
+	db *sql.DB
+}
+
+func openSQLiteStorage(path string) (*sqliteStorage, error) {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path))
+	if err != nil {
+		return nil, err
+	}
+	// SQLite не поддерживает параллельную запись — одного соединения достаточно
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+	return &sqliteStorage{db: db}, nil
+}
+
+// tx выполняет fn в транзакции, откатывая её при ошибке
+func (s *sqliteStorage) tx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func addChatTx(tx *sql.Tx, chatID int64) error {
+	_, err := tx.Exec(`INSERT OR IGNORE INTO chats (id, subscribed_at) VALUES (?, ?)`, chatID, time.Now().UTC())
+	return err
+}
+
+func (s *sqliteStorage) AddChat(chatID int64) error {
+	return s.tx(func(tx *sql.Tx) error {
+		return addChatTx(tx, chatID)
+	})
+}
+
+func (s *sqliteStorage) RemoveChat(chatID int64) error {
+	return s.tx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DELETE FROM chats WHERE id = ?`, chatID)
+		return err
+	})
+}
+
+func (s *sqliteStorage) Chat(chatID int64) (Chat, bool, error) {
+	chats, err := s.chats(`WHERE id = ?`, chatID)
+	if err != nil || len(chats) == 0 {
+		return Chat{}, false, err
+	}
+	return chats[0], true, nil
+}
+
+func (s *sqliteStorage) Chats() ([]Chat, error) {
+	return s.chats("")
+}
+
+// chats загружает чаты, подходящие под условие, вместе с консолями и настройками
+func (s *sqliteStorage) chats(where string, args ...interface{}) ([]Chat, error) {
+	rows, err := s.db.Query(`SELECT id, subscribed_at FROM chats `+where+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var chats []Chat
+	index := make(map[int64]int)
+	for rows.Next() {
+		chat := Chat{Settings: make(map[string]string)}
+		if err := rows.Scan(&chat.ID, &chat.SubscribedAt); err != nil {
+			return nil, err
+		}
+		index[chat.ID] = len(chats)
+		chats = append(chats, chat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(chats) == 0 {
+		return nil, nil
+	}
+
+	consoles, err := s.db.Query(`SELECT chat_id, console FROM chat_consoles
+		WHERE chat_id IN (SELECT id FROM chats `+where+`) ORDER BY chat_id, console`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer consoles.Close()
+	for consoles.Next() {
+		var chatID int64
+		var console string
+		if err := consoles.Scan(&chatID, &console); err != nil {
+			return nil, err
+		}
+		if i, ok := index[chatID]; ok {
+			chats[i].Consoles = append(chats[i].Consoles, console)
+		}
+	}
+	if err := consoles.Err(); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.db.Query(`SELECT chat_id, key, value FROM chat_settings
+		WHERE chat_id IN (SELECT id FROM chats `+where+`)`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer settings.Close()
+	for settings.Next() {
+		var chatID int64
+		var key, value string
+		if err := settings.Scan(&chatID, &key, &value); err != nil {
+			return nil, err
+		}
+		if i, ok := index[chatID]; ok {
+			chats[i].Settings[key] = value
+		}
+	}
+	return chats, settings.Err()
+}
+
+func (s *sqliteStorage) AddChatConsole(chatID int64, console string) error {
+	return s.tx(func(tx *sql.Tx) error {
+		if err := addChatTx(tx, chatID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`INSERT OR IGNORE INTO chat_consoles (chat_id, console) VALUES (?, ?)`, chatID, console)
+		return err
+	})
+}
+
+func (s *sqliteStorage) RemoveChatConsole(chatID int64, console string) error {
+	return s.tx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DELETE FROM chat_consoles WHERE chat_id = ? AND console = ?`, chatID, console)
+		return err
+	})
+}
+
+func (s *sqliteStorage) ChatSettings(chatID int64) (map[string]string, error) {
+	rows, err := s.db.Query(`SELECT key, value FROM chat_settings WHERE chat_id = ?`, chatID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		settings[key] = value
+	}
+	return settings, rows.Err()
+}
+
+func (s *sqliteStorage) SetChatSetting(chatID int64, key, value string) error {
+	return s.tx(func(tx *sql.Tx) error {
+		if value == "" {
+			_, err := tx.Exec(`DELETE FROM chat_settings WHERE chat_id = ? AND key = ?`, chatID, key)
+			return err
+		}
+		_, err := tx.Exec(`INSERT INTO chat_settings (chat_id, key, value) VALUES (?, ?, ?)
+			ON CONFLICT (chat_id, key) DO UPDATE SET value = excluded.value`, chatID, key, value)
+		return err
+	})
+}
+
+func (s *sqliteStorage) State(key string) (string, error) {
+	var value string
+	err := s.db.QueryRow(`SELECT value FROM state WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+func (s *sqliteStorage) SetState(key, value string) error {
+	return s.tx(func(tx *sql.Tx) error {
+		if value == "" {
+			_, err := tx.Exec(`DELETE FROM state WHERE key = ?`, key)
+			return err
+		}
+		_, err := tx.Exec(`INSERT INTO state (key, value) VALUES (?, ?)
+			ON CONFLICT (key) DO UPDATE SET value = excluded.value`, key, value)
+		return err
+	})
+}
+
+func (s *sqliteStorage) AddHistory(entry HistoryEntry) error {
+	_, err := s.db.Exec(`INSERT INTO history (endpoint, console, old, new, at) VALUES (?, ?, ?, ?, ?)`,
+		entry.Endpoint, entry.Console, entry.Old, entry.New, entry.At.UTC())
+	return err
+}
+
+func (s *sqliteStorage) History(endpoint, console string, since time.Time) ([]HistoryEntry, error) {
+	rows, err := s.db.Query(`SELECT old, new, at FROM history
+		WHERE endpoint = ? AND console = ? AND at >= ? ORDER BY at, rowid`, endpoint, console, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []HistoryEntry
+	for rows.Next() {
+		entry := HistoryEntry{Endpoint: endpoint, Console: console}
+		if err := rows.Scan(&entry.Old, &entry.New, &entry.At); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (s *sqliteStorage) PruneHistory(before time.Time) error {
+	_, err := s.db.Exec(`DELETE FROM history WHERE at < ?`, before.UTC())
+	return err
+}
+
+func (s *sqliteStorage) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"log/slog"
+	"status-bot/monitor"
+)
+
+func handleSubscribe(chatID int64, args string) {
+	console := resolveConsole(args)
+	if console == "" {
+		reply(chatID, "subscribe.usage")
+		return
+	}
+
+	if err := store.AddChatConsole(chatID, console); err != nil {
+		slog.Error("Error subscribing chat to console", "chat_id", chatID, "console", console, "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+
+	chat, _, err := store.Chat(chatID)
+	if err != nil {
+		slog.Error("Error loading chat", "chat_id", chatID, "error", err)
+	}
+
+	lang := chatLang(chatID)
+	reply(chatID, "subscribe.done", consoleName(lang, console), consoleNames(lang, chat.Consoles))
+}
+
+func handleUnsubscribe(chatID int64, args string) {
+	console := resolveConsole(args)
+	if console == "" {
+		reply(chatID, "unsubscribe.usage")
+		return
+	}
+
+	chat, _, err := store.Chat(chatID)
+	if err != nil {
+		slog.Error("Error loading chat", "chat_id", chatID, "error", err)
+		reply(chatID, "error.load")
+		return
+	}
+	lang := chatLang(chatID)
+	if !containsString(chat.Consoles, console) {
+		reply(chatID, "unsubscribe.not_sub", consoleName(lang, console))
+		return
+	}
+
+	if err := store.RemoveChatConsole(chatID, console); err != nil {
+		slog.Error("Error unsubscribing chat from console", "chat_id", chatID, "console", console, "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+
+	remaining := removeString(chat.Consoles, console)
+	if len(remaining) == 0 {
+		reply(chatID, "unsubscribe.all_left", consoleName(lang, console))
+		return
+	}
+	reply(chatID, "unsubscribe.done", consoleName(lang, console), consoleNames(lang, remaining))
+}
+
+// filterChanges оставляет только изменения выбранных консолей.
+// Пустой список означает подписку на все консоли.
+func filterChanges(changes []monitor.StatusChange, consoles []string) []monitor.StatusChange {
+	if len(consoles) == 0 {
+		return changes
+	}
+
+	filtered := make([]monitor.StatusChange, 0, len(changes))
+	for _, change := range changes {
+		if containsString(consoles, change.Name) {
+			filtered = append(filtered, change)
+		}
+	}
+	return filtered
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(list []string, s string) []string {
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if item != s {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"net/http"
+	"strings"
+)
+
+// updatesBuffer — размер канала обновлений, как у BotAPI по умолчанию
+const updatesBuffer = 100
+
+// Sender — исходящие вызовы Bot API: сообщения, правки и ответы на кнопки
+type Sender interface {
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+	// MakeRequest вызывает метод Bot API, которого ещё нет в tgbotapi
+	MakeRequest(endpoint string, params tgbotapi.Params) (*tgbotapi.APIResponse, error)
+	// UploadFiles — то же с файлами, например сертификатом вебхука
+	UploadFiles(endpoint string, params tgbotapi.Params, files []tgbotapi.RequestFile) (*tgbotapi.APIResponse, error)
+}
+
+// Updater — получение обновлений long polling или через вебхук
+type Updater interface {
+	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
+	StopReceivingUpdates()
+	HandleUpdate(r *http.Request) (*tgbotapi.Update, error)
+	GetWebhookInfo() (tgbotapi.WebhookInfo, error)
+}
+
+// Telegram — клиент Bot API, через который работает весь бот. Обычно это
+// *tgbotapi.BotAPI; обработчики и рассылка не зависят от него напрямую,
+// поэтому его можно подменить поддельным клиентом или другим транспортом.
+type Telegram interface {
+	Sender
+	Updater
+}
+
+// Проверка, что клиент библиотеки подходит под интерфейс
+var _ Telegram = (*tgbotapi.BotAPI)(nil)
+
+// TelegramConfig настраивает подключение к Bot API
+type TelegramConfig struct {
+	Proxy  string `yaml:"proxy" json:"proxy"`     // Прокси http://, https:// или socks5:// для api.telegram.org; пусто — HTTP_PROXY/HTTPS_PROXY из окружения
+	APIURL string `yaml:"api_url" json:"api_url"` // Адрес своего сервера telegram-bot-api, например http://localhost:8081; пусто — api.telegram.org
+}
+
+func (c TelegramConfig) validate() error {
+	if _, err := proxyFunc(c.Proxy); err != nil {
+		return fmt.Errorf("telegram.proxy: %w", err)
+	}
+	if c.APIURL != "" && !strings.HasPrefix(c.APIURL, "http://") && !strings.HasPrefix(c.APIURL, "https://") {
+		return fmt.Errorf("telegram.api_url must be an http(s) URL")
+	}
+	return nil
+}
+
+// endpoint возвращает шаблон адреса методов Bot API в формате tgbotapi.APIEndpoint
+func (c TelegramConfig) endpoint() string {
+	if c.APIURL == "" {
+		return tgbotapi.APIEndpoint
+	}
+	return strings.TrimSuffix(c.APIURL, "/") + "/bot%s/%s"
+}
+
+// newTelegramAPI авторизуется в Bot API (api.telegram.org или свой сервер)
+// через HTTP-клиент с настроенным прокси.
+// Таймаут у клиента не задаётся: long polling держит запрос открытым.
+func newTelegramAPI(token string, config TelegramConfig) (*tgbotapi.BotAPI, error) {
+	// Адрес уже проверен в validate
+	proxy, _ := proxyFunc(config.Proxy)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	return tgbotapi.NewBotAPIWithClient(token, config.endpoint(), &http.Client{Transport: transport})
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"status-bot/monitor"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplatesConfig задаёт текст уведомлений шаблонами text/template.
+// Пустой шаблон означает встроенную формулировку на языке чата.
+// При заданном parse_mode переменные подставляются уже экранированными,
+// а разметку в тексте самого шаблона оператор пишет сам.
+type TemplatesConfig struct {
+	Change       string `yaml:"change" json:"change"`             // Строка об изменении одной консоли
+	Notification string `yaml:"notification" json:"notification"` // Уведомление целиком
+}
+
+// ChangeData — переменные шаблона templates.change
+type ChangeData struct {
+	Console  string    // Имя консоли
+	Old      string    // Предыдущий статус; пусто — консоль появилась
+	New      string    // Новый статус; пусто — консоль пропала
+	OldEmoji string    // Значок предыдущего статуса из statuses
+	NewEmoji string    // Значок нового статуса из statuses
+	Endpoint string    // Имя API
+	Time     time.Time // Время проверки
+
+	Kind      string    // changed, down (консоль стала недоступна) или recovered (восстановилась)
+	DownSince time.Time // Для down — начало недоступности
+	Downtime  string    // Для recovered — длительность недоступности на языке чата
+	Incident  int       // Номер инцидента для down и recovered; 0 — нет инцидента
+}
+
+// NotificationData — переменные шаблона templates.notification
+type NotificationData struct {
+	Endpoint string       // Имя API
+	Time     time.Time    // Время проверки
+	Changes  []ChangeData // Изменения по консолям
+	Text     string       // Изменения, уже отрендеренные по строке на консоль
+}
+
+// notificationTemplates — шаблоны из конфигурации; nil, если шаблон не задан
+type notificationTemplates struct {
+	change       *template.Template
+	notification *template.Template
+}
+
+// templateFuncs — функции, доступные в шаблонах
+var templateFuncs = template.FuncMap{
+	"dash":  orDash,
+	"emoji": statusEmoji,
+	"bold":  wrapBold,
+	"code":  wrapCode,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"time": func(layout string, t time.Time) string {
+		return t.Format(layout)
+	},
+}
+
+// compile разбирает шаблоны из конфигурации
+func (c TemplatesConfig) compile() (notificationTemplates, error) {
+	var result notificationTemplates
+	var err error
+	if c.Change != "" {
+		if result.change, err = template.New("change").Funcs(templateFuncs).Parse(c.Change); err != nil {
+			return result, fmt.Errorf("templates.change: %w", err)
+		}
+	}
+	if c.Notification != "" {
+		if result.notification, err = template.New("notification").Funcs(templateFuncs).Parse(c.Notification); err != nil {
+			return result, fmt.Errorf("templates.notification: %w", err)
+		}
+	}
+	return result, nil
+}
+
+func (c TemplatesConfig) validate() error {
+	_, err := c.compile()
+	return err
+}
+
+// renderChanges формирует текст уведомления об изменениях консолей одного API.
+// Если шаблон не удаётся выполнить, используется встроенная формулировка.
+func renderChanges(lang string, endpoint Endpoint, changes []monitor.StatusChange, transitions map[string]transition, now time.Time) string {
+	name := escapeText(endpoint.Name)
+	templates := snapshot().templates
+	data := NotificationData{Endpoint: name, Time: now}
+	onlyEvents := true
+	for _, change := range changes {
+		t := transitions[change.Name]
+		data.Changes = append(data.Changes, ChangeData{
+			Console:   escapeText(consoleName(lang, change.Name)),
+			Old:       escapeText(change.Old),
+			New:       escapeText(change.New),
+			OldEmoji:  escapeText(statusEmoji(change.Old)),
+			NewEmoji:  escapeText(statusEmoji(change.New)),
+			Endpoint:  name,
+			Time:      now,
+			Kind:      t.kind,
+			DownSince: t.since,
+			Downtime:  escapeText(formatDuration(lang, t.downtime)),
+			Incident:  t.incident.ID,
+		})
+		if t.kind != changeDown && t.kind != changeRecovered {
+			onlyEvents = false
+		}
+	}
+
+	if templates.change != nil {
+		lines := make([]string, 0, len(data.Changes))
+		for _, change := range data.Changes {
+			line, err := executeTemplate(templates.change, change)
+			if err != nil {
+				slog.Error("Error rendering change template", "error", err)
+				lines = nil
+				break
+			}
+			lines = append(lines, line)
+		}
+		if lines != nil {
+			data.Text = strings.Join(lines, "\n")
+		}
+	}
+	if data.Text == "" {
+		lines := make([]string, 0, len(changes))
+		for _, change := range changes {
+			lines = append(lines, formatTransition(lang, change, transitions[change.Name]))
+		}
+		data.Text = strings.Join(lines, "\n")
+	}
+
+	if templates.notification != nil {
+		text, err := executeTemplate(templates.notification, data)
+		if err == nil {
+			return text
+		}
+		slog.Error("Error rendering notification template", "error", err)
+	}
+	if onlyEvents {
+		// Недоступность и восстановление говорят сами за себя
+		return trMarkup(lang, "status.events", name, data.Text)
+	}
+	return trMarkup(lang, "status.changed", name, data.Text)
+}
+
+func executeTemplate(t *template.Template, data interface{}) (string, error) {
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"status-bot/monitor"
+	"strings"
+	"time"
+)
+
+// handleTestAlert обрабатывает /testalert <консоль> [статус]: рассылает
+// выдуманное изменение статуса консоли через все каналы так же, как настоящее
+// (шаблоны, правила, подписки на консоли, /mute, тихие часы, очередь рассылки),
+// но не трогает сохранённые статусы, историю и инциденты.
+// По умолчанию консоль становится недоступна.
+func handleTestAlert(chatID int64, args string) {
+	fields := consoleFields(args)
+	if len(fields) == 0 {
+		reply(chatID, "testalert.usage")
+		return
+	}
+	name := fields[0]
+	status := defaultDownStatus()
+	if len(fields) > 1 {
+		status = strings.Join(fields[1:], " ")
+	}
+
+	// Консоль ищется в последних ответах всех API, иначе берётся первое API
+	endpoints := cfg().endpoints()
+	endpoint := endpoints[0]
+	old := "OK"
+	for _, e := range endpoints {
+		if console, ok := consoleStatus(e.Name, name); ok {
+			endpoint, name, old = e, console.Name, console.Status
+			break
+		}
+	}
+	if old == status {
+		old = "OK"
+		if status == old {
+			old = defaultDownStatus()
+		}
+	}
+
+	now := time.Now()
+	change := monitor.StatusChange{Name: name, Old: old, New: status}
+	t := transition{kind: changeUpdated}
+	switch {
+	case isDown(status) && !isDown(old):
+		t = transition{kind: changeDown, since: now}
+	case isDown(old) && !isDown(status):
+		t = transition{kind: changeRecovered}
+	}
+	transitions := map[string]transition{name: t}
+
+	changes, severities := applyRules(endpoint.Name, []monitor.StatusChange{change}, transitions)
+	severities = applyStatusSeverities(changes, severities)
+	if len(changes) == 0 {
+		reply(chatID, "testalert.filtered", consoleName(chatLang(chatID), name))
+		return
+	}
+	dispatchEvent(context.Background(), StatusEvent{
+		Endpoint:    endpoint,
+		Time:        now,
+		Changes:     changes,
+		Transitions: transitions,
+		Severities:  severities,
+		Test:        true,
+	})
+	reply(chatID, "testalert.sent", endpoint.Name, consoleName(chatLang(chatID), name), old, status, len(sinks))
+}
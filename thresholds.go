@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"status-bot/monitor"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ThresholdConfig следит за числовым полем консолей, например нагрузкой или
+// числом игроков. Для каждой консоли, у которой есть это поле, появляется
+// отдельная консоль «<консоль>: <name>» со статусом ok_status или status,
+// и её смены рассылаются как обычные изменения статуса.
+type ThresholdConfig struct {
+	Name     string   `yaml:"name" json:"name"`           // Имя показателя, например load
+	Endpoint string   `yaml:"endpoint" json:"endpoint"`   // Только для этого API; пусто — для всех
+	Consoles string   `yaml:"consoles" json:"consoles"`   // Регулярное выражение для имён консолей; пусто — все
+	Value    string   `yaml:"value" json:"value"`         // Путь JSONPath к числу внутри элемента-консоли, например @.Load
+	Above    *float64 `yaml:"above" json:"above"`         // Тревога, если значение больше
+	Below    *float64 `yaml:"below" json:"below"`         // Тревога, если значение меньше
+	Clear    *float64 `yaml:"clear" json:"clear"`         // Порог отмены тревоги (гистерезис); по умолчанию above или below
+	For      Duration `yaml:"for" json:"for"`             // Сколько значение должно держаться за порогом до тревоги
+	Status   string   `yaml:"status" json:"status"`       // Статус при тревоге; по умолчанию первый из down_statuses
+	OKStatus string   `yaml:"ok_status" json:"ok_status"` // Статус без тревоги; по умолчанию OK
+}
+
+// threshold — правило с разобранными путём и выражением
+type threshold struct {
+	config   ThresholdConfig
+	value    monitor.JSONPath
+	consoles *regexp.Regexp
+}
+
+func (c ThresholdConfig) compile() (threshold, error) {
+	if c.Name == "" {
+		return threshold{}, fmt.Errorf("name must be set")
+	}
+	if (c.Above == nil) == (c.Below == nil) {
+		return threshold{}, fmt.Errorf("exactly one of above or below must be set")
+	}
+	if c.Clear != nil && c.Above != nil && *c.Clear > *c.Above {
+		return threshold{}, fmt.Errorf("clear must not exceed above")
+	}
+	if c.Clear != nil && c.Below != nil && *c.Clear < *c.Below {
+		return threshold{}, fmt.Errorf("clear must not be less than below")
+	}
+	if c.For.Duration < 0 {
+		return threshold{}, fmt.Errorf("for must not be negative")
+	}
+	value, err := monitor.ParseJSONPath(c.Value)
+	if err != nil {
+		return threshold{}, fmt.Errorf("value: %w", err)
+	}
+	result := threshold{config: c, value: value}
+	if c.Consoles != "" {
+		if result.consoles, err = regexp.Compile(c.Consoles); err != nil {
+			return threshold{}, fmt.Errorf("consoles: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// compileThresholds разбирает числовые правила из конфигурации
+func compileThresholds(configs []ThresholdConfig) ([]threshold, error) {
+	result := make([]threshold, 0, len(configs))
+	for i, config := range configs {
+		t, err := config.compile()
+		if err != nil {
+			return nil, fmt.Errorf("thresholds[%d]: %w", i, err)
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+// breached сообщает, за порогом ли значение. Уже поднятая тревога
+// снимается только за порогом clear, чтобы значение у самой границы не мигало.
+func (t threshold) breached(value float64, alerting bool) bool {
+	if t.config.Above != nil {
+		limit := *t.config.Above
+		if alerting && t.config.Clear != nil {
+			limit = *t.config.Clear
+		}
+		return value > limit
+	}
+	limit := *t.config.Below
+	if alerting && t.config.Clear != nil {
+		limit = *t.config.Clear
+	}
+	return value < limit
+}
+
+// thresholdState — состояние правила для одной консоли
+type thresholdState struct {
+	since    time.Time // Когда значение вышло за порог; нулевое — не за порогом
+	alerting bool
+}
+
+var (
+	thresholdMutex  = &sync.Mutex{}
+	thresholdStates = make(map[string]*thresholdState) // По endpoint + "\x00" + консоль правила
+)
+
+// applyThresholds добавляет к статусам консоли числовых правил
+func applyThresholds(endpoint string, consoles []monitor.ConsoleStatus, now time.Time) []monitor.ConsoleStatus {
+	thresholds := snapshot().thresholds
+	if len(thresholds) == 0 {
+		return consoles
+	}
+
+	thresholdMutex.Lock()
+	defer thresholdMutex.Unlock()
+
+	result := consoles
+	for _, t := range thresholds {
+		if t.config.Endpoint != "" && t.config.Endpoint != endpoint {
+			continue
+		}
+		for _, console := range consoles {
+			if t.consoles != nil && !t.consoles.MatchString(console.Name) {
+				continue
+			}
+			value, ok := numericValue(t.value.Eval(console.Raw))
+			if !ok {
+				continue
+			}
+
+			name := console.Name + ": " + t.config.Name
+			key := endpoint + "\x00" + name
+			state := thresholdStates[key]
+			if state == nil {
+				state = &thresholdState{}
+				thresholdStates[key] = state
+			}
+			if !t.breached(value, state.alerting) {
+				state.since, state.alerting = time.Time{}, false
+			} else {
+				if state.since.IsZero() {
+					state.since = now
+				}
+				if now.Sub(state.since) >= t.config.For.Duration {
+					state.alerting = true
+				}
+			}
+			result = append(result, monitor.ConsoleStatus{Name: name, Status: t.status(state.alerting)})
+		}
+	}
+	return result
+}
+
+// status возвращает статус консоли правила
+func (t threshold) status(alerting bool) string {
+	if alerting {
+		if t.config.Status != "" {
+			return t.config.Status
+		}
+		return defaultDownStatus()
+	}
+	if t.config.OKStatus != "" {
+		return t.config.OKStatus
+	}
+	return "OK"
+}
+
+// numericValue возвращает первое значение числом; строки с числом тоже подходят
+func numericValue(nodes []interface{}) (float64, bool) {
+	if len(nodes) == 0 {
+		return 0, false
+	}
+	switch v := nodes[0].(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
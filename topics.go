@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"net/http"
+	"sort"
+	"status-bot/monitor"
+	"strconv"
+	"strings"
+)
+
+const (
+	topicSetting       = "topic"  // Тема форума, куда приходят уведомления чата; значение — message_thread_id
+	topicSettingPrefix = "topic:" // Префикс настроек тем для отдельных консолей
+)
+
+// chatTopic возвращает тему форума для уведомлений о консоли: свою тему
+// консоли, иначе тему чата; 0 — без темы («General»)
+func chatTopic(settings map[string]string, console string) int {
+	if console != "" {
+		if thread, err := strconv.Atoi(settings[topicSettingPrefix+console]); err == nil {
+			return thread
+		}
+	}
+	thread, _ := strconv.Atoi(settings[topicSetting])
+	return thread
+}
+
+// topicChanges — изменения, которые уходят в одну тему форума
+type topicChanges struct {
+	thread  int
+	changes []monitor.StatusChange
+}
+
+// groupByTopic раскладывает изменения по темам форума в порядке первого появления
+func groupByTopic(changes []monitor.StatusChange, settings map[string]string) []topicChanges {
+	var groups []topicChanges
+	index := make(map[int]int)
+	for _, change := range changes {
+		thread := chatTopic(settings, change.Name)
+		i, ok := index[thread]
+		if !ok {
+			i = len(groups)
+			index[thread] = i
+			groups = append(groups, topicChanges{thread: thread})
+		}
+		groups[i].changes = append(groups[i].changes, change)
+	}
+	return groups
+}
+
+// handleTopic обрабатывает /topic, /topic <here|ID|ссылка> [консоли...] и /topic off [консоли...]
+func handleTopic(ctx context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	if !message.Chat.IsSuperGroup() {
+		reply(chatID, "topic.supergroup_only")
+		return
+	}
+
+	fields := consoleFields(message.CommandArguments())
+	if len(fields) == 0 {
+		listTopics(chatID)
+		return
+	}
+
+	consoles := fields[1:]
+	if strings.ToLower(fields[0]) == "off" {
+		for _, key := range topicKeys(consoles) {
+			if err := store.SetChatSetting(chatID, key, ""); err != nil {
+				slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+				reply(chatID, "error.save")
+				return
+			}
+		}
+		reply(chatID, "topic.off")
+		return
+	}
+
+	var thread int
+	if strings.ToLower(fields[0]) == "here" {
+		// В теме форума Telegram делает каждое сообщение ответом на первое
+		// сообщение темы, ID которого и есть message_thread_id
+		if message.ReplyToMessage == nil {
+			reply(chatID, "topic.not_in_topic")
+			return
+		}
+		thread = message.ReplyToMessage.MessageID
+	} else {
+		var ok bool
+		if thread, ok = parseTopicRef(fields[0]); !ok {
+			reply(chatID, "topic.usage")
+			return
+		}
+	}
+
+	// Подтверждение в саму тему заодно проверяет, что она существует
+	lang := chatLang(chatID)
+	text := tr(lang, "topic.here")
+	if len(consoles) > 0 {
+		text = tr(lang, "topic.here_consoles", consoleNames(lang, consoles))
+	}
+	if _, err := sendToTopic(tgbotapi.NewMessage(chatID, text), thread); err != nil {
+		slog.Warn("Error sending to forum topic", "chat_id", chatID, "thread_id", thread, "error", err)
+		reply(chatID, "topic.not_found", thread)
+		return
+	}
+	for _, key := range topicKeys(consoles) {
+		if err := store.SetChatSetting(chatID, key, strconv.Itoa(thread)); err != nil {
+			slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+			reply(chatID, "error.save")
+			return
+		}
+	}
+}
+
+// topicKeys — настройки тем для консолей; без консолей — тема всего чата
+func topicKeys(consoles []string) []string {
+	if len(consoles) == 0 {
+		return []string{topicSetting}
+	}
+	keys := make([]string, 0, len(consoles))
+	for _, console := range consoles {
+		keys = append(keys, topicSettingPrefix+console)
+	}
+	return keys
+}
+
+// parseTopicRef разбирает ID темы или ссылку на неё: t.me/c/<чат>/<тема>
+// или t.me/<имя>/<тема>, в том числе ссылку на сообщение в теме
+func parseTopicRef(ref string) (int, bool) {
+	link := strings.TrimPrefix(strings.TrimPrefix(ref, "https://"), "http://")
+	if path, ok := strings.CutPrefix(link, "t.me/"); ok {
+		parts := strings.Split(strings.Trim(path, "/"), "/")
+		if parts[0] == "c" {
+			parts = parts[1:]
+		}
+		if len(parts) < 2 {
+			return 0, false
+		}
+		ref = parts[1]
+	}
+	thread, err := strconv.Atoi(ref)
+	return thread, err == nil && thread > 0
+}
+
+// listTopics отправляет темы, куда приходят уведомления чата
+func listTopics(chatID int64) {
+	settings, err := store.ChatSettings(chatID)
+	if err != nil {
+		slog.Error("Error loading chat settings", "chat_id", chatID, "error", err)
+		reply(chatID, "error.load")
+		return
+	}
+	lang := chatLang(chatID)
+	general := tr(lang, "topic.general")
+	lines := []string{tr(lang, "topic.default", orValue(settings[topicSetting], general))}
+	var consoles []string
+	for key, value := range settings {
+		if console := strings.TrimPrefix(key, topicSettingPrefix); console != key && value != "" {
+			consoles = append(consoles, fmt.Sprintf("%s — %s", consoleName(lang, console), value))
+		}
+	}
+	sort.Strings(consoles)
+	reply(chatID, "topic.list", strings.Join(append(lines, consoles...), "\n"))
+}
+
+func orValue(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// sendToTopic отправляет сообщение в тему форума. В tgbotapi нет
+// message_thread_id, поэтому sendMessage вызывается напрямую; остальные
+// сообщения (например, документы) уходят без темы.
+func sendToTopic(msg tgbotapi.Chattable, thread int) (tgbotapi.Message, error) {
+	m, ok := msg.(tgbotapi.MessageConfig)
+	if !ok || thread == 0 {
+		return bot.Send(msg)
+	}
+
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", m.ChatID)
+	params.AddNonZero("message_thread_id", thread)
+	params.AddNonEmpty("text", m.Text)
+	params.AddNonEmpty("parse_mode", m.ParseMode)
+	params.AddBool("disable_web_page_preview", m.DisableWebPagePreview)
+	params.AddBool("disable_notification", m.DisableNotification)
+	params.AddNonZero("reply_to_message_id", m.ReplyToMessageID)
+	if err := params.AddInterface("reply_markup", m.ReplyMarkup); err != nil {
+		return tgbotapi.Message{}, err
+	}
+	if err := params.AddInterface("entities", m.Entities); err != nil {
+		return tgbotapi.Message{}, err
+	}
+
+	var sent tgbotapi.Message
+	resp, err := bot.MakeRequest("sendMessage", params)
+	if err != nil || len(resp.Result) == 0 {
+		return sent, err
+	}
+	return sent, json.Unmarshal(resp.Result, &sent)
+}
+
+// isTopicMissing сообщает, что тему форума удалили или закрыли
+func isTopicMissing(err error) bool {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) || tgErr.Code != http.StatusBadRequest {
+		return false
+	}
+	message := strings.ToLower(tgErr.Message)
+	return strings.Contains(message, "thread not found") || strings.Contains(message, "topic_closed") || strings.Contains(message, "topic_deleted")
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// TracingConfig задаёт отправку трасс OpenTelemetry: проверка API, сравнение
+// ответов, доставка в каналы уведомлений и отправка в Telegram
+type TracingConfig struct {
+	Endpoint    string            `yaml:"endpoint" json:"endpoint"`         // Адрес приёмника OTLP/HTTP, например localhost:4318; пусто — трассировка выключена
+	Insecure    bool              `yaml:"insecure" json:"insecure"`         // Отправлять без TLS
+	Headers     map[string]string `yaml:"headers" json:"headers"`           // Дополнительные заголовки, например для авторизации
+	ServiceName string            `yaml:"service_name" json:"service_name"` // Имя сервиса в трассах
+	SampleRatio float64           `yaml:"sample_ratio" json:"sample_ratio"` // Доля записываемых трасс от 0 до 1
+}
+
+func (c TracingConfig) validate() error {
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("tracing.sample_ratio must be between 0 and 1")
+	}
+	return nil
+}
+
+// tracer создаёт спаны бота; пока трассировка не настроена, спаны ничего не делают
+var tracer = otel.Tracer("status-bot")
+
+var tracerProvider *sdktrace.TracerProvider
+
+// startTracing подключает экспорт трасс, если задан tracing.endpoint
+func startTracing(config TracingConfig) error {
+	if config.Endpoint == "" {
+		return nil
+	}
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.Endpoint)}
+	if config.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	if len(config.Headers) > 0 {
+		options = append(options, otlptracehttp.WithHeaders(config.Headers))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return err
+	}
+
+	tracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", config.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return nil
+}
+
+// stopTracing отправляет накопленные спаны
+func stopTracing() {
+	if tracerProvider == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	tracerProvider.Shutdown(ctx)
+}
+
+// endSpan отмечает ошибку в спане, если она есть, и завершает его
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"log/slog"
+	"status-bot/monitor"
+	"strings"
+	"time"
+)
+
+// defaultUptimePeriod — период /uptime, если он не указан
+const defaultUptimePeriod = 7 * 24 * time.Hour
+
+// availability — доступность консоли за период
+type availability struct {
+	percent   float64
+	downtime  time.Duration
+	incidents int
+}
+
+// sparkBlocks — ступени спарклайна от полной недоступности до полной доступности
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparklineBuckets — на сколько равных отрезков спарклайн делит период
+const sparklineBuckets = 12
+
+// recordHistory сохраняет смены статуса консолей и удаляет устаревшую историю
+func recordHistory(endpoint string, changes []monitor.StatusChange, now time.Time) {
+	for _, change := range changes {
+		entry := HistoryEntry{Endpoint: endpoint, Console: change.Name, Old: change.Old, New: change.New, At: now}
+		if err := store.AddHistory(entry); err != nil {
+			slog.Error("Error saving history", "endpoint", endpoint, "console", change.Name, "error", err)
+		}
+	}
+	if err := store.PruneHistory(now.Add(-cfg().History.Duration)); err != nil {
+		slog.Error("Error pruning history", "error", err)
+	}
+}
+
+// computeAvailability считает доступность консоли за [from, to] по сменам
+// статуса внутри периода. Статус в начале периода берётся из первой смены,
+// а если смен не было — из текущего статуса. Если консоль появилась внутри
+// периода, время до её появления не учитывается.
+func computeAvailability(entries []HistoryEntry, current string, from, to time.Time) availability {
+	state := current
+	if len(entries) > 0 {
+		state = entries[0].Old
+		if state == "" {
+			from = entries[0].At
+		}
+	}
+
+	var result availability
+	cursor := from
+	for _, entry := range entries {
+		if isDown(state) {
+			result.downtime += entry.At.Sub(cursor)
+		} else if isDown(entry.New) {
+			result.incidents++
+		}
+		cursor = entry.At
+		state = entry.New
+	}
+	if isDown(state) {
+		result.downtime += to.Sub(cursor)
+	}
+
+	result.percent = 100
+	if total := to.Sub(from); total > 0 {
+		result.percent = 100 * (1 - float64(result.downtime)/float64(total))
+	}
+	return result
+}
+
+// consoleStatus ищет консоль в последнем ответе API
+func consoleStatus(endpoint, console string) (monitor.ConsoleStatus, bool) {
+	consoles, err := monitor.ParseStatuses(statusEngine().Last(endpoint))
+	if err != nil {
+		return monitor.ConsoleStatus{}, false
+	}
+	for _, c := range consoles {
+		if strings.EqualFold(c.Name, console) {
+			return c, true
+		}
+	}
+	return monitor.ConsoleStatus{}, false
+}
+
+// handleUptime обрабатывает /uptime <консоль> [7d|30d]
+func handleUptime(chatID int64, args string) {
+	fields := consoleFields(args)
+	if len(fields) == 0 || len(fields) > 2 {
+		reply(chatID, "uptime.usage")
+		return
+	}
+
+	period := defaultUptimePeriod
+	if len(fields) == 2 {
+		d, err := parseDuration(fields[1])
+		if err != nil || d <= 0 {
+			reply(chatID, "uptime.usage")
+			return
+		}
+		period = d
+	}
+
+	lang := chatLang(chatID)
+	now := time.Now()
+	from := now.Add(-period)
+	var lines []string
+	for _, endpoint := range cfg().endpoints() {
+		console, ok := consoleStatus(endpoint.Name, fields[0])
+		if !ok {
+			continue
+		}
+		entries, err := store.History(endpoint.Name, console.Name, from)
+		if err != nil {
+			slog.Error("Error loading history", "endpoint", endpoint.Name, "console", console.Name, "error", err)
+			reply(chatID, "error.load")
+			return
+		}
+		a := computeAvailability(entries, console.Status, from, now)
+		lines = append(lines, sparkline(entries, console.Status, from, now)+" "+tr(lang, "uptime.line", endpoint.Name, a.percent, formatDuration(lang, a.downtime), a.incidents))
+	}
+
+	if len(lines) == 0 {
+		reply(chatID, "uptime.unknown", consoleName(lang, fields[0]))
+		return
+	}
+	reply(chatID, "uptime.header", consoleName(lang, fields[0]), formatDuration(lang, period), strings.Join(lines, "\n"))
+}
+
+// sparkline показывает доступность консоли по отрезкам [from, to]: полный
+// блок — без простоя, ниже — чем дольше простой. Любой сбой опускает блок
+// хотя бы на ступень; «·» — консоли ещё не было в ответе API.
+func sparkline(entries []HistoryEntry, current string, from, to time.Time) string {
+	segments := consoleTimeline(entries, current, from, to)
+	step := to.Sub(from) / sparklineBuckets
+	var b strings.Builder
+	for i := 0; i < sparklineBuckets; i++ {
+		start := from.Add(step * time.Duration(i))
+		end := start.Add(step)
+		var covered, down time.Duration
+		for _, segment := range segments {
+			overlap := minTime(segment.To, end).Sub(maxTime(segment.From, start))
+			if overlap <= 0 {
+				continue
+			}
+			covered += overlap
+			if segment.Down {
+				down += overlap
+			}
+		}
+		if covered == 0 {
+			b.WriteRune('·')
+			continue
+		}
+		level := len(sparkBlocks) - 1
+		if down > 0 {
+			level = int(float64(len(sparkBlocks)-1) * (1 - float64(down)/float64(covered)))
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeAvailability(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(10 * time.Hour)
+	at := func(h float64) time.Time { return from.Add(time.Duration(h * float64(time.Hour))) }
+
+	tests := []struct {
+		name      string
+		entries   []HistoryEntry
+		current   string
+		percent   float64
+		downtime  time.Duration
+		incidents int
+	}{
+		{
+			name:    "без смен, доступна",
+			current: "Online",
+			percent: 100,
+		},
+		{
+			name:     "без смен, недоступна весь период",
+			current:  "Error",
+			percent:  0,
+			downtime: 10 * time.Hour,
+		},
+		{
+			name: "один сбой",
+			entries: []HistoryEntry{
+				{Old: "Online", New: "Error", At: at(2)},
+				{Old: "Error", New: "Online", At: at(3)},
+			},
+			current:   "Online",
+			percent:   90,
+			downtime:  time.Hour,
+			incidents: 1,
+		},
+		{
+			name: "сбой до конца периода",
+			entries: []HistoryEntry{
+				{Old: "Online", New: "Error", At: at(5)},
+			},
+			current:   "Error",
+			percent:   50,
+			downtime:  5 * time.Hour,
+			incidents: 1,
+		},
+		{
+			name: "недоступна с начала периода",
+			entries: []HistoryEntry{
+				{Old: "Error", New: "Online", At: at(1)},
+			},
+			current:  "Online",
+			percent:  90,
+			downtime: time.Hour,
+		},
+		{
+			name: "появилась внутри периода",
+			entries: []HistoryEntry{
+				{New: "Online", At: at(5)},
+				{Old: "Online", New: "Error", At: at(9)},
+			},
+			current:   "Error",
+			percent:   80,
+			downtime:  time.Hour,
+			incidents: 1,
+		},
+		{
+			name: "смена между доступными статусами не сбой",
+			entries: []HistoryEntry{
+				{Old: "Online", New: "Busy", At: at(4)},
+			},
+			current: "Busy",
+			percent: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeAvailability(tt.entries, tt.current, from, to)
+			if got.percent != tt.percent || got.downtime != tt.downtime || got.incidents != tt.incidents {
+				t.Errorf("computeAvailability() = %.2f%%, %s, %d incidents; want %.2f%%, %s, %d",
+					got.percent, got.downtime, got.incidents, tt.percent, tt.downtime, tt.incidents)
+			}
+		})
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(12 * time.Hour)
+	at := func(h float64) time.Time { return from.Add(time.Duration(h * float64(time.Hour))) }
+
+	tests := []struct {
+		name    string
+		entries []HistoryEntry
+		current string
+		want    string
+	}{
+		{
+			name:    "всё время доступна",
+			current: "Online",
+			want:    "████████████",
+		},
+		{
+			name:    "всё время недоступна",
+			current: "Error",
+			want:    "▁▁▁▁▁▁▁▁▁▁▁▁",
+		},
+		{
+			name: "сбой в третий час",
+			entries: []HistoryEntry{
+				{Old: "Online", New: "Error", At: at(2)},
+				{Old: "Error", New: "Online", At: at(3)},
+			},
+			current: "Online",
+			want:    "██▁█████████",
+		},
+		{
+			name: "короткий сбой опускает блок",
+			entries: []HistoryEntry{
+				{Old: "Online", New: "Error", At: at(5)},
+				{Old: "Error", New: "Online", At: at(5.1)},
+			},
+			current: "Online",
+			want:    "█████▇██████",
+		},
+		{
+			name: "консоли ещё не было",
+			entries: []HistoryEntry{
+				{New: "Online", At: at(6)},
+			},
+			current: "Online",
+			want:    "······██████",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sparkline(tt.entries, tt.current, from, to); got != tt.want {
+				t.Errorf("sparkline() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,553 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"status-bot/monitor"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// UserMonitorsConfig включает мониторы, которые пользователи добавляют сами
+// командой /add_monitor
+type UserMonitorsConfig struct {
+	Enabled         bool     `yaml:"enabled" json:"enabled"`                                       // Разрешить /add_monitor; по умолчанию выключено
+	DefaultInterval Duration `yaml:"default_interval" json:"default_interval"`                     // Интервал проверки, если он не указан в команде
+	AllowPrivate    bool     `yaml:"allow_private" json:"allow_private"`                           // Разрешить адреса локальной сети и loopback
+	MaxPerUser      int      `yaml:"max_per_user" json:"max_per_user"`                             // Сколько мониторов может добавить один пользователь
+	MinInterval     Duration `yaml:"min_interval" json:"min_interval"`                             // Самый частый допустимый интервал проверки
+	MaxTotal        int      `yaml:"max_total" json:"max_total"`                                   // Сколько мониторов всего; 0 — без ограничения
+	MaxPerHour      int      `yaml:"max_notifications_per_hour" json:"max_notifications_per_hour"` // Сколько уведомлений в час получает пользователь от своих мониторов
+	Confirm         int      `yaml:"confirm" json:"confirm"`                                       // Сколько неудачных проверок подряд считать недоступностью адреса
+}
+
+func (c UserMonitorsConfig) validate() error {
+	if c.DefaultInterval.Duration <= 0 {
+		return fmt.Errorf("user_monitors.default_interval must be positive")
+	}
+	if c.MinInterval.Duration <= 0 {
+		return fmt.Errorf("user_monitors.min_interval must be positive")
+	}
+	if c.DefaultInterval.Duration < c.MinInterval.Duration {
+		return fmt.Errorf("user_monitors.default_interval must not be less than min_interval")
+	}
+	if c.MaxPerUser <= 0 {
+		return fmt.Errorf("user_monitors.max_per_user must be positive")
+	}
+	if c.MaxTotal < 0 || c.MaxPerHour < 0 {
+		return fmt.Errorf("user_monitors.max_total and max_notifications_per_hour must not be negative")
+	}
+	if c.Confirm <= 0 {
+		return fmt.Errorf("user_monitors.confirm must be positive")
+	}
+	return nil
+}
+
+// userMonitorsStateKey — ключ состояния со списком пользовательских мониторов
+const userMonitorsStateKey = "user_monitors"
+
+// userMonitor — адрес, который пользователь попросил проверять. Уведомления
+// уходят только в чат, где монитор добавлен, а список и удаление доступны
+// только его владельцу.
+type userMonitor struct {
+	ID       int       `json:"id"`
+	UserID   int64     `json:"user_id"` // Владелец
+	ChatID   int64     `json:"chat_id"` // Чат для уведомлений
+	URL      string    `json:"url"`
+	Interval Duration  `json:"interval"`
+	Created  time.Time `json:"created"`
+}
+
+// name — имя монитора для engine и ключа последнего статуса; не пересекается
+// с именами API из конфигурации
+func (m userMonitor) name() string {
+	return "user:" + strconv.Itoa(m.ID)
+}
+
+// title — короткое имя монитора в уведомлениях
+func (m userMonitor) title() string {
+	if u, err := url.Parse(m.URL); err == nil && u.Host != "" {
+		return fmt.Sprintf("#%d %s", m.ID, u.Host)
+	}
+	return fmt.Sprintf("#%d", m.ID)
+}
+
+// downKey — ключ состояния с моментом, когда адрес монитора признан недоступным
+func (m userMonitor) downKey() string {
+	return "down_since:" + m.name()
+}
+
+// userMonitorHealth — доступность адреса монитора по результатам проверок
+type userMonitorHealth struct {
+	failures  int       // Неудачных проверок подряд
+	downSince time.Time // Когда адрес признан недоступным; нулевое — доступен
+}
+
+// runningMonitor — проверки одного пользовательского монитора
+type runningMonitor struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var (
+	userMonitorsMutex = &sync.Mutex{}
+	userMonitorsCtx   context.Context                 // Контекст бота; nil — мониторы не запущены
+	runningMonitors   = make(map[int]*runningMonitor) // По ID монитора
+	userMonitorClient *http.Client
+
+	userNotifications = &notificationQuota{sent: make(map[int64][]time.Time)}
+)
+
+// notificationQuota ограничивает число уведомлений пользовательских
+// мониторов за скользящий час
+type notificationQuota struct {
+	mutex sync.Mutex
+	sent  map[int64][]time.Time // Моменты отправки по пользователю
+}
+
+// allow учитывает уведомление пользователя и сообщает, укладывается ли оно
+// в лимит; first — это первое уведомление сверх лимита за текущий час
+func (q *notificationQuota) allow(userID int64, limit int, now time.Time) (ok, first bool) {
+	if limit == 0 {
+		return true, false
+	}
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	recent := q.sent[userID][:0]
+	for _, t := range q.sent[userID] {
+		if now.Sub(t) < time.Hour {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) >= limit {
+		q.sent[userID] = recent
+		// Лишняя отметка показывает, что о превышении уже сообщено
+		if len(recent) == limit {
+			q.sent[userID] = append(recent, now)
+			return false, true
+		}
+		return false, false
+	}
+	q.sent[userID] = append(recent, now)
+	return true, false
+}
+
+// loadUserMonitors читает пользовательские мониторы из хранилища
+func loadUserMonitors() ([]userMonitor, error) {
+	value, err := store.State(userMonitorsStateKey)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	var monitors []userMonitor
+	if err := json.Unmarshal([]byte(value), &monitors); err != nil {
+		return nil, err
+	}
+	return monitors, nil
+}
+
+func saveUserMonitors(monitors []userMonitor) error {
+	if len(monitors) == 0 {
+		return store.SetState(userMonitorsStateKey, "")
+	}
+	data, err := json.Marshal(monitors)
+	if err != nil {
+		return err
+	}
+	return store.SetState(userMonitorsStateKey, string(data))
+}
+
+// startUserMonitors запускает проверки всех сохранённых мониторов
+func startUserMonitors(ctx context.Context) {
+	if !cfg().UserMonitors.Enabled {
+		return
+	}
+	monitors, err := loadUserMonitors()
+	if err != nil {
+		slog.Error("Error loading user monitors", "error", err)
+	}
+
+	userMonitorsMutex.Lock()
+	defer userMonitorsMutex.Unlock()
+	userMonitorClient = newUserMonitorClient(cfg().UserMonitors.AllowPrivate)
+	userMonitorsCtx = ctx
+	for _, m := range monitors {
+		startUserMonitor(m)
+	}
+	if len(monitors) > 0 {
+		slog.Info("Started user monitors", "count", len(monitors))
+	}
+}
+
+// stopUserMonitors останавливает проверки и дожидается их завершения
+func stopUserMonitors() {
+	userMonitorsMutex.Lock()
+	defer userMonitorsMutex.Unlock()
+	for id, running := range runningMonitors {
+		running.cancel()
+		<-running.done
+		delete(runningMonitors, id)
+	}
+	userMonitorsCtx = nil
+}
+
+// startUserMonitor запускает проверки монитора; вызывается под userMonitorsMutex
+func startUserMonitor(m userMonitor) {
+	if userMonitorsCtx == nil {
+		return
+	}
+	source := &statusAPISource{url: m.URL, request: &endpointRequest{header: http.Header{}, base: userMonitorClient}}
+	engine := monitor.NewEngine([]monitor.Target{{Name: m.name(), Source: source, Interval: m.Interval.Duration}})
+	engine.Backoff = cfg().Backoff.monitor()
+	// Недоступные адреса пользователей не должны засорять журнал бота
+	engine.Logger = slog.New(discardHandler{})
+	engine.OnChange = func(ctx context.Context, name, prev, status string) {
+		defer recoverPanic("user monitor change", map[string]string{"monitor": name})
+		if err := store.SetState(lastStatusKey(name), status); err != nil {
+			slog.Error("Error saving last status", "monitor", name, "error", err)
+		}
+		notifyUserMonitor(ctx, m, prev, status)
+	}
+	// Ответы, которые не удалось получить или разобрать, не меняют статусы
+	// консолей, поэтому о недоступности адреса сообщается по результатам проверок
+	health := &userMonitorHealth{}
+	if since, err := store.State(m.downKey()); err == nil && since != "" {
+		health.downSince, _ = time.Parse(time.RFC3339, since)
+	}
+	engine.OnCheck = func(check monitor.Check) {
+		defer recoverPanic("user monitor check", map[string]string{"monitor": check.Target})
+		checkUserMonitor(context.Background(), m, health, check.Err, time.Now())
+	}
+	if status, err := store.State(lastStatusKey(m.name())); err == nil && status != "" {
+		engine.SetLast(m.name(), status)
+	}
+
+	ctx, cancel := context.WithCancel(userMonitorsCtx)
+	running := &runningMonitor{cancel: cancel, done: make(chan struct{})}
+	runningMonitors[m.ID] = running
+	go func() {
+		defer close(running.done)
+		engine.Run(ctx)
+	}()
+}
+
+// stopUserMonitor останавливает проверки монитора; вызывается под userMonitorsMutex
+func stopUserMonitor(id int) {
+	if running := runningMonitors[id]; running != nil {
+		running.cancel()
+		<-running.done
+		delete(runningMonitors, id)
+	}
+}
+
+// notifyUserMonitor сообщает об изменении только в чат владельца монитора
+func notifyUserMonitor(ctx context.Context, m userMonitor, prev, status string) {
+	if prev == "" {
+		// Первая проверка нового монитора — это не изменение
+		return
+	}
+	changes, err := monitor.DiffPayloads(prev, status)
+	if err != nil || len(changes) == 0 {
+		return
+	}
+	now := time.Now()
+	sendUserMonitorAlert(ctx, m, false, now, func(lang string) string {
+		return renderChanges(lang, Endpoint{Name: m.title(), URL: m.URL}, changes, nil, now)
+	})
+}
+
+// checkUserMonitor учитывает результат проверки монитора: после
+// user_monitors.confirm неудачных проверок подряд сообщает о недоступности
+// адреса, после первой удачной — о восстановлении
+func checkUserMonitor(ctx context.Context, m userMonitor, health *userMonitorHealth, err error, now time.Time) {
+	if err != nil {
+		health.failures++
+		if !health.downSince.IsZero() || health.failures < cfg().UserMonitors.Confirm {
+			return
+		}
+		health.downSince = now
+		if err := store.SetState(m.downKey(), now.Format(time.RFC3339)); err != nil {
+			slog.Error("Error saving user monitor state", "monitor", m.name(), "error", err)
+		}
+		sendUserMonitorAlert(ctx, m, true, now, func(lang string) string {
+			return escapeText(tr(lang, "monitors.down", m.title(), err.Error()))
+		})
+		return
+	}
+
+	health.failures = 0
+	if health.downSince.IsZero() {
+		return
+	}
+	downtime := now.Sub(health.downSince)
+	health.downSince = time.Time{}
+	if err := store.SetState(m.downKey(), ""); err != nil {
+		slog.Error("Error saving user monitor state", "monitor", m.name(), "error", err)
+	}
+	sendUserMonitorAlert(ctx, m, false, now, func(lang string) string {
+		return escapeText(tr(lang, "monitors.recovered", m.title(), formatDuration(lang, downtime)))
+	})
+}
+
+// sendUserMonitorAlert отправляет уведомление монитора в его чат, если
+// владелец не превысил user_monitors.max_notifications_per_hour
+func sendUserMonitorAlert(ctx context.Context, m userMonitor, critical bool, now time.Time, text func(lang string) string) {
+	settings, err := store.ChatSettings(m.ChatID)
+	if err != nil {
+		slog.Error("Error loading chat settings", "chat_id", m.ChatID, "error", err)
+	}
+	lang := settingsLang(settings)
+	chat := Chat{ID: m.ChatID, Settings: settings}
+	ok, first := userNotifications.allow(m.UserID, cfg().UserMonitors.MaxPerHour, now)
+	if !ok {
+		if first {
+			notifyChat(ctx, chat, escapeText(tr(lang, "monitors.rate_limited", cfg().UserMonitors.MaxPerHour)), false, nil)
+		}
+		return
+	}
+	notifyChat(ctx, chat, text(lang), critical, nil)
+}
+
+// newUserMonitorClient собирает клиент для пользовательских адресов. Запросы
+// идут напрямую, без http_client.proxy, чтобы проверка адреса видела
+// настоящий сервер: без allowPrivate подключения к loopback, локальной сети
+// и служебным адресам запрещены.
+func newUserMonitorClient(allowPrivate bool) *http.Client {
+	var control func(network, address string, c syscall.RawConn) error
+	if !allowPrivate {
+		control = publicAddressOnly
+	}
+	transport := newAPITransport(cfg().HTTPClient, nil, control)
+	transport.Proxy = nil
+	return &http.Client{
+		Transport: transport,
+		Timeout:   cfg().HTTPClient.ReadTimeout.Duration,
+		// Перенаправления проверяются тем же Control при подключении
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return fmt.Errorf("stopped after %d redirects", len(via))
+			}
+			return nil
+		},
+	}
+}
+
+// publicAddressOnly запрещает подключения к непубличным адресам. Проверка
+// выполняется после разрешения имени, поэтому её не обойти DNS-записью.
+func publicAddressOnly(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return fmt.Errorf("address %s is not public", ip)
+	}
+	return nil
+}
+
+// discardHandler — slog.Handler, который ничего не пишет
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// parseAddMonitor разбирает "<url> [every <интервал>]"
+func parseAddMonitor(args string) (string, time.Duration, error) {
+	fields := strings.Fields(args)
+	var interval time.Duration
+	switch {
+	case len(fields) == 3 && strings.EqualFold(fields[1], "every"):
+		d, err := parseDuration(fields[2])
+		if err != nil || d <= 0 {
+			return "", 0, fmt.Errorf("invalid interval %q", fields[2])
+		}
+		interval = d
+	case len(fields) != 1:
+		return "", 0, fmt.Errorf("expected URL [every INTERVAL]")
+	}
+	u, err := url.Parse(fields[0])
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", 0, fmt.Errorf("expected http(s) URL, got %q", fields[0])
+	}
+	return u.String(), interval, nil
+}
+
+// handleAddMonitor обрабатывает /add_monitor <url> [every <интервал>]
+func handleAddMonitor(ctx context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	if !cfg().UserMonitors.Enabled || message.From == nil {
+		reply(chatID, "monitors.disabled")
+		return
+	}
+	rawURL, interval, err := parseAddMonitor(message.CommandArguments())
+	if err != nil {
+		reply(chatID, "monitors.usage")
+		return
+	}
+	if interval == 0 {
+		interval = cfg().UserMonitors.DefaultInterval.Duration
+	}
+	if interval < cfg().UserMonitors.MinInterval.Duration {
+		reply(chatID, "monitors.interval_too_short", formatDuration(chatLang(chatID), cfg().UserMonitors.MinInterval.Duration))
+		return
+	}
+
+	userMonitorsMutex.Lock()
+	defer userMonitorsMutex.Unlock()
+	monitors, err := loadUserMonitors()
+	if err != nil {
+		slog.Error("Error loading user monitors", "error", err)
+		reply(chatID, "error.load")
+		return
+	}
+	own := 0
+	for _, existing := range monitors {
+		if existing.UserID == message.From.ID {
+			own++
+		}
+		if existing.UserID == message.From.ID && existing.URL == rawURL {
+			reply(chatID, "monitors.duplicate", existing.ID)
+			return
+		}
+	}
+	if own >= cfg().UserMonitors.MaxPerUser {
+		reply(chatID, "monitors.quota", cfg().UserMonitors.MaxPerUser)
+		return
+	}
+	if cfg().UserMonitors.MaxTotal > 0 && len(monitors) >= cfg().UserMonitors.MaxTotal {
+		reply(chatID, "monitors.full")
+		return
+	}
+	m := userMonitor{ID: 1, UserID: message.From.ID, ChatID: chatID, URL: rawURL, Interval: Duration{interval}, Created: time.Now()}
+	for _, existing := range monitors {
+		if existing.ID >= m.ID {
+			m.ID = existing.ID + 1
+		}
+	}
+	if err := saveUserMonitors(append(monitors, m)); err != nil {
+		slog.Error("Error saving user monitors", "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+	startUserMonitor(m)
+	slog.Info("User monitor added", "monitor", m.name(), "user_id", m.UserID, "chat_id", chatID, "url", m.URL)
+	reply(chatID, "monitors.added", m.ID, m.URL, formatDuration(chatLang(chatID), interval))
+}
+
+// handleMyMonitors показывает мониторы автора команды, уведомляющие этот
+// чат: адреса из личных сообщений не должны попадать в группы
+func handleMyMonitors(ctx context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	if !cfg().UserMonitors.Enabled || message.From == nil {
+		reply(chatID, "monitors.disabled")
+		return
+	}
+	monitors, err := loadUserMonitors()
+	if err != nil {
+		slog.Error("Error loading user monitors", "error", err)
+		reply(chatID, "error.load")
+		return
+	}
+
+	lang := chatLang(chatID)
+	var lines []string
+	for _, m := range monitors {
+		if m.UserID != message.From.ID || m.ChatID != chatID {
+			continue
+		}
+		line := fmt.Sprintf("#%d %s — %s", m.ID, m.URL, formatDuration(lang, m.Interval.Duration))
+		if status := monitorStatus(m); status != "" {
+			line += "\n" + status
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		reply(chatID, "monitors.none")
+		return
+	}
+	reply(chatID, "monitors.list", strings.Join(lines, "\n"))
+}
+
+// monitorStatus — последние статусы монитора для /my_monitors
+func monitorStatus(m userMonitor) string {
+	status, err := store.State(lastStatusKey(m.name()))
+	if err != nil || status == "" {
+		return ""
+	}
+	consoles, err := monitor.ParseStatuses(status)
+	if err != nil {
+		return ""
+	}
+	lines := make([]string, 0, len(consoles))
+	for _, console := range consoles {
+		lines = append(lines, "  "+console.Name+": "+orDash(console.Status))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// handleRemoveMonitor обрабатывает /remove_monitor <id>; удалить можно только
+// свой монитор и только из чата, куда он присылает уведомления
+func handleRemoveMonitor(ctx context.Context, message *tgbotapi.Message) {
+	chatID := message.Chat.ID
+	if !cfg().UserMonitors.Enabled || message.From == nil {
+		reply(chatID, "monitors.disabled")
+		return
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(message.CommandArguments()), "#"))
+	if err != nil {
+		reply(chatID, "monitors.usage")
+		return
+	}
+
+	userMonitorsMutex.Lock()
+	defer userMonitorsMutex.Unlock()
+	monitors, err := loadUserMonitors()
+	if err != nil {
+		slog.Error("Error loading user monitors", "error", err)
+		reply(chatID, "error.load")
+		return
+	}
+	kept := monitors[:0:0]
+	var removed *userMonitor
+	for i, m := range monitors {
+		if m.ID == id && m.UserID == message.From.ID && m.ChatID == chatID {
+			removed = &monitors[i]
+			continue
+		}
+		kept = append(kept, m)
+	}
+	if removed == nil {
+		reply(chatID, "monitors.not_found", id)
+		return
+	}
+	if err := saveUserMonitors(kept); err != nil {
+		slog.Error("Error saving user monitors", "error", err)
+		reply(chatID, "error.save")
+		return
+	}
+	stopUserMonitor(id)
+	if err := store.SetState(lastStatusKey(removed.name()), ""); err != nil {
+		slog.Error("Error removing last status", "monitor", removed.name(), "error", err)
+	}
+	if err := store.SetState(removed.downKey(), ""); err != nil {
+		slog.Error("Error removing user monitor state", "monitor", removed.name(), "error", err)
+	}
+	reply(chatID, "monitors.removed", id)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckUserMonitor(t *testing.T) {
+	fake := setupTestBot(t)
+	const chatID = 500
+	m := userMonitor{ID: 1, UserID: 42, ChatID: chatID, URL: "https://example.com/status.json"}
+	health := &userMonitorHealth{}
+	failure := errors.New("connection refused")
+	now := time.Now()
+
+	startSender(cfg().Sender)
+	// Адрес недоступен со второй проверки, о сбое сообщается после третьей
+	// неудачной подряд; удачная после сбоя сообщает о восстановлении
+	for i, err := range []error{nil, failure, failure, failure, failure, nil, nil} {
+		checkUserMonitor(context.Background(), m, health, err, now.Add(time.Duration(i)*time.Minute))
+	}
+	stopSender()
+
+	sent := fake.sentTo(chatID)
+	if len(sent) != 2 {
+		t.Fatalf("sent %d messages, want 2: %v", len(sent), sent)
+	}
+	if !strings.Contains(sent[0].Text, "Монитор #1 example.com недоступен") || !strings.Contains(sent[0].Text, "connection refused") {
+		t.Errorf("down alert = %q", sent[0].Text)
+	}
+	if !strings.Contains(sent[1].Text, "Монитор #1 example.com восстановился через 2м") {
+		t.Errorf("recovery alert = %q", sent[1].Text)
+	}
+	if since, _ := store.State(m.downKey()); since != "" {
+		t.Errorf("down state = %q after recovery, want empty", since)
+	}
+}
+
+func TestCheckUserMonitorQuota(t *testing.T) {
+	fake := setupTestBot(t)
+	config := *cfg()
+	config.UserMonitors.Confirm = 1
+	config.UserMonitors.MaxPerHour = 2
+	setConfig(config)
+	userNotifications = &notificationQuota{sent: make(map[int64][]time.Time)}
+
+	const chatID = 501
+	m := userMonitor{ID: 2, UserID: 43, ChatID: chatID, URL: "https://example.com/"}
+	health := &userMonitorHealth{}
+	now := time.Now()
+
+	startSender(cfg().Sender)
+	for i := 0; i < 3; i++ {
+		checkUserMonitor(context.Background(), m, health, errors.New("timeout"), now)
+		checkUserMonitor(context.Background(), m, health, nil, now)
+	}
+	stopSender()
+
+	// Два уведомления и одно о превышении лимита
+	if n := len(fake.sentTo(chatID)); n != 3 {
+		t.Errorf("sent %d messages, want 3", n)
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"status-bot/monitor"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VaultConfig задаёт получение секретов из HashiCorp Vault. Секреты
+// подставляются вместо переменных окружения: token_env, password_env,
+// sentry_dsn_env и остальные *_env ищут значение сначала в окружении,
+// затем в файле из *_FILE, затем здесь.
+type VaultConfig struct {
+	Address   string            `yaml:"address" json:"address"`     // Адрес Vault, например https://vault:8200; пусто — Vault не используется
+	Token     string            `yaml:"token" json:"token"`         // Токен Vault; если пуст, читается из TokenEnv
+	TokenEnv  string            `yaml:"token_env" json:"token_env"` // Переменная окружения с токеном Vault
+	Namespace string            `yaml:"namespace" json:"namespace"` // Пространство имён Vault Enterprise
+	Secrets   map[string]string `yaml:"secrets" json:"secrets"`     // Имя переменной -> путь#поле секрета, например secret/data/status-bot#token
+	Refresh   Duration          `yaml:"refresh" json:"refresh"`     // Как часто перечитывать секреты; по умолчанию половина срока аренды
+	Timeout   Duration          `yaml:"timeout" json:"timeout"`     // Таймаут запроса к Vault
+}
+
+func (c VaultConfig) validate() error {
+	if c.Address == "" {
+		return nil
+	}
+	if !strings.HasPrefix(c.Address, "http://") && !strings.HasPrefix(c.Address, "https://") {
+		return fmt.Errorf("vault.address must be an http(s) URL")
+	}
+	if c.Timeout.Duration <= 0 {
+		return fmt.Errorf("vault.timeout must be positive")
+	}
+	if c.Refresh.Duration < 0 {
+		return fmt.Errorf("vault.refresh must not be negative")
+	}
+	for name, ref := range c.Secrets {
+		path, field, ok := strings.Cut(ref, "#")
+		if !ok || path == "" || field == "" {
+			return fmt.Errorf("vault.secrets.%s: expected path#field, got %q", name, ref)
+		}
+	}
+	return nil
+}
+
+// vaultClient хранит полученные секреты и продлевает токен и аренды
+type vaultClient struct {
+	config VaultConfig
+	client *http.Client
+	token  string
+
+	mu     sync.RWMutex
+	values map[string]string
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// vault — клиент Vault; nil, если Vault не настроен
+var vault *vaultClient
+
+// startVault получает секреты при запуске и продлевает их в фоне
+func startVault(config VaultConfig) error {
+	if config.Address == "" {
+		return nil
+	}
+	token := config.Token
+	if token == "" {
+		token = getenvSecret(config.TokenEnv)
+	}
+	if token == "" {
+		return fmt.Errorf("vault token is not set, expected token or %s", config.TokenEnv)
+	}
+
+	c := &vaultClient{config: config, client: &http.Client{Timeout: config.Timeout.Duration}, token: token}
+	ctx, cancel := context.WithCancel(context.Background())
+	every, err := c.refresh(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+	c.stop, c.done = cancel, make(chan struct{})
+	vault = c
+	slog.Info("Loaded secrets from Vault", "address", config.Address, "secrets", len(config.Secrets))
+
+	go c.run(ctx, every)
+	return nil
+}
+
+// stopVault останавливает продление аренд
+func stopVault() {
+	if vault == nil {
+		return
+	}
+	vault.stop()
+	<-vault.done
+}
+
+// vaultSecret возвращает секрет, полученный из Vault для переменной name
+func vaultSecret(name string) (string, bool) {
+	if vault == nil {
+		return "", false
+	}
+	vault.mu.RLock()
+	defer vault.mu.RUnlock()
+	value, ok := vault.values[name]
+	return value, ok
+}
+
+// run продлевает токен и перечитывает секреты до остановки. Ошибка не
+// сбрасывает уже полученные значения и повторяется через минуту.
+func (c *vaultClient) run(ctx context.Context, every time.Duration) {
+	defer close(c.done)
+	for every > 0 && monitor.SleepContext(ctx, every) {
+		next, err := c.refresh(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("Error refreshing Vault secrets", "error", err)
+			reportRepeated("vault", "Error refreshing Vault secrets", err, nil)
+			every = time.Minute
+			continue
+		}
+		reportRepeated("vault", "", nil, nil)
+		every = next
+	}
+}
+
+// refresh продлевает токен, если это возможно, и перечитывает секреты.
+// Возвращает паузу до следующего обновления: refresh из конфигурации или
+// половину самого короткого срока аренды; 0 — обновлять не нужно.
+func (c *vaultClient) refresh(ctx context.Context) (time.Duration, error) {
+	var lease time.Duration
+	shortest := func(seconds int, renewable bool) {
+		d := time.Duration(seconds) * time.Second
+		if renewable && d > 0 && (lease == 0 || d < lease) {
+			lease = d
+		}
+	}
+
+	var self struct {
+		Data struct {
+			TTL       int  `json:"ttl"`
+			Renewable bool `json:"renewable"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, "auth/token/lookup-self", &self); err != nil {
+		return 0, fmt.Errorf("vault token lookup: %w", err)
+	}
+	if self.Data.Renewable {
+		var renewed struct {
+			Auth struct {
+				LeaseDuration int  `json:"lease_duration"`
+				Renewable     bool `json:"renewable"`
+			} `json:"auth"`
+		}
+		if err := c.do(ctx, http.MethodPost, "auth/token/renew-self", &renewed); err != nil {
+			return 0, fmt.Errorf("vault token renew: %w", err)
+		}
+		shortest(renewed.Auth.LeaseDuration, renewed.Auth.Renewable)
+	}
+
+	values := make(map[string]string, len(c.config.Secrets))
+	cache := make(map[string]map[string]interface{})
+	for name, ref := range c.config.Secrets {
+		path, field, _ := strings.Cut(ref, "#")
+		data, ok := cache[path]
+		if !ok {
+			var secret struct {
+				LeaseDuration int                    `json:"lease_duration"`
+				Renewable     bool                   `json:"renewable"`
+				Data          map[string]interface{} `json:"data"`
+			}
+			if err := c.do(ctx, http.MethodGet, path, &secret); err != nil {
+				return 0, fmt.Errorf("vault secret %s: %w", path, err)
+			}
+			data = secret.Data
+			// KV версии 2 вкладывает значения в data.data
+			if inner, ok := data["data"].(map[string]interface{}); ok {
+				if _, ok := data["metadata"]; ok {
+					data = inner
+				}
+			}
+			// Динамические секреты перечитываются до окончания аренды
+			shortest(secret.LeaseDuration, secret.LeaseDuration > 0)
+			cache[path] = data
+		}
+		value, ok := data[field]
+		if !ok {
+			return 0, fmt.Errorf("vault secret %s has no field %q", path, field)
+		}
+		if s, ok := value.(string); ok {
+			values[name] = s
+		} else {
+			values[name] = fmt.Sprint(value)
+		}
+	}
+
+	c.mu.Lock()
+	c.values = values
+	c.mu.Unlock()
+
+	if c.config.Refresh.Duration > 0 {
+		return c.config.Refresh.Duration, nil
+	}
+	return lease / 2, nil
+}
+
+// do выполняет запрос к API Vault и разбирает ответ в result
+func (c *vaultClient) do(ctx context.Context, method, path string, result interface{}) error {
+	url := strings.TrimSuffix(c.config.Address, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", c.token)
+	if c.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.config.Namespace)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("vault returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, result)
+}
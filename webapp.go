@@ -0,0 +1,465 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebAppConfig — мини-приложение Telegram с текущим статусом, графиками
+// истории и настройками чата. Страница /webapp отдаётся служебным
+// HTTP-сервером (http_listen), снаружи она должна быть доступна по HTTPS.
+type WebAppConfig struct {
+	URL string `yaml:"url" json:"url"` // Публичный адрес страницы, например https://bot.example.com/webapp; пусто — мини-приложение выключено
+}
+
+func (c WebAppConfig) validate() error {
+	if c.URL != "" && !strings.HasPrefix(c.URL, "https://") {
+		return fmt.Errorf("webapp.url must be an https URL")
+	}
+	return nil
+}
+
+const (
+	// webAppMaxAge — сколько действительны данные запуска мини-приложения
+	webAppMaxAge = 24 * time.Hour
+	// webAppPeriod — период графиков истории
+	webAppPeriod = 24 * time.Hour
+)
+
+// webAppTexts — подписи страницы; отдаются на языке чата вместе с данными
+var webAppTexts = []string{
+	"webapp.title", "webapp.status", "webapp.settings", "webapp.subscribed",
+	"webapp.language", "webapp.quiet_hours", "webapp.quiet_hint", "webapp.digest", "webapp.digest_hint",
+	"webapp.save", "webapp.saved", "webapp.uptime", "status.no_data",
+}
+
+func init() {
+	httpMux.HandleFunc("GET /webapp", handleWebApp)
+	httpMux.HandleFunc("GET /webapp/state", handleWebAppState)
+	httpMux.HandleFunc("POST /webapp/settings", handleWebAppSettings)
+}
+
+// setupWebAppMenu ставит кнопку меню, открывающую мини-приложение, во всех личных чатах
+func setupWebAppMenu() {
+	if cfg().WebApp.URL == "" {
+		return
+	}
+	params := tgbotapi.Params{}
+	params.AddInterface("menu_button", map[string]interface{}{
+		"type":    "web_app",
+		"text":    tr(cfg().DefaultLanguage, "webapp.button"),
+		"web_app": map[string]string{"url": cfg().WebApp.URL},
+	})
+	if _, err := bot.MakeRequest("setChatMenuButton", params); err != nil {
+		slog.Error("Error setting menu button", "error", err)
+	}
+}
+
+// validateInitData проверяет подпись данных запуска мини-приложения
+// (Telegram.WebApp.initData) и возвращает ID пользователя
+func validateInitData(initData, token string, now time.Time) (int64, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return 0, err
+	}
+	hash, err := hex.DecodeString(values.Get("hash"))
+	if err != nil || len(hash) == 0 {
+		return 0, fmt.Errorf("missing hash")
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, key+"="+values.Get(key))
+	}
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(token))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	if !hmac.Equal(mac.Sum(nil), hash) {
+		return 0, fmt.Errorf("invalid hash")
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid auth_date")
+	}
+	if now.Sub(time.Unix(authDate, 0)) > webAppMaxAge {
+		return 0, fmt.Errorf("init data expired")
+	}
+
+	var user struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return 0, fmt.Errorf("invalid user")
+	}
+	return user.ID, nil
+}
+
+// authorizeWebApp проверяет заголовок X-Telegram-Init-Data и возвращает ID
+// пользователя; мини-приложение открывается в личном чате, поэтому он же ID чата
+func authorizeWebApp(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	if cfg().WebApp.URL == "" {
+		http.NotFound(w, r)
+		return 0, false
+	}
+	userID, err := validateInitData(r.Header.Get("X-Telegram-Init-Data"), cfg().botToken(), time.Now())
+	if err != nil {
+		slog.Debug("Rejected web app request", "error", err)
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return 0, false
+	}
+	return userID, true
+}
+
+// timelineSegment — отрезок графика, в течение которого статус консоли не менялся
+type timelineSegment struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Status string    `json:"status"`
+	Down   bool      `json:"down"`
+}
+
+// consoleTimeline делит [from, to] на отрезки по сменам статуса; время до
+// появления консоли в ответе API в график не попадает
+func consoleTimeline(entries []HistoryEntry, current string, from, to time.Time) []timelineSegment {
+	state := current
+	if len(entries) > 0 {
+		state = entries[0].Old
+	}
+	var segments []timelineSegment
+	cursor := from
+	add := func(until time.Time) {
+		if state != "" && until.After(cursor) {
+			segments = append(segments, timelineSegment{From: cursor, To: until, Status: state, Down: isDown(state)})
+		}
+	}
+	for _, entry := range entries {
+		add(entry.At)
+		cursor, state = entry.At, entry.New
+	}
+	add(to)
+	return segments
+}
+
+type webAppState struct {
+	Chat      webAppChat        `json:"chat"`
+	Endpoints []webAppEndpoint  `json:"endpoints"`
+	Languages []string          `json:"languages"`
+	Texts     map[string]string `json:"texts"`
+	From      time.Time         `json:"from"`
+	To        time.Time         `json:"to"`
+}
+
+type webAppChat struct {
+	ID         int64  `json:"id"`
+	Subscribed bool   `json:"subscribed"`
+	Language   string `json:"language"`
+	QuietHours string `json:"quiet_hours"`
+	Digest     string `json:"digest"`
+}
+
+type webAppEndpoint struct {
+	Name      string          `json:"name"`
+	OK        bool            `json:"ok"`
+	LastError string          `json:"last_error,omitempty"`
+	Consoles  []webAppConsole `json:"consoles"`
+}
+
+type webAppConsole struct {
+	Name     string            `json:"name"`
+	Status   string            `json:"status"`
+	Down     bool              `json:"down"`
+	Uptime   float64           `json:"uptime"` // Доступность за период графика, %
+	Timeline []timelineSegment `json:"timeline"`
+}
+
+// buildWebAppState собирает статусы, графики за сутки и настройки чата
+func buildWebAppState(chatID int64) (webAppState, error) {
+	settings, err := store.ChatSettings(chatID)
+	if err != nil {
+		return webAppState{}, err
+	}
+	_, subscribed, err := store.Chat(chatID)
+	if err != nil {
+		return webAppState{}, err
+	}
+
+	lang := settingsLang(settings)
+	to := time.Now()
+	from := to.Add(-webAppPeriod)
+	state := webAppState{
+		Chat:      webAppChat{ID: chatID, Subscribed: subscribed, Language: lang, QuietHours: settings[quietHoursSetting], Digest: settings[digestSetting]},
+		Languages: strings.Split(languages(), ", "),
+		Texts:     make(map[string]string, len(webAppTexts)),
+		From:      from,
+		To:        to,
+	}
+	for _, key := range webAppTexts {
+		state.Texts[key] = tr(lang, key)
+	}
+
+	for _, endpoint := range endpointStatuses() {
+		item := webAppEndpoint{Name: endpoint.Name, OK: endpoint.OK, LastError: endpoint.LastError}
+		for _, console := range endpoint.Consoles {
+			entries, err := store.History(endpoint.Name, console.Name, from)
+			if err != nil {
+				return webAppState{}, err
+			}
+			item.Consoles = append(item.Consoles, webAppConsole{
+				Name:     consoleName(lang, console.Name),
+				Status:   console.Status,
+				Down:     isDown(console.Status),
+				Uptime:   computeAvailability(entries, console.Status, from, to).percent,
+				Timeline: consoleTimeline(entries, console.Status, from, to),
+			})
+		}
+		state.Endpoints = append(state.Endpoints, item)
+	}
+	return state, nil
+}
+
+func handleWebAppState(w http.ResponseWriter, r *http.Request) {
+	chatID, ok := authorizeWebApp(w, r)
+	if !ok {
+		return
+	}
+	state, err := buildWebAppState(chatID)
+	if err != nil {
+		slog.Error("Error building web app state", "chat_id", chatID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// handleWebAppSettings сохраняет настройки чата из мини-приложения. Поля, которых
+// нет в запросе, не меняются; пустая строка выключает тихие часы или сводку.
+func handleWebAppSettings(w http.ResponseWriter, r *http.Request) {
+	chatID, ok := authorizeWebApp(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Subscribed *bool   `json:"subscribed"`
+		Language   *string `json:"language"`
+		QuietHours *string `json:"quiet_hours"`
+		Digest     *string `json:"digest"`
+	}
+	if err := decodeBody(r, &body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// Сначала проверяем всё, чтобы не сохранить настройки наполовину
+	changes := make(map[string]string)
+	if body.Language != nil {
+		if catalogs[*body.Language] == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unknown language"})
+			return
+		}
+		changes[languageSetting] = *body.Language
+	}
+	if body.QuietHours != nil {
+		changes[quietHoursSetting] = ""
+		if *body.QuietHours != "" {
+			q, err := parseQuietHours(*body.QuietHours)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid quiet_hours: " + err.Error()})
+				return
+			}
+			changes[quietHoursSetting] = q.String()
+		}
+	}
+	if body.Digest != nil {
+		changes[digestSetting] = ""
+		if *body.Digest != "" {
+			schedule, err := parseDigestSchedule(*body.Digest)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid digest: " + err.Error()})
+				return
+			}
+			changes[digestSetting] = schedule.String()
+		}
+	}
+
+	if err := saveWebAppSettings(chatID, body.Subscribed, changes); err != nil {
+		slog.Error("Error saving chat settings", "chat_id", chatID, "error", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal error"})
+		return
+	}
+	handleWebAppState(w, r)
+}
+
+func saveWebAppSettings(chatID int64, subscribed *bool, changes map[string]string) error {
+	if subscribed != nil {
+		var err error
+		if *subscribed {
+			err = store.AddChat(chatID)
+		} else {
+			err = store.RemoveChat(chatID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for key, value := range changes {
+		if err := store.SetChatSetting(chatID, key, value); err != nil {
+			return err
+		}
+	}
+	if value, ok := changes[digestSetting]; ok {
+		scheduleChatDigest(chatID, value)
+	}
+	return nil
+}
+
+func handleWebApp(w http.ResponseWriter, r *http.Request) {
+	if cfg().WebApp.URL == "" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := webAppTemplate.Execute(w, cfg().DefaultLanguage); err != nil {
+		slog.Error("Error rendering web app", "error", err)
+	}
+}
+
+// webAppTemplate — страница мини-приложения. Данные и подписи на языке чата
+// приходят из /webapp/state после проверки initData, поэтому без Telegram
+// страница показывает только подсказку.
+var webAppTemplate = template.Must(template.New("webapp").Funcs(template.FuncMap{"tr": tr}).Parse(`<!DOCTYPE html>
+<html lang="{{.}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{tr . "webapp.title"}}</title>
+<script src="https://telegram.org/js/telegram-web-app.js"></script>
+<style>
+body { font-family: system-ui, sans-serif; margin: 0; padding: 1em; background: var(--tg-theme-bg-color, #fff); color: var(--tg-theme-text-color, #000); }
+h2 { font-size: 1.05em; margin: 1.2em 0 .4em; }
+.hint { color: var(--tg-theme-hint-color, #888); font-size: .85em; }
+.console { margin: .5em 0 .8em; }
+.row { display: flex; justify-content: space-between; }
+.ok { color: #4caf50; }
+.down { color: #f44336; font-weight: bold; }
+.bar { display: flex; height: 10px; margin-top: .25em; border-radius: 3px; overflow: hidden; background: var(--tg-theme-secondary-bg-color, #eee); }
+.bar div { height: 100%; }
+label { display: block; margin: .6em 0 .2em; }
+input, select { width: 100%; box-sizing: border-box; padding: .4em; font-size: 1em; }
+input[type=checkbox] { width: auto; }
+button { margin-top: 1em; width: 100%; padding: .6em; font-size: 1em; border: 0; border-radius: 6px; background: var(--tg-theme-button-color, #2481cc); color: var(--tg-theme-button-text-color, #fff); }
+</style>
+</head>
+<body>
+<div id="app"><p class="hint">{{tr . "webapp.unauthorized"}}</p></div>
+<script>
+const tg = window.Telegram && Telegram.WebApp;
+const app = document.getElementById("app");
+let texts = {};
+
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  Object.assign(node, attrs || {});
+  for (const child of children) node.append(child);
+  return node;
+}
+
+async function call(path, body) {
+  const response = await fetch(path, {
+    method: body ? "POST" : "GET",
+    headers: {"X-Telegram-Init-Data": tg.initData, "Content-Type": "application/json"},
+    body: body ? JSON.stringify(body) : undefined,
+  });
+  const data = await response.json();
+  if (!response.ok) throw new Error(data.error);
+  return data;
+}
+
+function timeline(state, console) {
+  const bar = el("div", {className: "bar"});
+  const from = Date.parse(state.from), total = Date.parse(state.to) - from;
+  let cursor = from;
+  for (const s of console.timeline) {
+    const start = Date.parse(s.from), end = Date.parse(s.to);
+    if (start > cursor) bar.append(el("div", {style: "width:" + (100 * (start - cursor) / total) + "%"}));
+    bar.append(el("div", {title: s.status, style: "width:" + (100 * (end - start) / total) + "%;background:" + (s.down ? "#f44336" : "#4caf50")}));
+    cursor = end;
+  }
+  return bar;
+}
+
+function render(state) {
+  texts = state.texts;
+  app.replaceChildren();
+  app.append(el("h2", {textContent: texts["webapp.status"]}));
+  for (const endpoint of state.endpoints) {
+    app.append(el("h2", {textContent: endpoint.name + " " + (endpoint.ok ? "✅" : "⚠️")}));
+    if (endpoint.last_error) app.append(el("div", {className: "hint", textContent: endpoint.last_error}));
+    if (!endpoint.consoles) app.append(el("div", {className: "hint", textContent: texts["status.no_data"]}));
+    for (const c of endpoint.consoles || []) {
+      app.append(el("div", {className: "console"},
+        el("div", {className: "row"},
+          el("span", {textContent: c.name}),
+          el("span", {className: c.down ? "down" : "ok", textContent: c.status})),
+        timeline(state, c),
+        el("div", {className: "hint", textContent: texts["webapp.uptime"] + ": " + c.uptime.toFixed(2) + "%"})));
+    }
+  }
+
+  const chat = state.chat;
+  const subscribed = el("input", {type: "checkbox", checked: chat.subscribed});
+  const language = el("select", {}, ...state.languages.map(code => el("option", {value: code, textContent: code, selected: code === chat.language})));
+  const quiet = el("input", {value: chat.quiet_hours, placeholder: texts["webapp.quiet_hint"]});
+  const digest = el("input", {value: chat.digest, placeholder: texts["webapp.digest_hint"]});
+  const status = el("div", {className: "hint"});
+  const save = el("button", {textContent: texts["webapp.save"]});
+  save.onclick = async () => {
+    try {
+      render(await call("webapp/settings", {subscribed: subscribed.checked, language: language.value, quiet_hours: quiet.value.trim(), digest: digest.value.trim()}));
+      tg.HapticFeedback.notificationOccurred("success");
+      app.lastChild.textContent = texts["webapp.saved"];
+    } catch (e) {
+      status.textContent = e.message;
+    }
+  };
+  app.append(el("h2", {textContent: texts["webapp.settings"]}),
+    el("label", {}, subscribed, " " + texts["webapp.subscribed"]),
+    el("label", {textContent: texts["webapp.language"]}), language,
+    el("label", {textContent: texts["webapp.quiet_hours"]}), quiet,
+    el("label", {textContent: texts["webapp.digest"]}), digest,
+    save, status);
+}
+
+if (tg && tg.initData) {
+  tg.ready();
+  tg.expand();
+  const refresh = () => call("webapp/state").then(render).catch(e => app.replaceChildren(el("p", {className: "hint", textContent: e.message})));
+  refresh();
+  // Статус обновляется, пока приложение открыто; форму настроек не перерисовываем во время ввода
+  setInterval(() => { if (!app.contains(document.activeElement) || document.activeElement === document.body) refresh(); }, 30000);
+}
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+// webhookSecretHeader — заголовок, в котором Telegram возвращает secret_token вебхука
+const webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// WebhookConfig описывает режим получения обновлений через вебхук
+type WebhookConfig struct {
+	URL        string `yaml:"url" json:"url"`                 // Публичный адрес, который регистрируется в Telegram
+	Listen     string `yaml:"listen" json:"listen"`           // Адрес локального HTTP(S)-сервера
+	Path       string `yaml:"path" json:"path"`               // Путь обработчика; по умолчанию — путь из URL
+	CertFile   string `yaml:"cert_file" json:"cert_file"`     // Сертификат для HTTPS; без него сервер слушает HTTP (за обратным прокси)
+	KeyFile    string `yaml:"key_file" json:"key_file"`       // Закрытый ключ сертификата
+	SelfSigned bool   `yaml:"self_signed" json:"self_signed"` // Передать сертификат в Telegram при регистрации
+}
+
+// handlerPath возвращает путь, на котором принимаются обновления
+func (c WebhookConfig) handlerPath() string {
+	if c.Path != "" {
+		return c.Path
+	}
+	if u, err := url.Parse(c.URL); err == nil && u.Path != "" {
+		return u.Path
+	}
+	return "/"
+}
+
+func (c WebhookConfig) validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("webhook.url must be set in webhook mode")
+	}
+	if c.Listen == "" {
+		return fmt.Errorf("webhook.listen must be set in webhook mode")
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("webhook.cert_file and webhook.key_file must be set together")
+	}
+	if c.SelfSigned && c.CertFile == "" {
+		return fmt.Errorf("webhook.self_signed requires webhook.cert_file")
+	}
+	return nil
+}
+
+// startPolling удаляет ранее зарегистрированный вебхук (иначе getUpdates
+// возвращает ошибку) и запускает long polling.
+func startPolling() tgbotapi.UpdatesChannel {
+	if _, err := bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+		slog.Error("Error deleting webhook", "error", err)
+	}
+
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 60
+	return bot.GetUpdatesChan(u)
+}
+
+// startWebhook регистрирует вебхук в Telegram и запускает HTTP(S)-сервер,
+// который передаёт полученные обновления в возвращаемый канал. При каждом
+// запуске вебхук регистрируется с новым случайным secret_token, и запросы без
+// него отклоняются: иначе любой, кто достучится до сервера, мог бы подделать
+// обновление от имени администратора.
+func startWebhook(config WebhookConfig) (tgbotapi.UpdatesChannel, *http.Server, error) {
+	secret, err := webhookSecret()
+	if err != nil {
+		return nil, nil, fmt.Errorf("generate webhook secret: %w", err)
+	}
+	if err := setWebhook(config, secret); err != nil {
+		return nil, nil, fmt.Errorf("set webhook: %w", err)
+	}
+
+	info, err := bot.GetWebhookInfo()
+	if err != nil {
+		return nil, nil, fmt.Errorf("get webhook info: %w", err)
+	}
+	if info.LastErrorDate != 0 {
+		slog.Warn("Telegram reports webhook error", "error", info.LastErrorMessage)
+	}
+
+	updates := make(chan tgbotapi.Update, updatesBuffer)
+	mux := http.NewServeMux()
+	mux.HandleFunc(config.handlerPath(), func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(webhookSecretHeader)), []byte(secret)) != 1 {
+			slog.Warn("Rejected webhook request without a valid secret token", "remote", r.RemoteAddr)
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		update, err := bot.HandleUpdate(r)
+		if err != nil {
+			slog.Error("Error handling webhook update", "error", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		select {
+		case updates <- *update:
+		case <-r.Context().Done():
+		}
+	})
+
+	srv := &http.Server{Addr: config.Listen, Handler: mux}
+	go func() {
+		var err error
+		if config.CertFile != "" {
+			err = srv.ListenAndServeTLS(config.CertFile, config.KeyFile)
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != http.ErrServerClosed {
+			fatal("Webhook server stopped", "error", err)
+		}
+	}()
+
+	slog.Info("Listening for webhook updates", "addr", config.Listen, "path", config.handlerPath())
+	return updates, srv, nil
+}
+
+// webhookSecret создаёт secret_token: 32 случайных байта в hex (Telegram
+// допускает в нём только A-Z, a-z, 0-9, _ и -)
+func webhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// setWebhook вызывает setWebhook напрямую: в tgbotapi нет secret_token.
+// Самоподписанный сертификат загружается вместе с параметрами.
+func setWebhook(config WebhookConfig, secret string) error {
+	if _, err := url.Parse(config.URL); err != nil {
+		return err
+	}
+	params := tgbotapi.Params{}
+	params.AddNonEmpty("url", config.URL)
+	params.AddNonEmpty("secret_token", secret)
+
+	var err error
+	if config.SelfSigned {
+		files := []tgbotapi.RequestFile{{Name: "certificate", Data: tgbotapi.FilePath(config.CertFile)}}
+		_, err = bot.UploadFiles("setWebhook", params, files)
+	} else {
+		_, err = bot.MakeRequest("setWebhook", params)
+	}
+	return err
+}